@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/github/github-mcp-server/internal/ghmcp"
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the server configuration",
+	Long:  `Check that the GitHub API can be reached with the configured host and token, and report the token's scopes, rate limits, and the toolsets and tools the server would enable.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		token := viper.GetString("personal_access_token")
+		if token == "" {
+			return errors.New("GITHUB_PERSONAL_ACCESS_TOKEN not set")
+		}
+
+		enabledToolsets, err := enabledToolsetsFromConfig()
+		if err != nil {
+			return err
+		}
+
+		var customTools []github.CustomToolDefinition
+		if err := viper.UnmarshalKey("custom_tools", &customTools); err != nil {
+			return fmt.Errorf("failed to unmarshal custom tools: %w", err)
+		}
+
+		var profiles map[string]github.ToolsetProfile
+		if err := viper.UnmarshalKey("profiles", &profiles); err != nil {
+			return fmt.Errorf("failed to unmarshal profiles: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		report, err := ghmcp.RunDoctor(ctx, ghmcp.DoctorConfig{
+			Version:         version,
+			Host:            viper.GetString("host"),
+			Token:           token,
+			EnabledToolsets: enabledToolsets,
+			DynamicToolsets: viper.GetBool("dynamic_toolsets"),
+			ReadOnly:        viper.GetBool("read-only"),
+			Profile:         viper.GetString("profile"),
+			Profiles:        profiles,
+			HasCustomTools:  len(customTools) > 0,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to run doctor: %w", err)
+		}
+
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if jsonOutput {
+			err = report.WriteJSON(os.Stdout)
+		} else {
+			err = report.WriteText(os.Stdout)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+
+		if len(report.Problems) > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("found %d problem(s)", len(report.Problems))
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().Bool("json", false, "Print the report as JSON")
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -7,8 +7,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/github/github-mcp-server/internal/ghmcp"
 	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/github/github-mcp-server/pkg/sanitize"
+	"github.com/github/github-mcp-server/pkg/secrets"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -37,18 +41,54 @@ var (
 				return errors.New("GITHUB_PERSONAL_ACCESS_TOKEN not set")
 			}
 
-			// If you're wondering why we're not using viper.GetStringSlice("toolsets"),
-			// it's because viper doesn't handle comma-separated values correctly for env
-			// vars when using GetStringSlice.
-			// https://github.com/spf13/viper/issues/380
-			var enabledToolsets []string
-			if err := viper.UnmarshalKey("toolsets", &enabledToolsets); err != nil {
-				return fmt.Errorf("failed to unmarshal toolsets: %w", err)
+			enabledToolsets, err := enabledToolsetsFromConfig()
+			if err != nil {
+				return err
 			}
+			profile := viper.GetString("profile")
 
-			// No passed toolsets configuration means we enable the default toolset
-			if len(enabledToolsets) == 0 {
-				enabledToolsets = []string{github.ToolsetMetadataDefault.ID}
+			var customTools []github.CustomToolDefinition
+			if err := viper.UnmarshalKey("custom_tools", &customTools); err != nil {
+				return fmt.Errorf("failed to unmarshal custom tools: %w", err)
+			}
+
+			var profiles map[string]github.ToolsetProfile
+			if err := viper.UnmarshalKey("profiles", &profiles); err != nil {
+				return fmt.Errorf("failed to unmarshal profiles: %w", err)
+			}
+
+			trustPolicy, err := trustPolicyFromConfig()
+			if err != nil {
+				return err
+			}
+
+			var promptInjection *sanitize.ScannerConfig
+			if viper.IsSet("prompt_injection") {
+				promptInjection = &sanitize.ScannerConfig{}
+				if err := viper.UnmarshalKey("prompt_injection", promptInjection); err != nil {
+					return fmt.Errorf("failed to unmarshal prompt injection config: %w", err)
+				}
+			}
+
+			var urlPolicy *sanitize.URLPolicy
+			if viper.IsSet("url_policy") {
+				urlPolicy = &sanitize.URLPolicy{}
+				if err := viper.UnmarshalKey("url_policy", urlPolicy); err != nil {
+					return fmt.Errorf("failed to unmarshal URL policy: %w", err)
+				}
+			}
+
+			var secretScanning *secrets.Config
+			if viper.IsSet("secret_scanning") {
+				secretScanning = &secrets.Config{}
+				if err := viper.UnmarshalKey("secret_scanning", secretScanning); err != nil {
+					return fmt.Errorf("failed to unmarshal secret scanning config: %w", err)
+				}
+			}
+
+			var confirmationPolicy github.ConfirmationPolicy
+			if err := viper.UnmarshalKey("confirmation_policy", &confirmationPolicy); err != nil {
+				return fmt.Errorf("failed to unmarshal confirmation policy: %w", err)
 			}
 
 			ttl := viper.GetDuration("repo-access-cache-ttl")
@@ -64,7 +104,22 @@ var (
 				LogFilePath:          viper.GetString("log-file"),
 				ContentWindowSize:    viper.GetInt("content-window-size"),
 				LockdownMode:         viper.GetBool("lockdown-mode"),
+				LockdownRedact:       viper.GetBool("lockdown-redact"),
 				RepoAccessCacheTTL:   &ttl,
+				TrustPolicy:          trustPolicy,
+				TrustPolicyUpdates:   watchTrustPolicy(),
+				SanitizeContent:      viper.GetBool("sanitize-content"),
+				PromptInjection:      promptInjection,
+				URLPolicy:            urlPolicy,
+				ScanSecrets:          viper.GetBool("scan-secrets"),
+				SecretScanning:       secretScanning,
+				CustomTools:          customTools,
+				Profile:              profile,
+				Profiles:             profiles,
+				ScopeCheck:           viper.GetString("scope-check"),
+				Confirmation:         viper.GetString("confirmation"),
+				ConfirmationPolicy:   confirmationPolicy,
+				DryRun:               viper.GetBool("dry-run"),
 			}
 			return ghmcp.RunStdioServer(stdioServerConfig)
 		},
@@ -78,7 +133,9 @@ func init() {
 	rootCmd.SetVersionTemplate("{{.Short}}\n{{.Version}}\n")
 
 	// Add global flags that will be shared by all commands
+	rootCmd.PersistentFlags().String("config", "", "Path to a configuration file (JSON, YAML or TOML), e.g. for declaring custom tools")
 	rootCmd.PersistentFlags().StringSlice("toolsets", nil, github.GenerateToolsetsHelp())
+	rootCmd.PersistentFlags().String("profile", "", "Toolset profile for a common agent role (reviewer, triager, ci-doctor, security, or one defined in --config)")
 	rootCmd.PersistentFlags().Bool("dynamic-toolsets", false, "Enable dynamic toolsets")
 	rootCmd.PersistentFlags().Bool("read-only", false, "Restrict the server to read-only operations")
 	rootCmd.PersistentFlags().String("log-file", "", "Path to log file")
@@ -87,10 +144,18 @@ func init() {
 	rootCmd.PersistentFlags().String("gh-host", "", "Specify the GitHub hostname (for GitHub Enterprise etc.)")
 	rootCmd.PersistentFlags().Int("content-window-size", 5000, "Specify the content window size")
 	rootCmd.PersistentFlags().Bool("lockdown-mode", false, "Enable lockdown mode")
+	rootCmd.PersistentFlags().Bool("lockdown-redact", false, "In lockdown mode, replace content from untrusted authors with a placeholder instead of withholding it")
+	rootCmd.PersistentFlags().Bool("sanitize-content", false, "Remove hidden content, such as invisible characters and HTML comments, from user-authored text in tool results")
+	rootCmd.PersistentFlags().Bool("scan-secrets", false, "Scan the arguments of write tools, such as file contents and comment bodies, for secrets before publishing them")
+	rootCmd.PersistentFlags().String("scope-check", github.ScopeCheckOff, "How to treat tools the token lacks the scopes for at startup: off, warn, hide or annotate")
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Make write tools return the API requests they would send instead of sending them")
+	rootCmd.PersistentFlags().String("confirmation", github.ConfirmationOff, "Which tools need confirmation before they run: off, destructive or writes")
 	rootCmd.PersistentFlags().Duration("repo-access-cache-ttl", 5*time.Minute, "Override the repo access cache TTL (e.g. 1m, 0s to disable)")
 
 	// Bind flag to viper
+	_ = viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
 	_ = viper.BindPFlag("toolsets", rootCmd.PersistentFlags().Lookup("toolsets"))
+	_ = viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
 	_ = viper.BindPFlag("dynamic_toolsets", rootCmd.PersistentFlags().Lookup("dynamic-toolsets"))
 	_ = viper.BindPFlag("read-only", rootCmd.PersistentFlags().Lookup("read-only"))
 	_ = viper.BindPFlag("log-file", rootCmd.PersistentFlags().Lookup("log-file"))
@@ -99,18 +164,83 @@ func init() {
 	_ = viper.BindPFlag("host", rootCmd.PersistentFlags().Lookup("gh-host"))
 	_ = viper.BindPFlag("content-window-size", rootCmd.PersistentFlags().Lookup("content-window-size"))
 	_ = viper.BindPFlag("lockdown-mode", rootCmd.PersistentFlags().Lookup("lockdown-mode"))
+	_ = viper.BindPFlag("lockdown-redact", rootCmd.PersistentFlags().Lookup("lockdown-redact"))
 	_ = viper.BindPFlag("repo-access-cache-ttl", rootCmd.PersistentFlags().Lookup("repo-access-cache-ttl"))
+	_ = viper.BindPFlag("sanitize-content", rootCmd.PersistentFlags().Lookup("sanitize-content"))
+	_ = viper.BindPFlag("scan-secrets", rootCmd.PersistentFlags().Lookup("scan-secrets"))
+	_ = viper.BindPFlag("dry-run", rootCmd.PersistentFlags().Lookup("dry-run"))
+	_ = viper.BindPFlag("confirmation", rootCmd.PersistentFlags().Lookup("confirmation"))
+	_ = viper.BindPFlag("scope-check", rootCmd.PersistentFlags().Lookup("scope-check"))
 
 	// Add subcommands
 	rootCmd.AddCommand(stdioCmd)
 }
 
+// trustPolicyFromConfig reads the lockdown trust policy from the config file.
+func trustPolicyFromConfig() (lockdown.TrustPolicy, error) {
+	var policy lockdown.TrustPolicy
+	if err := viper.UnmarshalKey("lockdown_policy", &policy); err != nil {
+		return policy, fmt.Errorf("failed to unmarshal lockdown policy: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return policy, fmt.Errorf("invalid lockdown policy: %w", err)
+	}
+	return policy, nil
+}
+
+// watchTrustPolicy reloads the lockdown trust policy whenever the config file changes, so that policy
+// changes take effect without a restart. It returns nil when lockdown mode is off or no config file is used.
+func watchTrustPolicy() <-chan lockdown.TrustPolicy {
+	if !viper.GetBool("lockdown-mode") || viper.ConfigFileUsed() == "" {
+		return nil
+	}
+	updates := make(chan lockdown.TrustPolicy, 1)
+	viper.OnConfigChange(func(_ fsnotify.Event) {
+		policy, err := trustPolicyFromConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ignoring config change: %v\n", err)
+			return
+		}
+		// Only the latest policy matters, so replace one that has not been applied yet
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- policy
+	})
+	viper.WatchConfig()
+	return updates
+}
+
+// enabledToolsetsFromConfig reads the toolsets to enable, falling back to the default toolset
+// when neither toolsets nor a profile are configured.
+func enabledToolsetsFromConfig() ([]string, error) {
+	// If you're wondering why we're not using viper.GetStringSlice("toolsets"),
+	// it's because viper doesn't handle comma-separated values correctly for env
+	// vars when using GetStringSlice.
+	// https://github.com/spf13/viper/issues/380
+	var enabledToolsets []string
+	if err := viper.UnmarshalKey("toolsets", &enabledToolsets); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal toolsets: %w", err)
+	}
+
+	if len(enabledToolsets) == 0 && viper.GetString("profile") == "" {
+		enabledToolsets = []string{github.ToolsetMetadataDefault.ID}
+	}
+	return enabledToolsets, nil
+}
+
 func initConfig() {
 	// Initialize Viper configuration
 	viper.SetEnvPrefix("github")
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
 
+	// Settings that don't fit in flags or env vars, such as custom tools, come from an optional config file
+	if configFile := viper.GetString("config"); configFile != "" {
+		viper.SetConfigFile(configFile)
+		cobra.CheckErr(viper.ReadInConfig())
+	}
 }
 
 func main() {
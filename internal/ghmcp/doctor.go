@@ -0,0 +1,387 @@
+package ghmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/scopes"
+	"github.com/github/github-mcp-server/pkg/translations"
+	gogithub "github.com/google/go-github/v79/github"
+	"github.com/shurcooL/githubv4"
+)
+
+type DoctorConfig struct {
+	// Version of the server
+	Version string
+
+	// GitHub Host to target for API requests (e.g. github.com or github.enterprise.com)
+	Host string
+
+	// GitHub Token to authenticate with the GitHub API
+	Token string
+
+	// EnabledToolsets is a list of toolsets to enable
+	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#tool-configuration
+	EnabledToolsets []string
+
+	// Whether to enable dynamic toolsets
+	DynamicToolsets bool
+
+	// ReadOnly indicates if only read-only tools would be offered
+	ReadOnly bool
+
+	// Profile is the ID of a toolset profile to apply on top of EnabledToolsets
+	Profile string
+
+	// Profiles are profiles declared in configuration, overriding built-in profiles with the same ID
+	Profiles map[string]github.ToolsetProfile
+
+	// HasCustomTools is true when custom tools are declared in configuration
+	HasCustomTools bool
+}
+
+// DoctorReport is the result of the diagnostic checks run by the doctor command.
+type DoctorReport struct {
+	Host               string                   `json:"host"`
+	SubdomainIsolation *bool                    `json:"subdomain_isolation,omitempty"`
+	Endpoints          []DoctorEndpoint         `json:"endpoints"`
+	Token              DoctorToken              `json:"token"`
+	Scopes             DoctorScopes             `json:"scopes"`
+	GraphQL            DoctorCheck              `json:"graphql"`
+	RateLimits         []DoctorRateLimit        `json:"rate_limits,omitempty"`
+	Toolsets           DoctorToolsets           `json:"toolsets"`
+	Tools              []github.ToolScopeStatus `json:"tools"`
+	Problems           []string                 `json:"problems,omitempty"`
+}
+
+// DoctorCheck is the outcome of a single check.
+type DoctorCheck struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// DoctorEndpoint describes whether one of the API URLs could be reached.
+type DoctorEndpoint struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code,omitempty"`
+	DoctorCheck
+}
+
+// DoctorToken describes the token and the user it authenticates as.
+type DoctorToken struct {
+	Kind  string `json:"kind"`
+	Login string `json:"login,omitempty"`
+	DoctorCheck
+}
+
+// DoctorScopes describes the OAuth scopes granted to the token.
+type DoctorScopes struct {
+	Known   bool     `json:"known"`
+	Granted []string `json:"granted"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// DoctorRateLimit describes the remaining requests of one rate limit resource.
+type DoctorRateLimit struct {
+	Resource  string    `json:"resource"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// DoctorToolsets describes the toolsets the server would enable with the given configuration.
+type DoctorToolsets struct {
+	Profile  string   `json:"profile,omitempty"`
+	Enabled  []string `json:"enabled"`
+	Invalid  []string `json:"invalid,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Dynamic  bool     `json:"dynamic"`
+	ReadOnly bool     `json:"read_only"`
+	// ReadOnlyToolsets are the toolsets restricted to read-only tools by a read-only profile
+	ReadOnlyToolsets []string `json:"read_only_toolsets,omitempty"`
+}
+
+// RunDoctor runs the diagnostic checks against the configured host and token.
+// Failed checks are recorded in the report rather than returned as errors.
+func RunDoctor(ctx context.Context, cfg DoctorConfig) (*DoctorReport, error) {
+	apiHost, err := parseAPIHost(cfg.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse API host: %w", err)
+	}
+	restClient := newRESTClient(cfg.Version, cfg.Token, apiHost)
+	gqlClient := githubv4.NewEnterpriseClient(apiHost.graphqlURL.String(), &http.Client{
+		Transport: &bearerAuthTransport{
+			transport: http.DefaultTransport,
+			token:     cfg.Token,
+		},
+	})
+
+	report := &DoctorReport{
+		Host:  apiHost.baseRESTURL.Hostname(),
+		Token: DoctorToken{Kind: scopes.TokenKind(cfg.Token)},
+	}
+
+	// GHES is served under /api/v3/, and the raw URL only moves to its own subdomain with isolation enabled
+	if strings.HasSuffix(apiHost.baseRESTURL.Path, "/api/v3/") {
+		isolated := apiHost.rawURL.Hostname() != apiHost.baseRESTURL.Hostname()
+		report.SubdomainIsolation = &isolated
+	}
+
+	report.Endpoints = checkEndpoints(ctx, apiHost)
+	for _, endpoint := range report.Endpoints {
+		if !endpoint.OK {
+			report.problem("%s endpoint %s is unreachable: %s", endpoint.Name, endpoint.URL, endpoint.Error)
+		}
+	}
+
+	user, _, err := restClient.Users.Get(ctx, "")
+	if err != nil {
+		report.Token.Error = err.Error()
+		report.problem("the token could not be used to get the authenticated user: %v", err)
+	} else {
+		report.Token.OK = true
+		report.Token.Login = user.GetLogin()
+	}
+
+	var tokenScopes scopes.TokenScopes
+	rateLimits, resp, err := restClient.RateLimit.Get(ctx)
+	if err != nil {
+		report.Scopes.Error = err.Error()
+	} else {
+		tokenScopes = scopes.FromHeader(resp.Header)
+		report.Scopes.Known = tokenScopes.Known
+		report.Scopes.Granted = tokenScopes.Granted
+		report.RateLimits = doctorRateLimits(rateLimits)
+		for _, limit := range report.RateLimits {
+			if limit.Remaining == 0 {
+				report.problem("the %s rate limit is exhausted until %s", limit.Resource, limit.Reset.Format(time.RFC3339))
+			}
+		}
+	}
+
+	var query struct {
+		Viewer struct {
+			Login githubv4.String
+		}
+	}
+	if err := gqlClient.Query(ctx, &query, nil); err != nil {
+		report.GraphQL.Error = err.Error()
+		report.problem("the GraphQL API is unavailable: %v", err)
+	} else {
+		report.GraphQL.OK = true
+	}
+
+	var profile *github.ToolsetProfile
+	report.Toolsets, profile = resolveDoctorToolsets(cfg)
+	if report.Toolsets.Error != "" {
+		report.problem("%s", report.Toolsets.Error)
+	}
+	if len(report.Toolsets.Invalid) > 0 {
+		report.problem("invalid toolsets would be ignored: %s", strings.Join(report.Toolsets.Invalid, ", "))
+	}
+
+	// The tools are only inspected, never called, so the clients don't need to be functional
+	tsg := github.DefaultToolsetGroup(report.Toolsets.ReadOnly,
+		func(_ context.Context) (*gogithub.Client, error) { return restClient, nil },
+		func(_ context.Context) (*githubv4.Client, error) { return gqlClient, nil },
+		nil,
+		translations.NullTranslationHelper,
+		0,
+		github.FeatureFlags{},
+		nil,
+	)
+	if profile != nil {
+		report.Toolsets.ReadOnlyToolsets = github.ApplyProfileReadOnly(tsg, *profile)
+	}
+	report.Tools = github.CheckToolScopes(tsg, tokenScopes)
+
+	return report, nil
+}
+
+func (r *DoctorReport) problem(format string, args ...any) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// checkEndpoints requests each of the API URLs. Any HTTP response counts as reachable,
+// since most of them answer an unauthenticated request to their root with an error status.
+func checkEndpoints(ctx context.Context, apiHost apiHost) []DoctorEndpoint {
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		//nolint:revive // parameters are required by http.Client.CheckRedirect signature
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	endpoints := []DoctorEndpoint{
+		{Name: "REST", URL: apiHost.baseRESTURL.String()},
+		{Name: "GraphQL", URL: apiHost.graphqlURL.String()},
+		{Name: "Upload", URL: apiHost.uploadURL.String()},
+		{Name: "Raw", URL: apiHost.rawURL.String()},
+	}
+	for i := range endpoints {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoints[i].URL, nil)
+		if err != nil {
+			endpoints[i].Error = err.Error()
+			continue
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			endpoints[i].Error = err.Error()
+			continue
+		}
+		_ = resp.Body.Close()
+		endpoints[i].StatusCode = resp.StatusCode
+		endpoints[i].OK = true
+	}
+	return endpoints
+}
+
+func doctorRateLimits(limits *gogithub.RateLimits) []DoctorRateLimit {
+	var result []DoctorRateLimit
+	for _, resource := range []struct {
+		name string
+		rate *gogithub.Rate
+	}{
+		{"core", limits.GetCore()},
+		{"graphql", limits.GetGraphQL()},
+		{"search", limits.GetSearch()},
+		{"code_search", limits.GetCodeSearch()},
+	} {
+		if resource.rate == nil {
+			continue
+		}
+		result = append(result, DoctorRateLimit{
+			Resource:  resource.name,
+			Limit:     resource.rate.Limit,
+			Remaining: resource.rate.Remaining,
+			Reset:     resource.rate.Reset.Time,
+		})
+	}
+	return result
+}
+
+func resolveDoctorToolsets(cfg DoctorConfig) (DoctorToolsets, *github.ToolsetProfile) {
+	result := DoctorToolsets{
+		Profile:  cfg.Profile,
+		Dynamic:  cfg.DynamicToolsets,
+		ReadOnly: cfg.ReadOnly,
+	}
+
+	var profile *github.ToolsetProfile
+	if cfg.Profile != "" {
+		p, err := github.ResolveProfile(cfg.Profile, cfg.Profiles)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			profile = &p
+		}
+	}
+
+	enabled, invalid := resolveEnabledToolsets(cfg.EnabledToolsets, profile, cfg.HasCustomTools, cfg.DynamicToolsets)
+	for _, invalidToolset := range invalid {
+		enabled = github.RemoveToolset(enabled, invalidToolset)
+	}
+	result.Enabled, result.Invalid = enabled, invalid
+	return result, profile
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *DoctorReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report in a human readable format.
+func (r *DoctorReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Host:\t%s\n", r.Host)
+	if r.SubdomainIsolation != nil {
+		fmt.Fprintf(tw, "Subdomain isolation:\t%s\n", enabledText(*r.SubdomainIsolation))
+	}
+	for _, endpoint := range r.Endpoints {
+		status := fmt.Sprintf("reachable (HTTP %d)", endpoint.StatusCode)
+		if !endpoint.OK {
+			status = "unreachable: " + endpoint.Error
+		}
+		fmt.Fprintf(tw, "%s endpoint:\t%s %s\n", endpoint.Name, endpoint.URL, status)
+	}
+
+	fmt.Fprintf(tw, "\nToken kind:\t%s\n", r.Token.Kind)
+	if r.Token.OK {
+		fmt.Fprintf(tw, "Authenticated as:\t%s\n", r.Token.Login)
+	} else {
+		fmt.Fprintf(tw, "Authenticated as:\tfailed (%s)\n", r.Token.Error)
+	}
+	switch {
+	case r.Scopes.Error != "":
+		fmt.Fprintf(tw, "Token scopes:\tunavailable (%s)\n", r.Scopes.Error)
+	case !r.Scopes.Known:
+		fmt.Fprintf(tw, "Token scopes:\tnot reported, fine-grained and GitHub App tokens are not checked\n")
+	default:
+		fmt.Fprintf(tw, "Token scopes:\t%s\n", strings.Join(r.Scopes.Granted, ", "))
+	}
+	if r.GraphQL.OK {
+		fmt.Fprintf(tw, "GraphQL API:\tavailable\n")
+	} else {
+		fmt.Fprintf(tw, "GraphQL API:\tunavailable (%s)\n", r.GraphQL.Error)
+	}
+	for _, limit := range r.RateLimits {
+		fmt.Fprintf(tw, "Rate limit (%s):\t%d/%d remaining, resets %s\n", limit.Resource, limit.Remaining, limit.Limit, limit.Reset.Format(time.RFC3339))
+	}
+
+	fmt.Fprintln(tw)
+	if r.Toolsets.Profile != "" {
+		fmt.Fprintf(tw, "Profile:\t%s\n", r.Toolsets.Profile)
+	}
+	fmt.Fprintf(tw, "Enabled toolsets:\t%s\n", strings.Join(r.Toolsets.Enabled, ", "))
+	if len(r.Toolsets.Invalid) > 0 {
+		fmt.Fprintf(tw, "Invalid toolsets:\t%s\n", strings.Join(r.Toolsets.Invalid, ", "))
+	}
+	fmt.Fprintf(tw, "Dynamic toolsets:\t%s\n", enabledText(r.Toolsets.Dynamic))
+	fmt.Fprintf(tw, "Read-only:\t%s\n", enabledText(r.Toolsets.ReadOnly))
+	if len(r.Toolsets.ReadOnlyToolsets) > 0 {
+		fmt.Fprintf(tw, "Read-only toolsets:\t%s\n", strings.Join(r.Toolsets.ReadOnlyToolsets, ", "))
+	}
+
+	fmt.Fprintf(tw, "\nTOOLSET\tTOOL\tREQUIRED SCOPES\tSTATUS\n")
+	for _, status := range r.Tools {
+		required := "-"
+		if len(status.Required) > 0 {
+			required = strings.Join(status.Required, " or ")
+		}
+		result := "ok"
+		if !status.Satisfied {
+			result = "missing scope"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", status.Toolset, status.Tool, required, result)
+	}
+
+	if len(r.Problems) > 0 {
+		fmt.Fprintf(tw, "\nProblems found:\n")
+		for _, problem := range r.Problems {
+			fmt.Fprintf(tw, "- %s\n", problem)
+		}
+	} else {
+		fmt.Fprintf(tw, "\nNo problems found.\n")
+	}
+
+	return tw.Flush()
+}
+
+func enabledText(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -19,6 +20,10 @@ import (
 	"github.com/github/github-mcp-server/pkg/lockdown"
 	mcplog "github.com/github/github-mcp-server/pkg/log"
 	"github.com/github/github-mcp-server/pkg/raw"
+	"github.com/github/github-mcp-server/pkg/sanitize"
+	"github.com/github/github-mcp-server/pkg/scopes"
+	"github.com/github/github-mcp-server/pkg/secrets"
+	"github.com/github/github-mcp-server/pkg/toolsets"
 	"github.com/github/github-mcp-server/pkg/translations"
 	gogithub "github.com/google/go-github/v79/github"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -56,8 +61,57 @@ type MCPServerConfig struct {
 	// LockdownMode indicates if we should enable lockdown mode
 	LockdownMode bool
 
+	// LockdownRedact replaces content from untrusted authors with a placeholder in lockdown mode instead of withholding it
+	LockdownRedact bool
+
 	// RepoAccessTTL overrides the default TTL for repository access cache entries.
 	RepoAccessTTL *time.Duration
+
+	// TrustPolicy decides which authors are trusted in lockdown mode
+	TrustPolicy lockdown.TrustPolicy
+
+	// TrustPolicyUpdates receives replacements for the trust policy, e.g. when the config file changes
+	TrustPolicyUpdates <-chan lockdown.TrustPolicy
+
+	// SanitizeContent removes hidden content from the user-authored fields of tool results
+	SanitizeContent bool
+
+	// PromptInjection configures scanning user-authored fields for prompt injection, when SanitizeContent is set
+	PromptInjection *sanitize.ScannerConfig
+
+	// URLPolicy restricts the hosts that links and images in user-authored content may point to, whether or not
+	// SanitizeContent is set
+	URLPolicy *sanitize.URLPolicy
+
+	// ScanSecrets scans the arguments of write tools for secrets before they are published
+	ScanSecrets bool
+
+	// SecretScanning configures the rules and action of secret scanning, when ScanSecrets is set
+	SecretScanning *secrets.Config
+
+	// CustomTools are tools declared in configuration and registered into the custom toolset
+	CustomTools []github.CustomToolDefinition
+
+	// Profile is the ID of a toolset profile to apply on top of EnabledToolsets
+	Profile string
+
+	// Profiles are profiles declared in configuration, overriding built-in profiles with the same ID
+	Profiles map[string]github.ToolsetProfile
+
+	// ScopeCheck determines how tools are treated when the token lacks the scopes they need (off, warn, hide or annotate)
+	ScopeCheck string
+
+	// Confirmation determines which tools need confirmation before they run (off, destructive or writes)
+	Confirmation string
+
+	// ConfirmationPolicy adds tools to, or exempts tools from, confirmation
+	ConfirmationPolicy github.ConfirmationPolicy
+
+	// DryRun makes every write tool return the requests it would send instead of sending them
+	DryRun bool
+
+	// Logger receives the messages of the server, such as scope check warnings. Defaults to slog.Default()
+	Logger *slog.Logger
 }
 
 const stdioServerLogPrefix = "stdioserver"
@@ -69,28 +123,32 @@ func NewMCPServer(cfg MCPServerConfig) (*server.MCPServer, error) {
 	}
 
 	// Construct our REST client
-	restClient := gogithub.NewClient(nil).WithAuthToken(cfg.Token)
-	restClient.UserAgent = fmt.Sprintf("github-mcp-server/%s", cfg.Version)
-	restClient.BaseURL = apiHost.baseRESTURL
-	restClient.UploadURL = apiHost.uploadURL
+	restClient := newRESTClient(cfg.Version, cfg.Token, apiHost)
 
 	// Construct our GraphQL client
 	// We're using NewEnterpriseClient here unconditionally as opposed to NewClient because we already
 	// did the necessary API host parsing so that github.com will return the correct URL anyway.
 	gqlHTTPClient := &http.Client{
 		Transport: &bearerAuthTransport{
-			transport: http.DefaultTransport,
+			transport: github.NewDryRunTransport(http.DefaultTransport),
 			token:     cfg.Token,
 		},
 	} // We're going to wrap the Transport later in beforeInit
 	gqlClient := githubv4.NewEnterpriseClient(apiHost.graphqlURL.String(), gqlHTTPClient)
-	repoAccessOpts := []lockdown.RepoAccessOption{}
+	repoAccessOpts := []lockdown.RepoAccessOption{lockdown.WithTrustPolicy(cfg.TrustPolicy)}
 	if cfg.RepoAccessTTL != nil {
 		repoAccessOpts = append(repoAccessOpts, lockdown.WithTTL(*cfg.RepoAccessTTL))
 	}
 	var repoAccessCache *lockdown.RepoAccessCache
 	if cfg.LockdownMode {
 		repoAccessCache = lockdown.GetInstance(gqlClient, repoAccessOpts...)
+		if cfg.TrustPolicyUpdates != nil {
+			go func() {
+				for policy := range cfg.TrustPolicyUpdates {
+					repoAccessCache.SetPolicy(policy)
+				}
+			}()
+		}
 	}
 
 	// When a client send an initialize request, update the user agent to include the client info.
@@ -121,25 +179,25 @@ func NewMCPServer(cfg MCPServerConfig) (*server.MCPServer, error) {
 		},
 	}
 
-	enabledToolsets := cfg.EnabledToolsets
-
-	// If dynamic toolsets are enabled, remove "all" from the enabled toolsets
-	if cfg.DynamicToolsets {
-		enabledToolsets = github.RemoveToolset(enabledToolsets, github.ToolsetMetadataAll.ID)
+	if cfg.ScopeCheck != "" && !github.IsValidScopeCheckMode(cfg.ScopeCheck) {
+		return nil, fmt.Errorf("invalid scope check mode: %s", cfg.ScopeCheck)
 	}
-
-	// Clean up the passed toolsets
-	enabledToolsets, invalidToolsets := github.CleanToolsets(enabledToolsets)
-
-	// If "all" is present, override all other toolsets
-	if github.ContainsToolset(enabledToolsets, github.ToolsetMetadataAll.ID) {
-		enabledToolsets = []string{github.ToolsetMetadataAll.ID}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
 	}
-	// If "default" is present, expand to real toolset IDs
-	if github.ContainsToolset(enabledToolsets, github.ToolsetMetadataDefault.ID) {
-		enabledToolsets = github.AddDefaultToolset(enabledToolsets)
+
+	var profile *github.ToolsetProfile
+	if cfg.Profile != "" {
+		p, err := github.ResolveProfile(cfg.Profile, cfg.Profiles)
+		if err != nil {
+			return nil, err
+		}
+		// A read-only profile only restricts its own toolsets, see ApplyProfileTools
+		profile = &p
 	}
 
+	enabledToolsets, invalidToolsets := resolveEnabledToolsets(cfg.EnabledToolsets, profile, len(cfg.CustomTools) > 0, cfg.DynamicToolsets)
 	if len(invalidToolsets) > 0 {
 		fmt.Fprintf(os.Stderr, "Invalid toolsets ignored: %s\n", strings.Join(invalidToolsets, ", "))
 	}
@@ -147,15 +205,53 @@ func NewMCPServer(cfg MCPServerConfig) (*server.MCPServer, error) {
 	// Generate instructions based on enabled toolsets
 	instructions := github.GenerateInstructions(enabledToolsets)
 
-	ghServer := github.NewServer(cfg.Version,
-		server.WithInstructions(instructions),
-		server.WithHooks(hooks),
-	)
-
 	getClient := func(_ context.Context) (*gogithub.Client, error) {
 		return restClient, nil // closing over client
 	}
 
+	serverOpts := []server.ServerOption{
+		server.WithInstructions(instructions),
+		server.WithHooks(hooks),
+	}
+	var urlFilter *sanitize.URLFilter
+	if cfg.URLPolicy != nil {
+		urlFilter, err = sanitize.NewURLFilter(*cfg.URLPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL policy: %w", err)
+		}
+		// Tools that sanitize HTML themselves, such as issue_read, apply the policy to links and images too
+		if err := sanitize.RestrictURLs(*cfg.URLPolicy); err != nil {
+			return nil, fmt.Errorf("invalid URL policy: %w", err)
+		}
+	}
+	switch {
+	case cfg.SanitizeContent:
+		var scanner *sanitize.Scanner
+		if cfg.PromptInjection != nil {
+			scanner, err = sanitize.NewScanner(*cfg.PromptInjection)
+			if err != nil {
+				return nil, fmt.Errorf("invalid prompt injection config: %w", err)
+			}
+		}
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(github.NewContentSanitizer(urlFilter, scanner).ToolHandlerMiddleware))
+	case urlFilter != nil:
+		// The URL policy restricts links and images on its own, without the rest of content sanitization
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(github.NewURLSanitizer(urlFilter).ToolHandlerMiddleware))
+	}
+	if cfg.ScanSecrets {
+		var secretsCfg secrets.Config
+		if cfg.SecretScanning != nil {
+			secretsCfg = *cfg.SecretScanning
+		}
+		scanner, err := secrets.NewScanner(secretsCfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret scanning config: %w", err)
+		}
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(github.NewSecretGuard(scanner, getClient).ToolHandlerMiddleware))
+	}
+
+	ghServer := github.NewServer(cfg.Version, serverOpts...)
+
 	getGQLClient := func(_ context.Context) (*githubv4.Client, error) {
 		return gqlClient, nil // closing over client
 	}
@@ -176,15 +272,57 @@ func NewMCPServer(cfg MCPServerConfig) (*server.MCPServer, error) {
 		getRawClient,
 		cfg.Translator,
 		cfg.ContentWindowSize,
-		github.FeatureFlags{LockdownMode: cfg.LockdownMode},
+		github.FeatureFlags{LockdownMode: cfg.LockdownMode, LockdownRedact: cfg.LockdownRedact},
 		repoAccessCache,
 	)
+
+	if len(cfg.CustomTools) > 0 {
+		custom, err := github.CustomToolset(cfg.CustomTools, getClient, cfg.Translator)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load custom tools: %w", err)
+		}
+		if err := checkCustomToolNames(tsg, custom); err != nil {
+			return nil, err
+		}
+		tsg.AddToolset(custom)
+	}
+
 	err = tsg.EnableToolsets(enabledToolsets, nil)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to enable toolsets: %w", err)
 	}
 
+	if profile != nil {
+		if err := github.ApplyProfileTools(tsg, *profile); err != nil {
+			return nil, err
+		}
+	}
+
+	switch cfg.ScopeCheck {
+	case "", github.ScopeCheckOff:
+	case github.ScopeCheckWarn:
+		// Warnings leave the tools unchanged, so the scopes are fetched without delaying startup
+		go warnTokenScopes(logger, restClient, enabledToolScopes(tsg))
+	default:
+		if err := checkTokenScopes(logger, restClient, tsg, cfg.ScopeCheck); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Confirmation != "" || len(cfg.ConfirmationPolicy.Tools) > 0 {
+		mode := cfg.Confirmation
+		if mode == "" {
+			mode = github.ConfirmationOff
+		}
+		if _, err := github.ApplyConfirmation(tsg, mode, cfg.ConfirmationPolicy); err != nil {
+			return nil, err
+		}
+	}
+
+	// Applied last, so that dry runs skip confirmation
+	github.ApplyDryRun(tsg, cfg.DryRun, getClient)
+
 	// Register all mcp functionality with the server
 	tsg.RegisterAll(ghServer)
 
@@ -196,6 +334,138 @@ func NewMCPServer(cfg MCPServerConfig) (*server.MCPServer, error) {
 	return ghServer, nil
 }
 
+// resolveEnabledToolsets combines the configured toolsets with the profile's and the custom toolset,
+// and expands the special "all" and "default" toolsets.
+// Returns: (toolsets, invalidToolsets)
+func resolveEnabledToolsets(configured []string, profile *github.ToolsetProfile, hasCustomTools, dynamic bool) ([]string, []string) {
+	enabledToolsets := append([]string{}, configured...)
+	if profile != nil {
+		enabledToolsets = append(enabledToolsets, profile.Toolsets...)
+	}
+
+	// Declaring custom tools implies wanting them, unless the model is choosing toolsets itself
+	if hasCustomTools && !dynamic {
+		enabledToolsets = append(enabledToolsets, github.ToolsetMetadataCustom.ID)
+	}
+
+	// If dynamic toolsets are enabled, remove "all" from the enabled toolsets
+	if dynamic {
+		enabledToolsets = github.RemoveToolset(enabledToolsets, github.ToolsetMetadataAll.ID)
+	}
+
+	// Clean up the passed toolsets
+	enabledToolsets, invalidToolsets := github.CleanToolsets(enabledToolsets)
+
+	// If "all" is present, override all other toolsets
+	if github.ContainsToolset(enabledToolsets, github.ToolsetMetadataAll.ID) {
+		enabledToolsets = []string{github.ToolsetMetadataAll.ID}
+	}
+	// If "default" is present, expand to real toolset IDs
+	if github.ContainsToolset(enabledToolsets, github.ToolsetMetadataDefault.ID) {
+		enabledToolsets = github.AddDefaultToolset(enabledToolsets)
+	}
+
+	return enabledToolsets, invalidToolsets
+}
+
+func newRESTClient(version, token string, apiHost apiHost) *gogithub.Client {
+	// Mutating requests of tool calls in dry-run mode are planned instead of sent
+	restClient := gogithub.NewClient(&http.Client{Transport: github.NewDryRunTransport(nil)}).WithAuthToken(token)
+	restClient.UserAgent = fmt.Sprintf("github-mcp-server/%s", version)
+	restClient.BaseURL = apiHost.baseRESTURL
+	restClient.UploadURL = apiHost.uploadURL
+	return restClient
+}
+
+// checkTokenScopes compares the token's scopes to the tools' requirements and applies the scope check mode.
+// Failing to determine the scopes is not fatal, as the tools may still work.
+func checkTokenScopes(logger *slog.Logger, client *gogithub.Client, tsg *toolsets.ToolsetGroup, mode string) error {
+	tokenScopes, ok := fetchTokenScopes(logger, client)
+	if !ok {
+		return nil
+	}
+
+	unsatisfied, err := github.ApplyScopeCheck(tsg, tokenScopes, mode)
+	if err != nil {
+		return fmt.Errorf("failed to apply scope check: %w", err)
+	}
+	// Only report tools of enabled toolsets, others are of no concern to the user
+	var tools []string
+	for _, status := range unsatisfied {
+		if tsg.IsEnabled(status.Toolset) && !slices.Contains(tools, status.Tool) {
+			tools = append(tools, status.Tool)
+		}
+	}
+	action := "may fail"
+	if mode == github.ScopeCheckHide {
+		action = "were hidden"
+	}
+	logUnsatisfiedScopes(logger, action, tools)
+	return nil
+}
+
+// warnTokenScopes logs the tools among statuses whose requirement the token's scopes do not satisfy.
+func warnTokenScopes(logger *slog.Logger, client *gogithub.Client, statuses []github.ToolScopeStatus) {
+	tokenScopes, ok := fetchTokenScopes(logger, client)
+	if !ok {
+		return
+	}
+	var tools []string
+	for _, status := range statuses {
+		if !tokenScopes.HasAny(status.Required) && !slices.Contains(tools, status.Tool) {
+			tools = append(tools, status.Tool)
+		}
+	}
+	logUnsatisfiedScopes(logger, "may fail", tools)
+}
+
+// enabledToolScopes returns the scope requirements of the tools of enabled toolsets that need a scope.
+func enabledToolScopes(tsg *toolsets.ToolsetGroup) []github.ToolScopeStatus {
+	var statuses []github.ToolScopeStatus
+	for _, status := range github.CheckToolScopes(tsg, scopes.NewTokenScopes(nil)) {
+		if !status.Satisfied && tsg.IsEnabled(status.Toolset) {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// fetchTokenScopes returns the token's scopes, and false when they cannot be checked.
+func fetchTokenScopes(logger *slog.Logger, client *gogithub.Client) (scopes.TokenScopes, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tokenScopes, err := scopes.Fetch(ctx, client)
+	if err != nil {
+		logger.Warn("skipping token scope check", "error", err)
+		return scopes.TokenScopes{}, false
+	}
+	// Fine-grained and GitHub App tokens don't report their permissions
+	return tokenScopes, tokenScopes.Known
+}
+
+func logUnsatisfiedScopes(logger *slog.Logger, action string, tools []string) {
+	if len(tools) > 0 {
+		logger.Warn(fmt.Sprintf("tools that need token scopes the token does not have %s, run the doctor command for details", action), "tools", strings.Join(tools, ", "))
+	}
+}
+
+// checkCustomToolNames ensures custom tools cannot shadow built-in tools.
+func checkCustomToolNames(tsg *toolsets.ToolsetGroup, custom *toolsets.Toolset) error {
+	builtin := make(map[string]bool)
+	for _, ts := range tsg.Toolsets {
+		for _, tool := range ts.GetAvailableTools() {
+			builtin[tool.Tool.Name] = true
+		}
+	}
+	for _, tool := range custom.GetAvailableTools() {
+		if builtin[tool.Tool.Name] {
+			return fmt.Errorf("custom tool %s conflicts with a built-in tool", tool.Tool.Name)
+		}
+	}
+	return nil
+}
+
 type StdioServerConfig struct {
 	// Version of the server
 	Version string
@@ -233,8 +503,54 @@ type StdioServerConfig struct {
 	// LockdownMode indicates if we should enable lockdown mode
 	LockdownMode bool
 
+	// LockdownRedact replaces content from untrusted authors with a placeholder in lockdown mode instead of withholding it
+	LockdownRedact bool
+
 	// RepoAccessCacheTTL overrides the default TTL for repository access cache entries.
 	RepoAccessCacheTTL *time.Duration
+
+	// TrustPolicy decides which authors are trusted in lockdown mode
+	TrustPolicy lockdown.TrustPolicy
+
+	// TrustPolicyUpdates receives replacements for the trust policy, e.g. when the config file changes
+	TrustPolicyUpdates <-chan lockdown.TrustPolicy
+
+	// SanitizeContent removes hidden content from the user-authored fields of tool results
+	SanitizeContent bool
+
+	// PromptInjection configures scanning user-authored fields for prompt injection, when SanitizeContent is set
+	PromptInjection *sanitize.ScannerConfig
+
+	// URLPolicy restricts the hosts that links and images in user-authored content may point to, whether or not
+	// SanitizeContent is set
+	URLPolicy *sanitize.URLPolicy
+
+	// ScanSecrets scans the arguments of write tools for secrets before they are published
+	ScanSecrets bool
+
+	// SecretScanning configures the rules and action of secret scanning, when ScanSecrets is set
+	SecretScanning *secrets.Config
+
+	// CustomTools are tools declared in configuration and registered into the custom toolset
+	CustomTools []github.CustomToolDefinition
+
+	// Profile is the ID of a toolset profile to apply on top of EnabledToolsets
+	Profile string
+
+	// Profiles are profiles declared in configuration, overriding built-in profiles with the same ID
+	Profiles map[string]github.ToolsetProfile
+
+	// ScopeCheck determines how tools are treated when the token lacks the scopes they need (off, warn, hide or annotate)
+	ScopeCheck string
+
+	// Confirmation determines which tools need confirmation before they run (off, destructive or writes)
+	Confirmation string
+
+	// ConfirmationPolicy adds tools to, or exempts tools from, confirmation
+	ConfirmationPolicy github.ConfirmationPolicy
+
+	// DryRun makes every write tool return the requests it would send instead of sending them
+	DryRun bool
 }
 
 // RunStdioServer is not concurrent safe.
@@ -259,20 +575,36 @@ func RunStdioServer(cfg StdioServerConfig) error {
 		slogHandler = slog.NewTextHandler(logOutput, &slog.HandlerOptions{Level: slog.LevelInfo})
 	}
 	logger := slog.New(slogHandler)
-	logger.Info("starting server", "version", cfg.Version, "host", cfg.Host, "dynamicToolsets", cfg.DynamicToolsets, "readOnly", cfg.ReadOnly, "lockdownEnabled", cfg.LockdownMode)
+	logger.Info("starting server", "version", cfg.Version, "host", cfg.Host, "dynamicToolsets", cfg.DynamicToolsets, "readOnly", cfg.ReadOnly, "lockdownEnabled", cfg.LockdownMode, "profile", cfg.Profile)
 	stdLogger := log.New(logOutput, stdioServerLogPrefix, 0)
 
 	ghServer, err := NewMCPServer(MCPServerConfig{
-		Version:           cfg.Version,
-		Host:              cfg.Host,
-		Token:             cfg.Token,
-		EnabledToolsets:   cfg.EnabledToolsets,
-		DynamicToolsets:   cfg.DynamicToolsets,
-		ReadOnly:          cfg.ReadOnly,
-		Translator:        t,
-		ContentWindowSize: cfg.ContentWindowSize,
-		LockdownMode:      cfg.LockdownMode,
-		RepoAccessTTL:     cfg.RepoAccessCacheTTL,
+		Version:            cfg.Version,
+		Host:               cfg.Host,
+		Token:              cfg.Token,
+		EnabledToolsets:    cfg.EnabledToolsets,
+		DynamicToolsets:    cfg.DynamicToolsets,
+		ReadOnly:           cfg.ReadOnly,
+		Translator:         t,
+		ContentWindowSize:  cfg.ContentWindowSize,
+		LockdownMode:       cfg.LockdownMode,
+		LockdownRedact:     cfg.LockdownRedact,
+		RepoAccessTTL:      cfg.RepoAccessCacheTTL,
+		TrustPolicy:        cfg.TrustPolicy,
+		TrustPolicyUpdates: cfg.TrustPolicyUpdates,
+		SanitizeContent:    cfg.SanitizeContent,
+		PromptInjection:    cfg.PromptInjection,
+		URLPolicy:          cfg.URLPolicy,
+		ScanSecrets:        cfg.ScanSecrets,
+		SecretScanning:     cfg.SecretScanning,
+		CustomTools:        cfg.CustomTools,
+		Profile:            cfg.Profile,
+		Profiles:           cfg.Profiles,
+		ScopeCheck:         cfg.ScopeCheck,
+		Confirmation:       cfg.Confirmation,
+		ConfirmationPolicy: cfg.ConfirmationPolicy,
+		DryRun:             cfg.DryRun,
+		Logger:             logger,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create MCP server: %w", err)
@@ -0,0 +1,646 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// maxCherryPickCommits is the number of commits cherry_pick_commits applies in one call.
+const maxCherryPickCommits = 50
+
+// FileConflict is a file whose changes could not be applied cleanly.
+type FileConflict struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// ReplayedCommit is a commit applied by cherry_pick_commits or revert_commit.
+type ReplayedCommit struct {
+	SourceSHA string `json:"source_sha"`
+	// SHA is the new commit, unless the changes were already on the branch and the commit was skipped.
+	SHA     string `json:"sha,omitempty"`
+	Skipped bool   `json:"skipped,omitempty"`
+}
+
+// ReplayResult is the result of cherry_pick_commits and revert_commit. On conflict, the branch is left unchanged.
+type ReplayResult struct {
+	// Status is applied, unchanged or conflict.
+	Status            string           `json:"status"`
+	Branch            string           `json:"branch"`
+	HeadSHA           string           `json:"head_sha,omitempty"`
+	Commits           []ReplayedCommit `json:"commits,omitempty"`
+	ConflictingCommit string           `json:"conflicting_commit,omitempty"`
+	Conflicts         []FileConflict   `json:"conflicts,omitempty"`
+	Message           string           `json:"message"`
+}
+
+// treeFile is a file of a tree, or a zero treeFile for a path that is not in the tree.
+type treeFile struct {
+	SHA  string
+	Mode string
+	Type string
+}
+
+// replayStep is the change from the tree from to the tree to of a commit, applied as a new commit.
+type replayStep struct {
+	SourceSHA string
+	From      string
+	To        string
+	Message   string
+	Author    *github.CommitAuthor
+}
+
+// commitReplayer applies the changes of commits onto a branch, merging the files that the branch changed too.
+type commitReplayer struct {
+	ctx    context.Context
+	client *github.Client
+	owner  string
+	repo   string
+	trees  map[string]map[string]treeFile
+	blobs  map[string][]byte
+}
+
+func newCommitReplayer(ctx context.Context, client *github.Client, owner, repo string) *commitReplayer {
+	return &commitReplayer{
+		ctx:    ctx,
+		client: client,
+		owner:  owner,
+		repo:   repo,
+		trees:  make(map[string]map[string]treeFile),
+		blobs:  make(map[string][]byte),
+	}
+}
+
+// treeFiles returns the files of a tree by path. Trees too large to list in one request are refused, because
+// changes to the files that are left out would be lost.
+func (r *commitReplayer) treeFiles(sha string) (map[string]treeFile, *mcp.CallToolResult) {
+	if files, ok := r.trees[sha]; ok {
+		return files, nil
+	}
+	files := make(map[string]treeFile)
+	if sha != "" {
+		tree, resp, err := r.client.Git.GetTree(r.ctx, r.owner, r.repo, sha, true)
+		if err != nil {
+			return nil, ghErrors.NewGitHubAPIErrorResponse(r.ctx, fmt.Sprintf("failed to get tree: %s", sha), resp, err)
+		}
+		_ = resp.Body.Close()
+		if tree.GetTruncated() {
+			return nil, mcp.NewToolResultError(fmt.Sprintf("tree %s has too many files to be listed in one request, so its changes cannot be computed server-side", sha))
+		}
+		for _, entry := range tree.Entries {
+			if entry.GetType() == "tree" {
+				continue
+			}
+			files[entry.GetPath()] = treeFile{SHA: entry.GetSHA(), Mode: entry.GetMode(), Type: entry.GetType()}
+		}
+	}
+	r.trees[sha] = files
+	return files, nil
+}
+
+// blob returns the content of a blob.
+func (r *commitReplayer) blob(sha string) ([]byte, *mcp.CallToolResult) {
+	if content, ok := r.blobs[sha]; ok {
+		return content, nil
+	}
+	content, resp, err := r.client.Git.GetBlobRaw(r.ctx, r.owner, r.repo, sha)
+	if err != nil {
+		return nil, ghErrors.NewGitHubAPIErrorResponse(r.ctx, fmt.Sprintf("failed to get blob: %s", sha), resp, err)
+	}
+	_ = resp.Body.Close()
+	r.blobs[sha] = content
+	return content, nil
+}
+
+// mergeFile merges the changes from base to theirs into ours, where all three have content. It returns the SHA
+// of the merged blob, or the reason of the conflict.
+func (r *commitReplayer) mergeFile(path string, base, ours, theirs treeFile) (string, string, *mcp.CallToolResult) {
+	if base.Type != "blob" || ours.Type != "blob" || theirs.Type != "blob" {
+		return "", "submodule changed both by the commit and on the branch", nil
+	}
+	var contents [3][]byte
+	for i, file := range []treeFile{base, ours, theirs} {
+		content, errResult := r.blob(file.SHA)
+		if errResult != nil {
+			return "", "", errResult
+		}
+		if !utf8.Valid(content) || bytes.IndexByte(content, 0) >= 0 {
+			return "", "binary file changed both by the commit and on the branch", nil
+		}
+		contents[i] = content
+	}
+
+	merged, conflicts := mergeLines(textLines(string(contents[0])), textLines(string(contents[1])), textLines(string(contents[2])))
+	if conflicts > 0 {
+		return "", fmt.Sprintf("%d change(s) of the commit overlap changes on the branch", conflicts), nil
+	}
+	content := strings.Join(merged, "")
+	blob, resp, err := r.client.Git.CreateBlob(r.ctx, r.owner, r.repo, github.Blob{
+		Content:  github.Ptr(content),
+		Encoding: github.Ptr("utf-8"),
+	})
+	if err != nil {
+		return "", "", ghErrors.NewGitHubAPIErrorResponse(r.ctx, fmt.Sprintf("failed to create blob for %s", path), resp, err)
+	}
+	_ = resp.Body.Close()
+	r.blobs[blob.GetSHA()] = []byte(content)
+	return blob.GetSHA(), "", nil
+}
+
+// applyChange applies the change from the tree from to the tree to onto the files of current, which it updates. It
+// returns the tree entries that make the change, or the files that conflict.
+func (r *commitReplayer) applyChange(current map[string]treeFile, from, to string) ([]*github.TreeEntry, []FileConflict, *mcp.CallToolResult) {
+	fromFiles, errResult := r.treeFiles(from)
+	if errResult != nil {
+		return nil, nil, errResult
+	}
+	toFiles, errResult := r.treeFiles(to)
+	if errResult != nil {
+		return nil, nil, errResult
+	}
+
+	var paths []string
+	for path, file := range fromFiles {
+		if toFiles[path] != file {
+			paths = append(paths, path)
+		}
+	}
+	for path := range toFiles {
+		if _, ok := fromFiles[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var entries []*github.TreeEntry
+	var conflicts []FileConflict
+	for _, path := range paths {
+		base, theirs, ours := fromFiles[path], toFiles[path], current[path]
+
+		result := ours
+		switch {
+		case ours.SHA == base.SHA:
+			result.SHA, result.Type = theirs.SHA, theirs.Type
+		case ours.SHA == theirs.SHA, theirs.SHA == base.SHA:
+		case base.SHA == "":
+			conflicts = append(conflicts, FileConflict{Path: path, Reason: "added with different content by the commit and on the branch"})
+			continue
+		case theirs.SHA == "":
+			conflicts = append(conflicts, FileConflict{Path: path, Reason: "deleted by the commit, but changed on the branch"})
+			continue
+		case ours.SHA == "":
+			conflicts = append(conflicts, FileConflict{Path: path, Reason: "changed by the commit, but deleted on the branch"})
+			continue
+		default:
+			sha, reason, errResult := r.mergeFile(path, base, ours, theirs)
+			if errResult != nil {
+				return nil, nil, errResult
+			}
+			if reason != "" {
+				conflicts = append(conflicts, FileConflict{Path: path, Reason: reason})
+				continue
+			}
+			result.SHA = sha
+		}
+		if ours.Mode == base.Mode {
+			result.Mode = theirs.Mode
+		}
+		if result == ours {
+			continue
+		}
+
+		if result.SHA == "" {
+			// An entry with neither content nor SHA deletes the file
+			entries = append(entries, &github.TreeEntry{
+				Path: github.Ptr(path),
+				Mode: github.Ptr(ours.Mode),
+				Type: github.Ptr(ours.Type),
+			})
+			delete(current, path)
+			continue
+		}
+		entries = append(entries, &github.TreeEntry{
+			Path: github.Ptr(path),
+			Mode: github.Ptr(result.Mode),
+			Type: github.Ptr(result.Type),
+			SHA:  github.Ptr(result.SHA),
+		})
+		current[path] = result
+	}
+	return entries, conflicts, nil
+}
+
+// replay applies steps as new commits on top of headSHA, and points branch to the last one. With fromBase, the
+// branch is created instead of updated, even if every step is skipped. Nothing is written to the branch if any
+// step conflicts.
+func (r *commitReplayer) replay(branch, headSHA string, fromBase bool, steps []replayStep) (*mcp.CallToolResult, error) {
+	head, resp, err := r.client.Git.GetCommit(r.ctx, r.owner, r.repo, headSHA)
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(r.ctx, fmt.Sprintf("failed to get commit: %s", headSHA), resp, err), nil
+	}
+	_ = resp.Body.Close()
+	headFiles, errResult := r.treeFiles(head.GetTree().GetSHA())
+	if errResult != nil {
+		return errResult, nil
+	}
+	current := make(map[string]treeFile, len(headFiles))
+	for path, file := range headFiles {
+		current[path] = file
+	}
+	treeSHA := head.GetTree().GetSHA()
+
+	result := ReplayResult{Branch: branch}
+	newHead := headSHA
+	for _, step := range steps {
+		entries, conflicts, errResult := r.applyChange(current, step.From, step.To)
+		if errResult != nil {
+			return errResult, nil
+		}
+		if len(conflicts) > 0 {
+			result.Status = "conflict"
+			result.ConflictingCommit = step.SourceSHA
+			result.Conflicts = conflicts
+			result.Message = fmt.Sprintf("The changes of commit %s conflict with %s, so branch %s was left unchanged. Resolve the conflicts in the listed files and commit them, e.g. with push_files.",
+				step.SourceSHA, headSHA, branch)
+			conflictResult := MarshalledTextResult(result)
+			conflictResult.IsError = true
+			return conflictResult, nil
+		}
+		if len(entries) == 0 {
+			result.Commits = append(result.Commits, ReplayedCommit{SourceSHA: step.SourceSHA, Skipped: true})
+			continue
+		}
+
+		tree, resp, err := r.client.Git.CreateTree(r.ctx, r.owner, r.repo, treeSHA, entries)
+		if err != nil {
+			return ghErrors.NewGitHubAPIErrorResponse(r.ctx, "failed to create tree", resp, err), nil
+		}
+		_ = resp.Body.Close()
+		commit, resp, err := r.client.Git.CreateCommit(r.ctx, r.owner, r.repo, github.Commit{
+			Message: github.Ptr(step.Message),
+			Tree:    tree,
+			Parents: []*github.Commit{{SHA: github.Ptr(newHead)}},
+			Author:  step.Author,
+		}, nil)
+		if err != nil {
+			return ghErrors.NewGitHubAPIErrorResponse(r.ctx, "failed to create commit", resp, err), nil
+		}
+		_ = resp.Body.Close()
+		treeSHA, newHead = tree.GetSHA(), commit.GetSHA()
+		result.Commits = append(result.Commits, ReplayedCommit{SourceSHA: step.SourceSHA, SHA: commit.GetSHA()})
+	}
+
+	// A branch requested from base is created even if it gets no new commit
+	if newHead == headSHA && !fromBase {
+		result.Status = "unchanged"
+		result.HeadSHA = headSHA
+		result.Message = fmt.Sprintf("The changes are already on %s, so no commit was created and branch %s was not written.", headSHA, branch)
+		return MarshalledTextResult(result), nil
+	}
+
+	if fromBase {
+		_, resp, err := r.client.Git.CreateRef(r.ctx, r.owner, r.repo, github.CreateRef{Ref: "refs/heads/" + branch, SHA: newHead})
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
+				return mcp.NewToolResultError(fmt.Sprintf("branch %s already exists: leave out base to apply the commits to it, or choose another branch name", branch)), nil
+			}
+			return ghErrors.NewGitHubAPIErrorResponse(r.ctx, fmt.Sprintf("failed to create branch: %s", branch), resp, err), nil
+		}
+		_ = resp.Body.Close()
+	} else {
+		_, resp, err := r.client.Git.UpdateRef(r.ctx, r.owner, r.repo, "refs/heads/"+branch, github.UpdateRef{SHA: newHead, Force: github.Ptr(false)})
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
+				return mcp.NewToolResultError(fmt.Sprintf("branch %s moved from %s while the commits were applied: try again", branch, headSHA)), nil
+			}
+			return ghErrors.NewGitHubAPIErrorResponse(r.ctx, fmt.Sprintf("failed to update branch: %s", branch), resp, err), nil
+		}
+		_ = resp.Body.Close()
+	}
+
+	result.Status = "applied"
+	result.HeadSHA = newHead
+	result.Message = fmt.Sprintf("Branch %s now points to %s.", branch, newHead)
+	if newHead == headSHA {
+		result.Message = fmt.Sprintf("The changes are already on %s, so no commit was created. Branch %s was created at %s.", headSHA, branch, headSHA)
+	}
+	return MarshalledTextResult(result), nil
+}
+
+// replayHead returns the commit to apply changes on top of: base if it is given, or else the head of branch.
+func replayHead(ctx context.Context, client *github.Client, owner, repo, branch, base string) (string, *mcp.CallToolResult) {
+	if base != "" {
+		sha, resp, err := client.Repositories.GetCommitSHA1(ctx, owner, repo, base, "")
+		if err != nil {
+			return "", ghErrors.NewGitHubAPIErrorResponse(ctx, fmt.Sprintf("failed to resolve base: %s", base), resp, err)
+		}
+		_ = resp.Body.Close()
+		return sha, nil
+	}
+	ref, resp, err := client.Git.GetRef(ctx, owner, repo, "refs/heads/"+branch)
+	if err != nil {
+		return "", ghErrors.NewGitHubAPIErrorResponse(ctx, fmt.Sprintf("failed to get branch: %s", branch), resp, err)
+	}
+	_ = resp.Body.Close()
+	return ref.GetObject().GetSHA(), nil
+}
+
+// singleParentCommit gets a commit and the tree of its parent. Merge commits are refused, because which parent
+// their changes are relative to is ambiguous.
+func singleParentCommit(ctx context.Context, client *github.Client, owner, repo, sha string) (*github.Commit, string, *mcp.CallToolResult) {
+	commit, resp, err := client.Git.GetCommit(ctx, owner, repo, sha)
+	if err != nil {
+		return nil, "", ghErrors.NewGitHubAPIErrorResponse(ctx, fmt.Sprintf("failed to get commit: %s", sha), resp, err)
+	}
+	_ = resp.Body.Close()
+	switch len(commit.Parents) {
+	case 0:
+		return commit, "", nil
+	case 1:
+	default:
+		return nil, "", mcp.NewToolResultError(fmt.Sprintf("commit %s is a merge commit, which cannot be applied: apply the commits it merges instead", sha))
+	}
+	parent, resp, err := client.Git.GetCommit(ctx, owner, repo, commit.Parents[0].GetSHA())
+	if err != nil {
+		return nil, "", ghErrors.NewGitHubAPIErrorResponse(ctx, fmt.Sprintf("failed to get commit: %s", commit.Parents[0].GetSHA()), resp, err)
+	}
+	_ = resp.Body.Close()
+	return commit, parent.GetTree().GetSHA(), nil
+}
+
+// CherryPickCommits creates a tool to apply the changes of commits onto a branch.
+func CherryPickCommits(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("cherry_pick_commits",
+			mcp.WithDescription(t("TOOL_CHERRY_PICK_COMMITS_DESCRIPTION", "Apply the changes of commits, in order, as new commits on a branch, or on a new branch created from base, without a local clone. Files changed both by a commit and on the branch are merged line by line. If any change does not apply cleanly, nothing is written and the conflicting files are reported. Renames are applied as a deletion and an addition.")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_CHERRY_PICK_COMMITS_USER_TITLE", "Cherry-pick commits"),
+				ReadOnlyHint: ToBoolPtr(false),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithArray("commits",
+				mcp.Required(),
+				mcp.Description("SHAs of the commits to apply, oldest first. Merge commits are not supported"),
+				mcp.Items(map[string]interface{}{
+					"type": "string",
+				}),
+			),
+			mcp.WithString("branch",
+				mcp.Required(),
+				mcp.Description("Branch to commit to (e.g., 'release-1.2')"),
+			),
+			mcp.WithString("base",
+				mcp.Description("Branch, tag or SHA to create branch from. Without it, branch must exist"),
+			),
+			mcp.WithBoolean("record_origin",
+				mcp.Description("Whether to append '(cherry picked from commit <sha>)' to the commit messages. Default is true"),
+				mcp.DefaultBool(true),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			commits, err := OptionalStringArrayParam(request, "commits")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if len(commits) == 0 || len(commits) > maxCherryPickCommits {
+				return mcp.NewToolResultError(fmt.Sprintf("commits must list between 1 and %d commits", maxCherryPickCommits)), nil
+			}
+			branch, err := RequiredParam[string](request, "branch")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			base, err := OptionalParam[string](request, "base")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			recordOrigin, err := OptionalBoolParamWithDefault(request, "record_origin", true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			steps := make([]replayStep, 0, len(commits))
+			for _, sha := range commits {
+				commit, parentTree, errResult := singleParentCommit(ctx, client, owner, repo, sha)
+				if errResult != nil {
+					return errResult, nil
+				}
+				message := commit.GetMessage()
+				if recordOrigin {
+					message = fmt.Sprintf("%s\n\n(cherry picked from commit %s)", strings.TrimRight(message, "\n"), commit.GetSHA())
+				}
+				steps = append(steps, replayStep{
+					SourceSHA: commit.GetSHA(),
+					From:      parentTree,
+					To:        commit.GetTree().GetSHA(),
+					Message:   message,
+					Author:    commit.Author,
+				})
+			}
+
+			headSHA, errResult := replayHead(ctx, client, owner, repo, branch, base)
+			if errResult != nil {
+				return errResult, nil
+			}
+			return newCommitReplayer(ctx, client, owner, repo).replay(branch, headSHA, base != "", steps)
+		}
+}
+
+// RevertCommit creates a tool to undo the changes of a commit with a new commit on a branch.
+func RevertCommit(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("revert_commit",
+			mcp.WithDescription(t("TOOL_REVERT_COMMIT_DESCRIPTION", "Undo the changes of a commit with a new commit on a branch, or on a new branch created from base, without a local clone. Files changed since the commit are merged line by line. If the revert does not apply cleanly, nothing is written and the conflicting files are reported.")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_REVERT_COMMIT_USER_TITLE", "Revert commit"),
+				ReadOnlyHint: ToBoolPtr(false),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("sha",
+				mcp.Required(),
+				mcp.Description("SHA of the commit to revert. Merge commits are not supported"),
+			),
+			mcp.WithString("branch",
+				mcp.Required(),
+				mcp.Description("Branch to commit the revert to"),
+			),
+			mcp.WithString("base",
+				mcp.Description("Branch, tag or SHA to create branch from. Without it, branch must exist"),
+			),
+			mcp.WithString("message",
+				mcp.Description("Commit message. Defaults to 'Revert \"<subject>\"' and the reverted SHA"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			sha, err := RequiredParam[string](request, "sha")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			branch, err := RequiredParam[string](request, "branch")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			base, err := OptionalParam[string](request, "base")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			message, err := OptionalParam[string](request, "message")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			commit, parentTree, errResult := singleParentCommit(ctx, client, owner, repo, sha)
+			if errResult != nil {
+				return errResult, nil
+			}
+			if message == "" {
+				subject, _, _ := strings.Cut(commit.GetMessage(), "\n")
+				message = fmt.Sprintf("Revert %q\n\nThis reverts commit %s.", subject, commit.GetSHA())
+			}
+
+			headSHA, errResult := replayHead(ctx, client, owner, repo, branch, base)
+			if errResult != nil {
+				return errResult, nil
+			}
+			return newCommitReplayer(ctx, client, owner, repo).replay(branch, headSHA, base != "", []replayStep{{
+				SourceSHA: commit.GetSHA(),
+				From:      commit.GetTree().GetSHA(),
+				To:        parentTree,
+				Message:   message,
+			}})
+		}
+}
+
+// textLines splits s into lines that keep their newlines, so that joining them gives s back.
+func textLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// mergeHunk replaces the lines base[start:end] with lines.
+type mergeHunk struct {
+	start, end int
+	lines      []string
+}
+
+// diffHunks returns the hunks that change base into changed. Popular lines, such as blank lines, are not treated as
+// junk, which would make hunks larger than they are and merges conflict needlessly.
+func diffHunks(base, changed []string) []mergeHunk {
+	var hunks []mergeHunk
+	for _, op := range difflib.NewMatcherWithJunk(base, changed, false, nil).GetOpCodes() {
+		if op.Tag != 'e' {
+			hunks = append(hunks, mergeHunk{start: op.I1, end: op.I2, lines: changed[op.J1:op.J2]})
+		}
+	}
+	return hunks
+}
+
+// applyHunks returns the lines base[start:end] with hunks applied.
+func applyHunks(base []string, start, end int, hunks []mergeHunk) []string {
+	var lines []string
+	for _, hunk := range hunks {
+		lines = append(lines, base[start:hunk.start]...)
+		lines = append(lines, hunk.lines...)
+		start = hunk.end
+	}
+	return append(lines, base[start:end]...)
+}
+
+// mergeLines merges the changes from base to ours and from base to theirs. As in git, changes of both sides to the
+// same or adjacent lines conflict unless they are identical. It returns the merged lines and the number of
+// conflicts, in which case the merged lines are not meaningful.
+func mergeLines(base, ours, theirs []string) ([]string, int) {
+	oursHunks, theirsHunks := diffHunks(base, ours), diffHunks(base, theirs)
+	var merged []string
+	conflicts := 0
+	pos, i, j := 0, 0, 0
+	for i < len(oursHunks) || j < len(theirsHunks) {
+		// A region starts at the first remaining hunk, and grows while hunks of either side touch it
+		var start int
+		if j == len(theirsHunks) || (i < len(oursHunks) && oursHunks[i].start <= theirsHunks[j].start) {
+			start = oursHunks[i].start
+		} else {
+			start = theirsHunks[j].start
+		}
+		end := start
+		firstOurs, firstTheirs := i, j
+		for {
+			if i < len(oursHunks) && oursHunks[i].start <= end {
+				end = max(end, oursHunks[i].end)
+				i++
+			} else if j < len(theirsHunks) && theirsHunks[j].start <= end {
+				end = max(end, theirsHunks[j].end)
+				j++
+			} else {
+				break
+			}
+		}
+
+		merged = append(merged, base[pos:start]...)
+		oursRegion := applyHunks(base, start, end, oursHunks[firstOurs:i])
+		theirsRegion := applyHunks(base, start, end, theirsHunks[firstTheirs:j])
+		switch {
+		case firstTheirs == j:
+			merged = append(merged, oursRegion...)
+		case firstOurs == i, slices.Equal(oursRegion, theirsRegion):
+			merged = append(merged, theirsRegion...)
+		default:
+			conflicts++
+		}
+		pos = end
+	}
+	return append(merged, base[pos:]...), conflicts
+}
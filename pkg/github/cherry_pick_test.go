@@ -0,0 +1,457 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/github/github-mcp-server/internal/toolsnaps"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_mergeLines(t *testing.T) {
+	base := "one\ntwo\nthree\nfour\nfive\n"
+
+	tests := []struct {
+		name              string
+		ours              string
+		theirs            string
+		expectedMerged    string
+		expectedConflicts int
+	}{
+		{
+			name:           "changes to different lines",
+			ours:           "ONE\ntwo\nthree\nfour\nfive\n",
+			theirs:         "one\ntwo\nthree\nfour\nFIVE\n",
+			expectedMerged: "ONE\ntwo\nthree\nfour\nFIVE\n",
+		},
+		{
+			name:           "insertion and deletion",
+			ours:           "zero\none\ntwo\nthree\nfour\nfive\n",
+			theirs:         "one\ntwo\nfour\nfive\n",
+			expectedMerged: "zero\none\ntwo\nfour\nfive\n",
+		},
+		{
+			name:           "identical changes",
+			ours:           "one\nTWO\nthree\nfour\nfive\n",
+			theirs:         "one\nTWO\nthree\nfour\nfive\n",
+			expectedMerged: "one\nTWO\nthree\nfour\nfive\n",
+		},
+		{
+			name:              "different changes to the same line",
+			ours:              "one\nTWO\nthree\nfour\nfive\n",
+			theirs:            "one\n2\nthree\nfour\nfive\n",
+			expectedConflicts: 1,
+		},
+		{
+			name:              "changes to adjacent lines",
+			ours:              "one\nTWO\nthree\nfour\nfive\n",
+			theirs:            "one\ntwo\nTHREE\nfour\nfive\n",
+			expectedConflicts: 1,
+		},
+		{
+			name:           "missing final newline is kept",
+			ours:           "ONE\ntwo\nthree\nfour\nfive\n",
+			theirs:         "one\ntwo\nthree\nfour\nfive",
+			expectedMerged: "ONE\ntwo\nthree\nfour\nfive",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, conflicts := mergeLines(textLines(base), textLines(tc.ours), textLines(tc.theirs))
+			assert.Equal(t, tc.expectedConflicts, conflicts)
+			if tc.expectedConflicts == 0 {
+				assert.Equal(t, tc.expectedMerged, strings.Join(merged, ""))
+			}
+		})
+	}
+}
+
+// gitObjectsRepo is a repository whose commits, trees and blobs are served by SHA.
+type gitObjectsRepo struct {
+	commits map[string]*github.Commit
+	trees   map[string]map[string]string
+	blobs   map[string]string
+}
+
+// mockOptions serves the objects of the repository. Tree entries are blobs, keyed by path.
+func (r gitObjectsRepo) mockOptions(t *testing.T) []mock.MockBackendOption {
+	return []mock.MockBackendOption{
+		mock.WithRequestMatchHandler(
+			mock.GetReposGitCommitsByOwnerByRepoByCommitSha,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				commit, ok := r.commits[path.Base(req.URL.Path)]
+				if !ok {
+					mockResponse(t, http.StatusNotFound, map[string]string{"message": "Not Found"})(w, req)
+					return
+				}
+				mockResponse(t, http.StatusOK, commit)(w, req)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				sha := path.Base(req.URL.Path)
+				tree := &github.Tree{SHA: github.Ptr(sha), Truncated: github.Ptr(false)}
+				for p, blob := range r.trees[sha] {
+					tree.Entries = append(tree.Entries, &github.TreeEntry{
+						Path: github.Ptr(p),
+						Mode: github.Ptr("100644"),
+						Type: github.Ptr("blob"),
+						SHA:  github.Ptr(blob),
+					})
+				}
+				mockResponse(t, http.StatusOK, tree)(w, req)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposGitBlobsByOwnerByRepoByFileSha,
+			http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				_, _ = w.Write([]byte(r.blobs[path.Base(req.URL.Path)]))
+			}),
+		),
+	}
+}
+
+// cherryPickRepo has a commit c1 that changes a.txt, adds new.txt and deletes old.txt, and a branch release at h1.
+func cherryPickRepo(branchFiles map[string]string) gitObjectsRepo {
+	return gitObjectsRepo{
+		commits: map[string]*github.Commit{
+			"p1": {SHA: github.Ptr("p1"), Tree: &github.Tree{SHA: github.Ptr("tp")}},
+			"c1": {
+				SHA:     github.Ptr("c1"),
+				Message: github.Ptr("Fix greeting\n"),
+				Author:  &github.CommitAuthor{Name: github.Ptr("Octocat"), Email: github.Ptr("octocat@github.com")},
+				Tree:    &github.Tree{SHA: github.Ptr("tc")},
+				Parents: []*github.Commit{{SHA: github.Ptr("p1")}},
+			},
+			"h1": {SHA: github.Ptr("h1"), Tree: &github.Tree{SHA: github.Ptr("th")}},
+		},
+		trees: map[string]map[string]string{
+			"tp": {"a.txt": "a0", "b.txt": "b0", "old.txt": "o0"},
+			"tc": {"a.txt": "a1", "b.txt": "b0", "new.txt": "n1"},
+			"th": branchFiles,
+		},
+		blobs: map[string]string{
+			"a0": "hello\nworld\n\nbye\n",
+			"a1": "hello\nworld\n\nbye!\n",
+			"a2": "hi\nworld\n\nbye\n",
+			"a3": "hello\nworld\n\nfarewell\n",
+		},
+	}
+}
+
+func Test_CherryPickCommits(t *testing.T) {
+	tool, _ := CherryPickCommits(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "cherry_pick_commits", tool.Name)
+	assert.False(t, *tool.Annotations.ReadOnlyHint)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "commits", "branch"})
+
+	headRef := mock.WithRequestMatchHandler(
+		mock.GetReposGitRefByOwnerByRepoByRef,
+		expectPath(t, "/repos/owner/repo/git/ref/heads/release").andThen(
+			mockResponse(t, http.StatusOK, &github.Reference{
+				Ref:    github.Ptr("refs/heads/release"),
+				Object: &github.GitObject{SHA: github.Ptr("h1")},
+			}),
+		),
+	)
+	createCommit := mock.WithRequestMatchHandler(
+		mock.PostReposGitCommitsByOwnerByRepo,
+		expectRequestBody(t, map[string]any{
+			"message": "Fix greeting\n\n(cherry picked from commit c1)",
+			"tree":    "t2",
+			"parents": []any{"h1"},
+			"author":  map[string]any{"name": "Octocat", "email": "octocat@github.com"},
+		}).andThen(
+			mockResponse(t, http.StatusCreated, &github.Commit{SHA: github.Ptr("c2")}),
+		),
+	)
+
+	tests := []struct {
+		name              string
+		branchFiles       map[string]string
+		extraMocks        []mock.MockBackendOption
+		requestArgs       map[string]interface{}
+		expectError       bool
+		expectedErrMsg    string
+		expectedResult    ReplayResult
+		expectedConflicts []FileConflict
+	}{
+		{
+			name:        "applies commit to unchanged files",
+			branchFiles: map[string]string{"a.txt": "a0", "b.txt": "b9", "old.txt": "o0"},
+			extraMocks: []mock.MockBackendOption{
+				headRef,
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitTreesByOwnerByRepo,
+					expectRequestBody(t, map[string]any{
+						"base_tree": "th",
+						"tree": []any{
+							map[string]any{"path": "a.txt", "mode": "100644", "type": "blob", "sha": "a1"},
+							map[string]any{"path": "new.txt", "mode": "100644", "type": "blob", "sha": "n1"},
+							map[string]any{"path": "old.txt", "mode": "100644", "type": "blob", "sha": nil},
+						},
+					}).andThen(
+						mockResponse(t, http.StatusCreated, &github.Tree{SHA: github.Ptr("t2")}),
+					),
+				),
+				createCommit,
+				mock.WithRequestMatchHandler(
+					mock.PatchReposGitRefsByOwnerByRepoByRef,
+					expect(t, expectations{
+						path:        "/repos/owner/repo/git/refs/heads/release",
+						requestBody: map[string]any{"sha": "c2", "force": false},
+					}).andThen(
+						mockResponse(t, http.StatusOK, &github.Reference{Ref: github.Ptr("refs/heads/release")}),
+					),
+				),
+			},
+			requestArgs: map[string]interface{}{"commits": []interface{}{"c1"}, "branch": "release"},
+			expectedResult: ReplayResult{
+				Status:  "applied",
+				Branch:  "release",
+				HeadSHA: "c2",
+				Commits: []ReplayedCommit{{SourceSHA: "c1", SHA: "c2"}},
+			},
+		},
+		{
+			name:        "merges file changed on the branch",
+			branchFiles: map[string]string{"a.txt": "a2", "b.txt": "b0", "old.txt": "o0", "new.txt": "n1"},
+			extraMocks: []mock.MockBackendOption{
+				headRef,
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitBlobsByOwnerByRepo,
+					expectRequestBody(t, map[string]any{"content": "hi\nworld\n\nbye!\n", "encoding": "utf-8"}).andThen(
+						mockResponse(t, http.StatusCreated, &github.Blob{SHA: github.Ptr("am")}),
+					),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitTreesByOwnerByRepo,
+					expectRequestBody(t, map[string]any{
+						"base_tree": "th",
+						"tree": []any{
+							map[string]any{"path": "a.txt", "mode": "100644", "type": "blob", "sha": "am"},
+							map[string]any{"path": "old.txt", "mode": "100644", "type": "blob", "sha": nil},
+						},
+					}).andThen(
+						mockResponse(t, http.StatusCreated, &github.Tree{SHA: github.Ptr("t2")}),
+					),
+				),
+				createCommit,
+				mock.WithRequestMatch(mock.PatchReposGitRefsByOwnerByRepoByRef, &github.Reference{Ref: github.Ptr("refs/heads/release")}),
+			},
+			requestArgs: map[string]interface{}{"commits": []interface{}{"c1"}, "branch": "release"},
+			expectedResult: ReplayResult{
+				Status:  "applied",
+				Branch:  "release",
+				HeadSHA: "c2",
+				Commits: []ReplayedCommit{{SourceSHA: "c1", SHA: "c2"}},
+			},
+		},
+		{
+			name:        "reports conflicts without writing",
+			branchFiles: map[string]string{"a.txt": "a3", "b.txt": "b0", "old.txt": "o9"},
+			extraMocks:  []mock.MockBackendOption{headRef},
+			requestArgs: map[string]interface{}{"commits": []interface{}{"c1"}, "branch": "release"},
+			expectError: true,
+			expectedResult: ReplayResult{
+				Status:            "conflict",
+				Branch:            "release",
+				ConflictingCommit: "c1",
+			},
+			expectedConflicts: []FileConflict{
+				{Path: "a.txt", Reason: "1 change(s) of the commit overlap changes on the branch"},
+				{Path: "old.txt", Reason: "deleted by the commit, but changed on the branch"},
+			},
+		},
+		{
+			name:        "skips changes already on the branch",
+			branchFiles: map[string]string{"a.txt": "a1", "b.txt": "b0", "new.txt": "n1"},
+			extraMocks:  []mock.MockBackendOption{headRef},
+			requestArgs: map[string]interface{}{"commits": []interface{}{"c1"}, "branch": "release"},
+			expectedResult: ReplayResult{
+				Status:  "unchanged",
+				Branch:  "release",
+				HeadSHA: "h1",
+				Commits: []ReplayedCommit{{SourceSHA: "c1", Skipped: true}},
+			},
+		},
+		{
+			name:        "creates branch from base",
+			branchFiles: map[string]string{"a.txt": "a0", "b.txt": "b0", "old.txt": "o0"},
+			extraMocks: []mock.MockBackendOption{
+				mock.WithRequestMatchHandler(
+					mock.GetReposCommitsByOwnerByRepoByRef,
+					expectPath(t, "/repos/owner/repo/commits/v1.2.0").andThen(
+						func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("h1")) },
+					),
+				),
+				mock.WithRequestMatch(mock.PostReposGitTreesByOwnerByRepo, &github.Tree{SHA: github.Ptr("t2")}),
+				createCommit,
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitRefsByOwnerByRepo,
+					expectRequestBody(t, map[string]any{"ref": "refs/heads/backport-fix", "sha": "c2"}).andThen(
+						mockResponse(t, http.StatusCreated, &github.Reference{Ref: github.Ptr("refs/heads/backport-fix")}),
+					),
+				),
+			},
+			requestArgs: map[string]interface{}{"commits": []interface{}{"c1"}, "branch": "backport-fix", "base": "v1.2.0"},
+			expectedResult: ReplayResult{
+				Status:  "applied",
+				Branch:  "backport-fix",
+				HeadSHA: "c2",
+				Commits: []ReplayedCommit{{SourceSHA: "c1", SHA: "c2"}},
+			},
+		},
+		{
+			name:        "creates branch from base when the changes are already there",
+			branchFiles: map[string]string{"a.txt": "a1", "b.txt": "b0", "new.txt": "n1"},
+			extraMocks: []mock.MockBackendOption{
+				mock.WithRequestMatchHandler(
+					mock.GetReposCommitsByOwnerByRepoByRef,
+					http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("h1")) }),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitRefsByOwnerByRepo,
+					expectRequestBody(t, map[string]any{"ref": "refs/heads/backport-fix", "sha": "h1"}).andThen(
+						mockResponse(t, http.StatusCreated, &github.Reference{Ref: github.Ptr("refs/heads/backport-fix")}),
+					),
+				),
+			},
+			requestArgs: map[string]interface{}{"commits": []interface{}{"c1"}, "branch": "backport-fix", "base": "v1.2.0"},
+			expectedResult: ReplayResult{
+				Status:  "applied",
+				Branch:  "backport-fix",
+				HeadSHA: "h1",
+				Commits: []ReplayedCommit{{SourceSHA: "c1", Skipped: true}},
+			},
+		},
+		{
+			name:        "branch moved during cherry-pick",
+			branchFiles: map[string]string{"a.txt": "a0", "b.txt": "b0", "old.txt": "o0"},
+			extraMocks: []mock.MockBackendOption{
+				headRef,
+				mock.WithRequestMatch(mock.PostReposGitTreesByOwnerByRepo, &github.Tree{SHA: github.Ptr("t2")}),
+				createCommit,
+				mock.WithRequestMatchHandler(
+					mock.PatchReposGitRefsByOwnerByRepoByRef,
+					mockResponse(t, http.StatusUnprocessableEntity, map[string]string{"message": "Update is not a fast forward"}),
+				),
+			},
+			requestArgs:    map[string]interface{}{"commits": []interface{}{"c1"}, "branch": "release"},
+			expectError:    true,
+			expectedErrMsg: "branch release moved from h1 while the commits were applied: try again",
+		},
+		{
+			name:           "merge commit",
+			branchFiles:    map[string]string{},
+			extraMocks:     nil,
+			requestArgs:    map[string]interface{}{"commits": []interface{}{"m1"}, "branch": "release"},
+			expectError:    true,
+			expectedErrMsg: "commit m1 is a merge commit, which cannot be applied",
+		},
+		{
+			name:           "no commits",
+			branchFiles:    map[string]string{},
+			requestArgs:    map[string]interface{}{"commits": []interface{}{}, "branch": "release"},
+			expectError:    true,
+			expectedErrMsg: "commits must list between 1 and 50 commits",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := cherryPickRepo(tc.branchFiles)
+			repo.commits["m1"] = &github.Commit{SHA: github.Ptr("m1"), Parents: []*github.Commit{{SHA: github.Ptr("p1")}, {SHA: github.Ptr("c1")}}}
+			client := github.NewClient(mock.NewMockedHTTPClient(append(repo.mockOptions(t), tc.extraMocks...)...))
+			_, handler := CherryPickCommits(stubGetClientFn(client), translations.NullTranslationHelper)
+
+			args := map[string]interface{}{"owner": "owner", "repo": "repo"}
+			for k, v := range tc.requestArgs {
+				args[k] = v
+			}
+			result, err := handler(context.Background(), createMCPRequest(args))
+			require.NoError(t, err)
+
+			if tc.expectedErrMsg != "" {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+			assert.Equal(t, tc.expectError, result.IsError)
+
+			var response ReplayResult
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+			assert.Equal(t, tc.expectedConflicts, response.Conflicts)
+			assert.NotEmpty(t, response.Message)
+			response.Conflicts, response.Message = nil, ""
+			assert.Equal(t, tc.expectedResult, response)
+		})
+	}
+}
+
+func Test_RevertCommit(t *testing.T) {
+	tool, _ := RevertCommit(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "revert_commit", tool.Name)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "sha", "branch"})
+
+	// The branch has c1 and a later change to b.txt, so reverting c1 restores a.txt and old.txt and removes new.txt
+	repo := cherryPickRepo(map[string]string{"a.txt": "a1", "b.txt": "b9", "new.txt": "n1"})
+	client := github.NewClient(mock.NewMockedHTTPClient(append(repo.mockOptions(t),
+		mock.WithRequestMatch(
+			mock.GetReposGitRefByOwnerByRepoByRef,
+			&github.Reference{Ref: github.Ptr("refs/heads/main"), Object: &github.GitObject{SHA: github.Ptr("h1")}},
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposGitTreesByOwnerByRepo,
+			expectRequestBody(t, map[string]any{
+				"base_tree": "th",
+				"tree": []any{
+					map[string]any{"path": "a.txt", "mode": "100644", "type": "blob", "sha": "a0"},
+					map[string]any{"path": "new.txt", "mode": "100644", "type": "blob", "sha": nil},
+					map[string]any{"path": "old.txt", "mode": "100644", "type": "blob", "sha": "o0"},
+				},
+			}).andThen(
+				mockResponse(t, http.StatusCreated, &github.Tree{SHA: github.Ptr("t2")}),
+			),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PostReposGitCommitsByOwnerByRepo,
+			expectRequestBody(t, map[string]any{
+				"message": "Revert \"Fix greeting\"\n\nThis reverts commit c1.",
+				"tree":    "t2",
+				"parents": []any{"h1"},
+			}).andThen(
+				mockResponse(t, http.StatusCreated, &github.Commit{SHA: github.Ptr("r1")}),
+			),
+		),
+		mock.WithRequestMatch(mock.PatchReposGitRefsByOwnerByRepoByRef, &github.Reference{Ref: github.Ptr("refs/heads/main")}),
+	)...))
+	_, handler := RevertCommit(stubGetClientFn(client), translations.NullTranslationHelper)
+	result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+		"owner":  "owner",
+		"repo":   "repo",
+		"sha":    "c1",
+		"branch": "main",
+	}))
+	require.NoError(t, err)
+
+	var response ReplayResult
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+	assert.Equal(t, "applied", response.Status)
+	assert.Equal(t, "r1", response.HeadSHA)
+	assert.Equal(t, []ReplayedCommit{{SourceSHA: "c1", SHA: "r1"}}, response.Commits)
+}
@@ -0,0 +1,322 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// ConfirmationOff runs every tool immediately.
+	ConfirmationOff = "off"
+	// ConfirmationDestructive requires confirmation for tools annotated as destructive.
+	ConfirmationDestructive = "destructive"
+	// ConfirmationWrites requires confirmation for every tool that is not read-only.
+	ConfirmationWrites = "writes"
+)
+
+const (
+	confirmTokenParam      = "confirm_token"
+	defaultConfirmTokenTTL = 5 * time.Minute
+)
+
+// ConfirmationPolicy adjusts which tools require confirmation beyond those selected by the confirmation mode.
+type ConfirmationPolicy struct {
+	// Tools are tools that always require confirmation.
+	Tools []string `mapstructure:"tools" json:"tools,omitempty"`
+	// ExemptTools are tools that never require confirmation.
+	ExemptTools []string `mapstructure:"exempt_tools" json:"exempt_tools,omitempty"`
+	// TokenTTL is how long a confirm token stays valid. Defaults to 5 minutes.
+	TokenTTL time.Duration `mapstructure:"token_ttl" json:"token_ttl,omitempty"`
+}
+
+// confirmationConditions report whether a call destroys data, for destructive tools that also have harmless
+// uses. Other calls run without confirmation in destructive mode.
+var confirmationConditions = map[string]func(args map[string]any) bool{
+	"label_write": func(args map[string]any) bool {
+		return args["method"] == "delete"
+	},
+	"update_git_ref": func(args map[string]any) bool {
+		return args["force"] == true
+	},
+}
+
+// confirmationSummaries describe the effect of a call to a destructive tool, so that the user knows what they
+// approve. Tools that are not listed are summarized by their arguments.
+var confirmationSummaries = map[string]func(args map[string]any) string{
+	"delete_file": func(args map[string]any) string {
+		return fmt.Sprintf("Delete %v from branch %v of %v/%v, committing with message %q",
+			args["path"], args["branch"], args["owner"], args["repo"], fmt.Sprint(args["message"]))
+	},
+	"delete_release": func(args map[string]any) string {
+		return fmt.Sprintf("Delete release %v of %v/%v and all of its assets", args["release_id"], args["owner"], args["repo"])
+	},
+	"delete_git_ref": func(args map[string]any) string {
+		return fmt.Sprintf("Delete reference %v of %v/%v", args["ref"], args["owner"], args["repo"])
+	},
+	"update_git_ref": func(args map[string]any) string {
+		summary := fmt.Sprintf("Point reference %v of %v/%v to %v", args["ref"], args["owner"], args["repo"], args["sha"])
+		if args["force"] == true {
+			summary = "Force " + strings.ToLower(summary[:1]) + summary[1:] + ", discarding the commits only reachable from its current SHA"
+		}
+		return summary
+	},
+	"merge_pull_request": func(args map[string]any) string {
+		method, _ := args["merge_method"].(string)
+		if method == "" {
+			method = "merge"
+		}
+		return fmt.Sprintf("Merge pull request #%v of %v/%v into its base branch using the %s method",
+			args["pullNumber"], args["owner"], args["repo"], method)
+	},
+	"delete_workflow_run_logs": func(args map[string]any) string {
+		return fmt.Sprintf("Delete all logs of workflow run %v in %v/%v", args["run_id"], args["owner"], args["repo"])
+	},
+	"label_write": func(args map[string]any) string {
+		return fmt.Sprintf("Delete label %q from %v/%v, removing it from every issue and pull request that has it",
+			fmt.Sprint(args["name"]), args["owner"], args["repo"])
+	},
+	"delete_project_item": func(args map[string]any) string {
+		return fmt.Sprintf("Delete item %v from project %v of %v %v",
+			args["item_id"], args["project_number"], args["owner_type"], args["owner"])
+	},
+	"mark_all_notifications_read": func(args map[string]any) string {
+		summary := "Mark all notifications as read"
+		if owner, repo := args["owner"], args["repo"]; owner != nil && repo != nil {
+			summary += fmt.Sprintf(" in %v/%v", owner, repo)
+		}
+		if lastReadAt, ok := args["lastReadAt"].(string); ok && lastReadAt != "" {
+			summary += " up to " + lastReadAt
+		}
+		return summary
+	},
+}
+
+// ConfirmationRequired is the result of a call that needs confirmation before it runs.
+type ConfirmationRequired struct {
+	Status       string    `json:"status"`
+	Tool         string    `json:"tool"`
+	Summary      string    `json:"summary"`
+	ConfirmToken string    `json:"confirm_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Instructions string    `json:"instructions"`
+}
+
+type pendingConfirmation struct {
+	digest    string
+	expiresAt time.Time
+}
+
+// ConfirmationGuard holds the confirm tokens issued for calls awaiting confirmation. A token is bound to the
+// tool and the exact arguments of the call, and can be used once.
+type ConfirmationGuard struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	pending map[string]pendingConfirmation
+}
+
+// NewConfirmationGuard creates a ConfirmationGuard issuing tokens that expire after ttl, or after 5 minutes if
+// ttl is zero.
+func NewConfirmationGuard(ttl time.Duration) *ConfirmationGuard {
+	if ttl <= 0 {
+		ttl = defaultConfirmTokenTTL
+	}
+	return &ConfirmationGuard{ttl: ttl, now: time.Now, pending: make(map[string]pendingConfirmation)}
+}
+
+// IsValidConfirmationMode reports whether mode is one of the supported confirmation modes.
+func IsValidConfirmationMode(mode string) bool {
+	switch mode {
+	case ConfirmationOff, ConfirmationDestructive, ConfirmationWrites:
+		return true
+	}
+	return false
+}
+
+// ApplyConfirmation wraps the tools that require confirmation under mode and policy, and adds a confirm_token
+// parameter to them. It returns the names of the wrapped tools.
+func ApplyConfirmation(tsg *toolsets.ToolsetGroup, mode string, policy ConfirmationPolicy) ([]string, error) {
+	if !IsValidConfirmationMode(mode) {
+		return nil, fmt.Errorf("unknown confirmation mode %q, expected one of: %s, %s, %s", mode, ConfirmationOff, ConfirmationDestructive, ConfirmationWrites)
+	}
+	if mode == ConfirmationOff && len(policy.Tools) == 0 {
+		return nil, nil
+	}
+
+	guard := NewConfirmationGuard(policy.TokenTTL)
+	var wrapped []string
+	tsg.MapTools(func(st server.ServerTool) server.ServerTool {
+		name := st.Tool.Name
+		if !requiresConfirmation(st.Tool, mode, policy) {
+			return st
+		}
+		var condition func(args map[string]any) bool
+		if mode == ConfirmationDestructive && !slices.Contains(policy.Tools, name) {
+			condition = confirmationConditions[name]
+		}
+		st.Handler = guard.Wrap(name, condition, st.Handler)
+		st.Tool = withConfirmTokenParam(st.Tool)
+		wrapped = append(wrapped, name)
+		return st
+	})
+	sort.Strings(wrapped)
+	return slices.Compact(wrapped), nil
+}
+
+func requiresConfirmation(tool mcp.Tool, mode string, policy ConfirmationPolicy) bool {
+	switch {
+	case slices.Contains(policy.ExemptTools, tool.Name):
+		return false
+	case slices.Contains(policy.Tools, tool.Name):
+		return true
+	case mode == ConfirmationDestructive:
+		return tool.Annotations.DestructiveHint != nil && *tool.Annotations.DestructiveHint
+	case mode == ConfirmationWrites:
+		return tool.Annotations.ReadOnlyHint == nil || !*tool.Annotations.ReadOnlyHint
+	default:
+		return false
+	}
+}
+
+func withConfirmTokenParam(tool mcp.Tool) mcp.Tool {
+	// Tools declared with a raw schema accept the token without advertising it
+	if tool.RawInputSchema != nil {
+		return tool
+	}
+	properties := make(map[string]any, len(tool.InputSchema.Properties)+1)
+	for name, property := range tool.InputSchema.Properties {
+		properties[name] = property
+	}
+	properties[confirmTokenParam] = map[string]any{
+		"type":        "string",
+		"description": "Token returned by a previous call with the same arguments, once the user has approved its summary. Omit it to request confirmation.",
+	}
+	tool.InputSchema.Properties = properties
+	return tool
+}
+
+// Wrap returns a handler that runs next only once the call is confirmed with a confirm token, which a first call
+// without one returns alongside a summary for the user to approve. If condition is not nil, only calls for which
+// it returns true need confirmation.
+func (g *ConfirmationGuard) Wrap(toolName string, condition func(args map[string]any) bool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Dry runs change nothing, so there is nothing to confirm
+		if IsDryRun(ctx) {
+			return next(ctx, request)
+		}
+		args := request.GetArguments()
+		if condition != nil && !condition(args) {
+			return next(ctx, request)
+		}
+
+		digest := confirmationDigest(toolName, args)
+		if token, _ := args[confirmTokenParam].(string); token != "" {
+			if err := g.redeem(token, digest); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			delete(args, confirmTokenParam)
+			return next(ctx, request)
+		}
+
+		// The MCP library cannot ask the user directly, so the agent relays the summary and the approval
+		summary := ConfirmationSummary(toolName, args)
+		token, expiresAt, err := g.issue(digest)
+		if err != nil {
+			return nil, err
+		}
+		return MarshalledTextResult(ConfirmationRequired{
+			Status:       "confirmation_required",
+			Tool:         toolName,
+			Summary:      summary,
+			ConfirmToken: token,
+			ExpiresAt:    expiresAt,
+			Instructions: fmt.Sprintf("Nothing has been changed. Show the summary to the user, and only if they approve, call %s again with the same arguments and %s set to this token.", toolName, confirmTokenParam),
+		}), nil
+	}
+}
+
+// ConfirmationSummary describes the effect of a call to the named tool.
+func ConfirmationSummary(toolName string, args map[string]any) string {
+	if summarize, ok := confirmationSummaries[toolName]; ok {
+		return summarize(args)
+	}
+	names := make([]string, 0, len(args))
+	for name := range args {
+		if name != confirmTokenParam {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		value, _ := json.Marshal(args[name])
+		parts = append(parts, fmt.Sprintf("%s=%s", name, value))
+	}
+	return fmt.Sprintf("Call %s with %s", toolName, strings.Join(parts, ", "))
+}
+
+// confirmationDigest identifies a call by its tool and arguments, other than the confirm token.
+func confirmationDigest(toolName string, args map[string]any) string {
+	filtered := make(map[string]any, len(args))
+	for name, value := range args {
+		if name != confirmTokenParam {
+			filtered[name] = value
+		}
+	}
+	// Maps are marshalled with sorted keys, so equal arguments give equal digests
+	data, _ := json.Marshal(filtered)
+	sum := sha256.Sum256(append([]byte(toolName+"\x00"), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+func (g *ConfirmationGuard) issue(digest string) (string, time.Time, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate confirm token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	now := g.now()
+	expiresAt := now.Add(g.ttl)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for t, pending := range g.pending {
+		if now.After(pending.expiresAt) {
+			delete(g.pending, t)
+		}
+	}
+	g.pending[token] = pendingConfirmation{digest: digest, expiresAt: expiresAt}
+	return token, expiresAt, nil
+}
+
+func (g *ConfirmationGuard) redeem(token, digest string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	pending, ok := g.pending[token]
+	if !ok {
+		return fmt.Errorf("unknown or already used %s: call the tool without it to request confirmation", confirmTokenParam)
+	}
+	if g.now().After(pending.expiresAt) {
+		delete(g.pending, token)
+		return fmt.Errorf("%s has expired: call the tool without it to request confirmation again", confirmTokenParam)
+	}
+	if pending.digest != digest {
+		// The token stays valid for the arguments it was issued for
+		return fmt.Errorf("%s was issued for different arguments: the user has not approved this call", confirmTokenParam)
+	}
+	delete(g.pending, token)
+	return nil
+}
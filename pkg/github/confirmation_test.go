@@ -0,0 +1,184 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyConfirmation(t *testing.T) {
+	newGroup := func() *toolsets.ToolsetGroup {
+		tsg := DefaultToolsetGroup(false, stubGetClientFn(nil), stubGetGQLClientFn(nil), stubGetRawClientFn(nil), translations.NullTranslationHelper, 5000, FeatureFlags{}, nil)
+		require.NoError(t, tsg.EnableToolsets([]string{"all"}, nil))
+		return tsg
+	}
+
+	t.Run("destructive wraps tools annotated as destructive", func(t *testing.T) {
+		tsg := newGroup()
+		wrapped, err := ApplyConfirmation(tsg, ConfirmationDestructive, ConfirmationPolicy{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{
+			"delete_file",
+			"delete_git_ref",
+			"delete_project_item",
+			"delete_release",
+			"delete_workflow_run_logs",
+			"label_write",
+			"mark_all_notifications_read",
+			"merge_pull_request",
+			"update_git_ref",
+		}, wrapped)
+
+		for _, tool := range tsg.Toolsets["repos"].GetActiveTools() {
+			_, hasToken := tool.Tool.InputSchema.Properties[confirmTokenParam]
+			assert.Equal(t, tool.Tool.Name == "delete_file" || tool.Tool.Name == "delete_release", hasToken, tool.Tool.Name)
+		}
+	})
+
+	t.Run("policy adds and exempts tools", func(t *testing.T) {
+		wrapped, err := ApplyConfirmation(newGroup(), ConfirmationDestructive, ConfirmationPolicy{
+			Tools:       []string{"create_gist"},
+			ExemptTools: []string{"merge_pull_request"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, wrapped, "create_gist")
+		assert.NotContains(t, wrapped, "merge_pull_request")
+	})
+
+	t.Run("writes wraps every tool that is not read-only", func(t *testing.T) {
+		wrapped, err := ApplyConfirmation(newGroup(), ConfirmationWrites, ConfirmationPolicy{})
+		require.NoError(t, err)
+		assert.Contains(t, wrapped, "create_or_update_file")
+		assert.Contains(t, wrapped, "delete_file")
+		assert.NotContains(t, wrapped, "get_file_contents")
+	})
+
+	t.Run("off leaves tools untouched", func(t *testing.T) {
+		wrapped, err := ApplyConfirmation(newGroup(), ConfirmationOff, ConfirmationPolicy{})
+		require.NoError(t, err)
+		assert.Empty(t, wrapped)
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		_, err := ApplyConfirmation(newGroup(), "always", ConfirmationPolicy{})
+		require.Error(t, err)
+	})
+}
+
+func Test_ConfirmationGuard_TokenHandshake(t *testing.T) {
+	guard := NewConfirmationGuard(time.Minute)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	guard.now = func() time.Time { return now }
+
+	calls := 0
+	handler := guard.Wrap("delete_file", nil, func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		assert.NotContains(t, request.GetArguments(), confirmTokenParam)
+		return mcp.NewToolResultText("deleted"), nil
+	})
+	args := func(extra map[string]any) map[string]any {
+		a := map[string]any{"owner": "owner", "repo": "repo", "path": "docs/a.md", "branch": "main", "message": "Remove a"}
+		for k, v := range extra {
+			a[k] = v
+		}
+		return a
+	}
+	requestConfirmation := func() ConfirmationRequired {
+		result, err := handler(context.Background(), createMCPRequest(args(nil)))
+		require.NoError(t, err)
+		var confirmation ConfirmationRequired
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &confirmation))
+		return confirmation
+	}
+
+	// The first call only describes its effect
+	confirmation := requestConfirmation()
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, "confirmation_required", confirmation.Status)
+	assert.Equal(t, `Delete docs/a.md from branch main of owner/repo, committing with message "Remove a"`, confirmation.Summary)
+	assert.Equal(t, now.Add(time.Minute), confirmation.ExpiresAt)
+
+	// A token does not confirm a call with other arguments
+	result, err := handler(context.Background(), createMCPRequest(args(map[string]any{"path": "README.md", confirmTokenParam: confirmation.ConfirmToken})))
+	require.NoError(t, err)
+	assert.Contains(t, getErrorResult(t, result).Text, "issued for different arguments")
+	assert.Equal(t, 0, calls)
+
+	// The token confirms the same call once
+	result, err = handler(context.Background(), createMCPRequest(args(map[string]any{confirmTokenParam: confirmation.ConfirmToken})))
+	require.NoError(t, err)
+	assert.Equal(t, "deleted", getTextResult(t, result).Text)
+	assert.Equal(t, 1, calls)
+
+	result, err = handler(context.Background(), createMCPRequest(args(map[string]any{confirmTokenParam: confirmation.ConfirmToken})))
+	require.NoError(t, err)
+	assert.Contains(t, getErrorResult(t, result).Text, "unknown or already used")
+	assert.Equal(t, 1, calls)
+
+	// Tokens expire
+	confirmation = requestConfirmation()
+	now = now.Add(2 * time.Minute)
+	result, err = handler(context.Background(), createMCPRequest(args(map[string]any{confirmTokenParam: confirmation.ConfirmToken})))
+	require.NoError(t, err)
+	assert.Contains(t, getErrorResult(t, result).Text, "has expired")
+	assert.Equal(t, 1, calls)
+}
+
+func Test_ConfirmationGuard_Condition(t *testing.T) {
+	guard := NewConfirmationGuard(0)
+	handler := guard.Wrap("label_write", confirmationConditions["label_write"], func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	result, err := handler(context.Background(), createMCPRequest(map[string]any{"method": "create", "owner": "owner", "repo": "repo", "name": "bug"}))
+	require.NoError(t, err)
+	assert.Equal(t, "done", getTextResult(t, result).Text)
+
+	result, err = handler(context.Background(), createMCPRequest(map[string]any{"method": "delete", "owner": "owner", "repo": "repo", "name": "bug"}))
+	require.NoError(t, err)
+	assert.Contains(t, getTextResult(t, result).Text, `Delete label \"bug\" from owner/repo`)
+}
+
+func Test_ConfirmationGuard_ForcedRefUpdate(t *testing.T) {
+	handler := NewConfirmationGuard(0).Wrap("update_git_ref", confirmationConditions["update_git_ref"], func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("updated"), nil
+	})
+	args := map[string]any{"owner": "owner", "repo": "repo", "ref": "heads/main", "sha": "abc123"}
+
+	result, err := handler(context.Background(), createMCPRequest(args))
+	require.NoError(t, err)
+	assert.Equal(t, "updated", getTextResult(t, result).Text)
+
+	args["force"] = true
+	result, err = handler(context.Background(), createMCPRequest(args))
+	require.NoError(t, err)
+	var confirmation ConfirmationRequired
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &confirmation))
+	assert.Equal(t, "Force point reference heads/main of owner/repo to abc123, discarding the commits only reachable from its current SHA", confirmation.Summary)
+}
+
+func TestConfirmationSummary(t *testing.T) {
+	assert.Equal(t, "Mark all notifications as read in owner/repo up to 2025-01-01T00:00:00Z",
+		ConfirmationSummary("mark_all_notifications_read", map[string]any{"owner": "owner", "repo": "repo", "lastReadAt": "2025-01-01T00:00:00Z"}))
+	assert.Equal(t, `Call create_gist with content="x", public=true`,
+		ConfirmationSummary("create_gist", map[string]any{"public": true, "content": "x", confirmTokenParam: "abc"}))
+}
+
+func Test_ConfirmationGuard_DryRun(t *testing.T) {
+	handler := NewConfirmationGuard(0).Wrap("delete_file", nil, func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("planned"), nil
+	})
+	ctx := context.WithValue(context.Background(), dryRunRecorderKey{}, &dryRunRecorder{})
+
+	// Dry runs change nothing, so they run without confirmation
+	result, err := handler(ctx, createMCPRequest(map[string]any{"owner": "owner", "repo": "repo", "path": "a"}))
+	require.NoError(t, err)
+	assert.Equal(t, "planned", getTextResult(t, result).Text)
+}
@@ -0,0 +1,240 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/github/github-mcp-server/pkg/sanitize"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sanitizedResultFields are the paths of the user-authored fields in the results of tools returning issues,
+// pull requests, comments, reviews, discussions, commits, blame, tags, releases, labels, gists, notifications,
+// project items and workflow runs. A path is a list of object keys separated by dots, where [] stands for every
+// item of a list and * for every value of an object. Paths that do not match a result, such as those of another
+// method of the same tool, are ignored. Results of other tools are not sanitized.
+var sanitizedResultFields = map[string][]string{
+	"issue_read":               {"title", "body", "labels[].description", "[].title", "[].body"},
+	"list_issues":              {"issues[].title", "issues[].body"},
+	"search_issues":            {"items[].title", "items[].body"},
+	"pull_request_read":        {"title", "body", "[].body"},
+	"list_pull_requests":       {"[].title", "[].body"},
+	"search_pull_requests":     {"items[].title", "items[].body"},
+	"get_commit":               {"commit.message"},
+	"list_commits":             {"[].commit.message"},
+	"compare_refs":             {"commits[].commit.message"},
+	"get_file_history":         {"commits[].commit.message"},
+	"get_file_blame":           {"hunks[].commit.message", "hunks[].commit.pull_request.title"},
+	"get_git_commit":           {"message"},
+	"get_git_tag":              {"message"},
+	"get_tag":                  {"message"},
+	"list_releases":            {"[].name", "[].body"},
+	"get_latest_release":       {"name", "body"},
+	"get_release_by_tag":       {"name", "body"},
+	"generate_release_notes":   {"name", "body"},
+	"get_label":                {"description"},
+	"list_label":               {"labels[].description"},
+	"get_gist":                 {"description"},
+	"list_gists":               {"[].description"},
+	"list_discussions":         {"discussions[].title", "discussions[].body"},
+	"get_discussion":           {"title", "body"},
+	"get_discussion_comments":  {"comments[].body"},
+	"list_notifications":       {"[].subject.title"},
+	"get_notification_details": {"subject.title"},
+	"list_project_items":       {"items[].fields[].value"},
+	"get_project_item":         {"fields[].value"},
+	"list_workflow_runs":       {"workflow_runs[].display_title", "workflow_runs[].head_commit.message"},
+	"get_workflow_run":         {"display_title", "head_commit.message"},
+}
+
+// sanitizedCodeFields are the paths of fields holding code, which only lose invisible characters, as removing
+// comments from them would change the code.
+var sanitizedCodeFields = map[string][]string{
+	"get_gist": {"files.*.content"},
+}
+
+// ContentSanitizer post-processes tool results, running the user-authored fields listed in sanitizedResultFields
+// through sanitize.Sanitize after removing markdown comments, which leaves only the text and HTML a reader of the
+// rendered field would see. With a URL filter, links and images in the fields may only point
+// to allowed hosts. With a scanner, the fields are also checked for prompt injection, unless they belong to an
+// item that lockdown mode has marked as written by a trusted author.
+type ContentSanitizer struct {
+	hidden  bool
+	urls    *sanitize.URLFilter
+	scanner *sanitize.Scanner
+}
+
+// NewContentSanitizer creates a ContentSanitizer that removes hidden content. urls and scanner may be nil to
+// leave links and images alone and to not scan for prompt injection.
+func NewContentSanitizer(urls *sanitize.URLFilter, scanner *sanitize.Scanner) *ContentSanitizer {
+	return &ContentSanitizer{hidden: true, urls: urls, scanner: scanner}
+}
+
+// NewURLSanitizer creates a ContentSanitizer that only applies urls to the user-authored fields, for servers
+// that restrict links and images without removing hidden content.
+func NewURLSanitizer(urls *sanitize.URLFilter) *ContentSanitizer {
+	return &ContentSanitizer{urls: urls}
+}
+
+// ToolHandlerMiddleware sanitizes the text results of a tool that are JSON documents. Other results, such as
+// file contents returned as resources, and results of tools without user-authored fields are passed through
+// unchanged.
+func (s *ContentSanitizer) ToolHandlerMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+		toolName := request.Params.Name
+		for i, content := range result.Content {
+			switch text := content.(type) {
+			case mcp.TextContent:
+				text.Text = s.SanitizeJSON(toolName, text.Text)
+				result.Content[i] = text
+			case *mcp.TextContent:
+				text.Text = s.SanitizeJSON(toolName, text.Text)
+			}
+		}
+		return result, nil
+	}
+}
+
+// SanitizeJSON sanitizes the user-authored fields of a JSON document returned by the named tool. Text that is
+// not a JSON document is returned unchanged.
+func (s *ContentSanitizer) SanitizeJSON(toolName, text string) string {
+	fields, codeFields := sanitizedResultFields[toolName], sanitizedCodeFields[toolName]
+	if len(fields) == 0 && len(codeFields) == 0 {
+		return text
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(text)))
+	// Decode numbers as json.Number so that large IDs keep their precision
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return text
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return text
+	}
+	// Dry-run plans show the exact requests a tool would send, which hold what the agent wrote
+	if plan, ok := document.(map[string]any); ok && plan["dry_run"] == true {
+		return text
+	}
+
+	for _, path := range fields {
+		document = s.sanitizePath(document, parseResultPath(path), false, false)
+	}
+	for _, path := range codeFields {
+		document = s.sanitizePath(document, parseResultPath(path), false, true)
+	}
+	out, err := json.Marshal(document)
+	if err != nil {
+		return text
+	}
+	return string(out)
+}
+
+// parseResultPath splits a path of sanitizedResultFields into object keys, [] and *.
+func parseResultPath(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, ".") {
+		key, list := strings.CutSuffix(segment, "[]")
+		if key != "" {
+			segments = append(segments, key)
+		}
+		if list {
+			segments = append(segments, "[]")
+		}
+	}
+	return segments
+}
+
+// sanitizePath sanitizes the strings found at path within value.
+func (s *ContentSanitizer) sanitizePath(value any, path []string, trusted, code bool) any {
+	if len(path) == 0 {
+		text, ok := value.(string)
+		switch {
+		case !ok:
+			return value
+		case code && s.hidden:
+			return sanitize.FilterInvisibleCharacters(text)
+		case code:
+			return text
+		default:
+			return s.sanitizeText(text, trusted)
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		// Items tagged by lockdown mode carry the trust in their author
+		if level, ok := value["trust_level"].(string); ok {
+			trusted = level != string(lockdown.TrustLevelExternal)
+		}
+		switch key := path[0]; {
+		case key == "*":
+			for k, field := range value {
+				value[k] = s.sanitizePath(field, path[1:], trusted, code)
+			}
+		case key == "value" && len(path) == 1:
+			// Project items list all their fields, of which only text and title fields hold user text
+			if field, ok := value[key]; ok && isProjectTextField(value) {
+				value[key] = s.sanitizeProjectFieldValue(field, trusted)
+			}
+		default:
+			if field, ok := value[key]; ok {
+				value[key] = s.sanitizePath(field, path[1:], trusted, code)
+			}
+		}
+		return value
+	case []any:
+		if path[0] == "[]" {
+			for i, item := range value {
+				value[i] = s.sanitizePath(item, path[1:], trusted, code)
+			}
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+func (s *ContentSanitizer) sanitizeText(text string, trusted bool) string {
+	if s.hidden {
+		text = sanitize.Sanitize(sanitize.FilterMarkdownComments(text))
+	}
+	if s.urls != nil {
+		text = s.urls.Filter(text)
+	}
+	if s.scanner != nil && !trusted {
+		text, _ = s.scanner.Apply(text)
+	}
+	return text
+}
+
+// sanitizeProjectFieldValue sanitizes the value of a text or title field of a project item. Text fields hold a
+// string, while title fields hold an object with the text of the item's title.
+func (s *ContentSanitizer) sanitizeProjectFieldValue(value any, trusted bool) any {
+	switch value := value.(type) {
+	case string:
+		return s.sanitizeText(value, trusted)
+	case map[string]any:
+		if text, ok := value["text"].(string); ok {
+			value["text"] = s.sanitizeText(text, trusted)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+func isProjectTextField(object map[string]any) bool {
+	dataType, _ := object["data_type"].(string)
+	return dataType == "text" || dataType == "title"
+}
@@ -0,0 +1,353 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// CustomToolDefinition describes a tool that is declared in configuration rather than in code.
+// Exactly one of GraphQL or REST must be set.
+type CustomToolDefinition struct {
+	Name        string                `mapstructure:"name"`
+	Title       string                `mapstructure:"title"`
+	Description string                `mapstructure:"description"`
+	Parameters  []CustomToolParameter `mapstructure:"parameters"`
+	// GraphQL is a GraphQL document. Every parameter is passed as a variable of the same name.
+	GraphQL string `mapstructure:"graphql"`
+	// REST describes a REST request whose path may reference parameters as {name}.
+	REST *CustomToolREST `mapstructure:"rest"`
+	// Mutating must be set for tools that modify data. Such tools are never offered in read-only mode.
+	Mutating bool `mapstructure:"mutating"`
+}
+
+// CustomToolREST describes the REST request issued by a custom tool.
+type CustomToolREST struct {
+	Method string `mapstructure:"method"`
+	Path   string `mapstructure:"path"`
+}
+
+// CustomToolParameter describes a single typed parameter of a custom tool.
+type CustomToolParameter struct {
+	Name        string   `mapstructure:"name"`
+	Type        string   `mapstructure:"type"`
+	Description string   `mapstructure:"description"`
+	Required    bool     `mapstructure:"required"`
+	Enum        []string `mapstructure:"enum"`
+}
+
+const (
+	customParamTypeString  = "string"
+	customParamTypeNumber  = "number"
+	customParamTypeInteger = "integer"
+	customParamTypeBoolean = "boolean"
+)
+
+var (
+	customToolNamePattern     = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	customPathParamPattern    = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+	customReadOnlyRESTMethods = map[string]bool{http.MethodGet: true, http.MethodHead: true}
+)
+
+// Validate checks that the definition is complete and internally consistent.
+func (d CustomToolDefinition) Validate() error {
+	if !customToolNamePattern.MatchString(d.Name) {
+		return fmt.Errorf("custom tool name %q must be lowercase snake_case", d.Name)
+	}
+	if strings.TrimSpace(d.Description) == "" {
+		return fmt.Errorf("custom tool %s: description is required", d.Name)
+	}
+
+	declared := make(map[string]CustomToolParameter, len(d.Parameters))
+	for _, p := range d.Parameters {
+		if p.Name == "" {
+			return fmt.Errorf("custom tool %s: parameter name is required", d.Name)
+		}
+		if _, dup := declared[p.Name]; dup {
+			return fmt.Errorf("custom tool %s: duplicate parameter %s", d.Name, p.Name)
+		}
+		switch p.Type {
+		case customParamTypeString, customParamTypeNumber, customParamTypeInteger, customParamTypeBoolean:
+		default:
+			return fmt.Errorf("custom tool %s: parameter %s has unsupported type %q", d.Name, p.Name, p.Type)
+		}
+		if len(p.Enum) > 0 && p.Type != customParamTypeString {
+			return fmt.Errorf("custom tool %s: enum is only supported for string parameter %s", d.Name, p.Name)
+		}
+		declared[p.Name] = p
+	}
+
+	hasGraphQL := strings.TrimSpace(d.GraphQL) != ""
+	hasREST := d.REST != nil && d.REST.Path != ""
+	if hasGraphQL == hasREST {
+		return fmt.Errorf("custom tool %s: exactly one of graphql or rest must be set", d.Name)
+	}
+
+	if hasGraphQL {
+		// Documents that cannot be parsed may hide a mutation
+		if !d.Mutating && containsGraphQLMutation(d.GraphQL) {
+			return fmt.Errorf("custom tool %s: graphql mutations must be marked as mutating", d.Name)
+		}
+		return nil
+	}
+
+	if !customReadOnlyRESTMethods[d.restMethod()] && !d.Mutating {
+		return fmt.Errorf("custom tool %s: %s requests must be marked as mutating", d.Name, d.restMethod())
+	}
+	for _, m := range customPathParamPattern.FindAllStringSubmatch(d.REST.Path, -1) {
+		p, ok := declared[m[1]]
+		if !ok {
+			return fmt.Errorf("custom tool %s: path references undeclared parameter %s", d.Name, m[1])
+		}
+		if !p.Required {
+			return fmt.Errorf("custom tool %s: path parameter %s must be required", d.Name, m[1])
+		}
+	}
+	return nil
+}
+
+func (d CustomToolDefinition) restMethod() string {
+	if d.REST == nil || d.REST.Method == "" {
+		return http.MethodGet
+	}
+	return strings.ToUpper(d.REST.Method)
+}
+
+// CustomTool creates a tool from a configuration-declared definition. The definition must be valid.
+func CustomTool(def CustomToolDefinition, getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	keyPrefix := "TOOL_" + strings.ToUpper(def.Name)
+	title := def.Title
+	if title == "" {
+		title = def.Name
+	}
+
+	opts := []mcp.ToolOption{
+		mcp.WithDescription(t(keyPrefix+"_DESCRIPTION", def.Description)),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        t(keyPrefix+"_USER_TITLE", title),
+			ReadOnlyHint: ToBoolPtr(!def.Mutating),
+		}),
+	}
+	for _, p := range def.Parameters {
+		propOpts := []mcp.PropertyOption{mcp.Description(p.Description)}
+		if p.Required {
+			propOpts = append(propOpts, mcp.Required())
+		}
+		switch p.Type {
+		case customParamTypeString:
+			if len(p.Enum) > 0 {
+				propOpts = append(propOpts, mcp.Enum(p.Enum...))
+			}
+			opts = append(opts, mcp.WithString(p.Name, propOpts...))
+		case customParamTypeNumber, customParamTypeInteger:
+			opts = append(opts, mcp.WithNumber(p.Name, propOpts...))
+		case customParamTypeBoolean:
+			opts = append(opts, mcp.WithBoolean(p.Name, propOpts...))
+		}
+	}
+
+	return mcp.NewTool(def.Name, opts...),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args, err := validateCustomToolArguments(def.Parameters, request.GetArguments())
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			if def.GraphQL != "" {
+				return runCustomGraphQLTool(ctx, client, def, args)
+			}
+			return runCustomRESTTool(ctx, client, def, args)
+		}
+}
+
+// validateCustomToolArguments checks the arguments against the declared parameters and
+// returns only the arguments that were declared.
+func validateCustomToolArguments(params []CustomToolParameter, args map[string]any) (map[string]any, error) {
+	declared := make(map[string]bool, len(params))
+	result := make(map[string]any, len(args))
+	for _, p := range params {
+		declared[p.Name] = true
+		v, ok := args[p.Name]
+		if !ok || v == nil {
+			if p.Required {
+				return nil, fmt.Errorf("missing required parameter: %s", p.Name)
+			}
+			continue
+		}
+		switch p.Type {
+		case customParamTypeString:
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("parameter %s is not of type string, is %T", p.Name, v)
+			}
+			if len(p.Enum) > 0 && !ContainsToolset(p.Enum, s) {
+				return nil, fmt.Errorf("parameter %s must be one of: %s", p.Name, strings.Join(p.Enum, ", "))
+			}
+		case customParamTypeNumber:
+			if _, ok := v.(float64); !ok {
+				return nil, fmt.Errorf("parameter %s is not of type number, is %T", p.Name, v)
+			}
+		case customParamTypeInteger:
+			f, ok := v.(float64)
+			if !ok || f != math.Trunc(f) {
+				return nil, fmt.Errorf("parameter %s is not of type integer", p.Name)
+			}
+		case customParamTypeBoolean:
+			if _, ok := v.(bool); !ok {
+				return nil, fmt.Errorf("parameter %s is not of type boolean, is %T", p.Name, v)
+			}
+		}
+		result[p.Name] = v
+	}
+
+	var unknown []string
+	for name := range args {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown parameters: %s", strings.Join(unknown, ", "))
+	}
+	return result, nil
+}
+
+func runCustomGraphQLTool(ctx context.Context, client *github.Client, def CustomToolDefinition, args map[string]any) (*mcp.CallToolResult, error) {
+	body := map[string]any{
+		"query":     def.GraphQL,
+		"variables": args,
+	}
+	req, err := client.NewRequest(http.MethodPost, graphQLEndpoint(client), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GraphQL request: %w", err)
+	}
+
+	var gqlResp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	resp, err := client.Do(ctx, req, &gqlResp)
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(ctx, fmt.Sprintf("failed to run custom tool %s", def.Name), resp, err), nil
+	}
+	if len(gqlResp.Errors) > 0 {
+		messages := make([]string, len(gqlResp.Errors))
+		for i, e := range gqlResp.Errors {
+			messages[i] = e.Message
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("custom tool %s returned GraphQL errors: %s", def.Name, strings.Join(messages, "; "))), nil
+	}
+
+	return mcp.NewToolResultText(string(gqlResp.Data)), nil
+}
+
+func runCustomRESTTool(ctx context.Context, client *github.Client, def CustomToolDefinition, args map[string]any) (*mcp.CallToolResult, error) {
+	method := def.restMethod()
+	remaining := make(map[string]any, len(args))
+	for k, v := range args {
+		remaining[k] = v
+	}
+
+	path := customPathParamPattern.ReplaceAllStringFunc(def.REST.Path, func(m string) string {
+		name := m[1 : len(m)-1]
+		v := remaining[name]
+		delete(remaining, name)
+		return url.PathEscape(formatCustomToolValue(v))
+	})
+	path = strings.TrimPrefix(path, "/")
+
+	var body any
+	if method == http.MethodGet || method == http.MethodHead || method == http.MethodDelete {
+		if len(remaining) > 0 {
+			query := url.Values{}
+			for k, v := range remaining {
+				query.Set(k, formatCustomToolValue(v))
+			}
+			path += "?" + query.Encode()
+		}
+	} else if len(remaining) > 0 {
+		body = remaining
+	}
+
+	req, err := client.NewRequest(method, path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	var result json.RawMessage
+	resp, err := client.Do(ctx, req, &result)
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(ctx, fmt.Sprintf("failed to run custom tool %s", def.Name), resp, err), nil
+	}
+	if len(result) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("custom tool %s completed with status %d", def.Name, resp.StatusCode)), nil
+	}
+
+	return mcp.NewToolResultText(string(result)), nil
+}
+
+func formatCustomToolValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// graphQLEndpoint resolves the GraphQL endpoint relative to the REST client's base URL.
+// GitHub Enterprise Server serves REST from /api/v3/ and GraphQL from /api/graphql.
+func graphQLEndpoint(client *github.Client) string {
+	if strings.HasSuffix(client.BaseURL.Path, "/api/v3/") {
+		return "../graphql"
+	}
+	return "graphql"
+}
+
+// CustomToolset creates the custom toolset from configuration-declared definitions.
+func CustomToolset(defs []CustomToolDefinition, getClient GetClientFn, t translations.TranslationHelperFunc) (*toolsets.Toolset, error) {
+	custom := toolsets.NewToolset(ToolsetMetadataCustom.ID, ToolsetMetadataCustom.Description)
+	seen := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		if err := def.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[def.Name] {
+			return nil, fmt.Errorf("duplicate custom tool %s", def.Name)
+		}
+		seen[def.Name] = true
+
+		if def.Mutating {
+			custom.AddWriteTools(toolsets.NewServerTool(CustomTool(def, getClient, t)))
+		} else {
+			custom.AddReadTools(toolsets.NewServerTool(CustomTool(def, getClient, t)))
+		}
+	}
+	return custom, nil
+}
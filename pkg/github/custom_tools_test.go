@@ -0,0 +1,326 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CustomToolDefinition_Validate(t *testing.T) {
+	ownerRepoParams := []CustomToolParameter{
+		{Name: "owner", Type: "string", Required: true},
+		{Name: "repo", Type: "string", Required: true},
+	}
+
+	tests := []struct {
+		name           string
+		def            CustomToolDefinition
+		expectedErrMsg string
+	}{
+		{
+			name: "valid REST tool",
+			def: CustomToolDefinition{
+				Name:        "list_repo_variables",
+				Description: "List Actions variables",
+				Parameters:  ownerRepoParams,
+				REST:        &CustomToolREST{Path: "/repos/{owner}/{repo}/actions/variables"},
+			},
+		},
+		{
+			name: "valid GraphQL tool",
+			def: CustomToolDefinition{
+				Name:        "repo_stars",
+				Description: "Count stars",
+				Parameters:  ownerRepoParams,
+				GraphQL:     "query($owner: String!, $repo: String!) { repository(owner: $owner, name: $repo) { stargazerCount } }",
+			},
+		},
+		{
+			name: "invalid name",
+			def: CustomToolDefinition{
+				Name:        "Bad-Name",
+				Description: "x",
+				REST:        &CustomToolREST{Path: "/user"},
+			},
+			expectedErrMsg: "must be lowercase snake_case",
+		},
+		{
+			name: "missing description",
+			def: CustomToolDefinition{
+				Name: "no_description",
+				REST: &CustomToolREST{Path: "/user"},
+			},
+			expectedErrMsg: "description is required",
+		},
+		{
+			name: "both graphql and rest",
+			def: CustomToolDefinition{
+				Name:        "both",
+				Description: "x",
+				GraphQL:     "query { viewer { login } }",
+				REST:        &CustomToolREST{Path: "/user"},
+			},
+			expectedErrMsg: "exactly one of graphql or rest must be set",
+		},
+		{
+			name: "unsupported parameter type",
+			def: CustomToolDefinition{
+				Name:        "bad_param",
+				Description: "x",
+				Parameters:  []CustomToolParameter{{Name: "ids", Type: "array"}},
+				REST:        &CustomToolREST{Path: "/user"},
+			},
+			expectedErrMsg: `unsupported type "array"`,
+		},
+		{
+			name: "undeclared path parameter",
+			def: CustomToolDefinition{
+				Name:        "undeclared",
+				Description: "x",
+				REST:        &CustomToolREST{Path: "/orgs/{org}/teams"},
+			},
+			expectedErrMsg: "undeclared parameter org",
+		},
+		{
+			name: "optional path parameter",
+			def: CustomToolDefinition{
+				Name:        "optional_path",
+				Description: "x",
+				Parameters:  []CustomToolParameter{{Name: "org", Type: "string"}},
+				REST:        &CustomToolREST{Path: "/orgs/{org}/teams"},
+			},
+			expectedErrMsg: "path parameter org must be required",
+		},
+		{
+			name: "unmarked REST write",
+			def: CustomToolDefinition{
+				Name:        "delete_variable",
+				Description: "x",
+				REST:        &CustomToolREST{Method: "delete", Path: "/user"},
+			},
+			expectedErrMsg: "DELETE requests must be marked as mutating",
+		},
+		{
+			name: "unmarked GraphQL mutation",
+			def: CustomToolDefinition{
+				Name:        "add_star",
+				Description: "x",
+				GraphQL:     "mutation($id: ID!) { addStar(input: {starrableId: $id}) { clientMutationId } }",
+			},
+			expectedErrMsg: "graphql mutations must be marked as mutating",
+		},
+		{
+			name: "unmarked GraphQL mutation after a fragment",
+			def: CustomToolDefinition{
+				Name:        "star_repo",
+				Description: "Star a repository",
+				GraphQL:     "fragment F on Repository { id } mutation($id: ID!) { addStar(input: {starrableId: $id}) { clientMutationId } }",
+			},
+			expectedErrMsg: "graphql mutations must be marked as mutating",
+		},
+		{
+			name: "marked GraphQL mutation",
+			def: CustomToolDefinition{
+				Name:        "add_star",
+				Description: "x",
+				GraphQL:     "mutation($id: ID!) { addStar(input: {starrableId: $id}) { clientMutationId } }",
+				Mutating:    true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.def.Validate()
+			if tc.expectedErrMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErrMsg)
+		})
+	}
+}
+
+func Test_CustomTool(t *testing.T) {
+	restDef := CustomToolDefinition{
+		Name:        "list_repo_variables",
+		Title:       "List repository variables",
+		Description: "List Actions variables for a repository",
+		Parameters: []CustomToolParameter{
+			{Name: "owner", Type: "string", Required: true, Description: "Repository owner"},
+			{Name: "repo", Type: "string", Required: true, Description: "Repository name"},
+			{Name: "per_page", Type: "integer", Description: "Results per page"},
+		},
+		REST: &CustomToolREST{Path: "/repos/{owner}/{repo}/actions/variables"},
+	}
+
+	tool, _ := CustomTool(restDef, stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	assert.Equal(t, "list_repo_variables", tool.Name)
+	assert.Equal(t, "List repository variables", tool.Annotations.Title)
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+	assert.Contains(t, tool.InputSchema.Properties, "per_page")
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo"})
+
+	graphQLDef := CustomToolDefinition{
+		Name:        "repo_stars",
+		Description: "Count stars",
+		Parameters: []CustomToolParameter{
+			{Name: "owner", Type: "string", Required: true},
+			{Name: "repo", Type: "string", Required: true},
+		},
+		GraphQL: "query($owner: String!, $repo: String!) { repository(owner: $owner, name: $repo) { stargazerCount } }",
+	}
+
+	tests := []struct {
+		name           string
+		def            CustomToolDefinition
+		mockedClient   *http.Client
+		requestArgs    map[string]interface{}
+		expectError    bool
+		expectedText   string
+		expectedErrMsg string
+	}{
+		{
+			name: "REST tool expands path and passes query parameters",
+			def:  restDef,
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.EndpointPattern{Pattern: "/repos/{owner}/{repo}/actions/variables", Method: http.MethodGet},
+					expect(t, expectations{
+						path:        "/repos/octo/hello-world/actions/variables",
+						queryParams: map[string]string{"per_page": "5"},
+					}).andThen(
+						mockResponse(t, http.StatusOK, map[string]any{"total_count": 0}),
+					),
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":    "octo",
+				"repo":     "hello-world",
+				"per_page": float64(5),
+			},
+			expectedText: `{"total_count":0}`,
+		},
+		{
+			name: "GraphQL tool passes parameters as variables",
+			def:  graphQLDef,
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.EndpointPattern{Pattern: "/graphql", Method: http.MethodPost},
+					expectRequestBody(t, map[string]any{
+						"query":     graphQLDef.GraphQL,
+						"variables": map[string]any{"owner": "octo", "repo": "hello"},
+					}).andThen(
+						mockResponse(t, http.StatusOK, map[string]any{
+							"data": map[string]any{"repository": map[string]any{"stargazerCount": 42}},
+						}),
+					),
+				),
+			),
+			requestArgs:  map[string]interface{}{"owner": "octo", "repo": "hello"},
+			expectedText: `{"repository":{"stargazerCount":42}}`,
+		},
+		{
+			name: "GraphQL errors are surfaced",
+			def:  graphQLDef,
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.EndpointPattern{Pattern: "/graphql", Method: http.MethodPost},
+					mockResponse(t, http.StatusOK, map[string]any{
+						"errors": []map[string]any{{"message": "Could not resolve to a Repository"}},
+					}),
+				),
+			),
+			requestArgs:    map[string]interface{}{"owner": "octo", "repo": "missing"},
+			expectError:    true,
+			expectedErrMsg: "Could not resolve to a Repository",
+		},
+		{
+			name:           "missing required parameter",
+			def:            restDef,
+			mockedClient:   mock.NewMockedHTTPClient(),
+			requestArgs:    map[string]interface{}{"owner": "octo"},
+			expectError:    true,
+			expectedErrMsg: "missing required parameter: repo",
+		},
+		{
+			name:           "non-integer value for integer parameter",
+			def:            restDef,
+			mockedClient:   mock.NewMockedHTTPClient(),
+			requestArgs:    map[string]interface{}{"owner": "octo", "repo": "hello", "per_page": 1.5},
+			expectError:    true,
+			expectedErrMsg: "parameter per_page is not of type integer",
+		},
+		{
+			name:           "unknown parameter",
+			def:            restDef,
+			mockedClient:   mock.NewMockedHTTPClient(),
+			requestArgs:    map[string]interface{}{"owner": "octo", "repo": "hello", "page": float64(2)},
+			expectError:    true,
+			expectedErrMsg: "unknown parameters: page",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := github.NewClient(tc.mockedClient)
+			_, handler := CustomTool(tc.def, stubGetClientFn(client), translations.NullTranslationHelper)
+
+			result, err := handler(context.Background(), createMCPRequest(tc.requestArgs))
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			require.False(t, result.IsError)
+			textContent := getTextResult(t, result)
+			assert.JSONEq(t, tc.expectedText, textContent.Text)
+		})
+	}
+}
+
+func Test_CustomToolset(t *testing.T) {
+	defs := []CustomToolDefinition{
+		{
+			Name:        "get_viewer",
+			Description: "Get the viewer",
+			GraphQL:     "query { viewer { login } }",
+		},
+		{
+			Name:        "set_repo_variable",
+			Description: "Create a repository variable",
+			Parameters: []CustomToolParameter{
+				{Name: "owner", Type: "string", Required: true},
+				{Name: "repo", Type: "string", Required: true},
+				{Name: "name", Type: "string", Required: true},
+				{Name: "value", Type: "string", Required: true},
+			},
+			REST:     &CustomToolREST{Method: "POST", Path: "/repos/{owner}/{repo}/actions/variables"},
+			Mutating: true,
+		},
+	}
+
+	custom, err := CustomToolset(defs, stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, err)
+	assert.Equal(t, ToolsetMetadataCustom.ID, custom.Name)
+	assert.Len(t, custom.GetAvailableTools(), 2)
+
+	custom.SetReadOnly()
+	available := custom.GetAvailableTools()
+	require.Len(t, available, 1)
+	assert.Equal(t, "get_viewer", available[0].Tool.Name)
+
+	_, err = CustomToolset(append(defs, defs[0]), stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate custom tool get_viewer")
+}
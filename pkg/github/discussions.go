@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/github/github-mcp-server/pkg/lockdown"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/google/go-github/v79/github"
@@ -120,7 +121,7 @@ func getQueryType(useOrdering bool, categoryID *githubv4.ID) any {
 	return &BasicNoOrder{}
 }
 
-func ListDiscussions(getGQLClient GetGQLClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func ListDiscussions(getGQLClient GetGQLClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("list_discussions",
 			mcp.WithDescription(t("TOOL_LIST_DISCUSSIONS_DESCRIPTION", "List discussions for a repository or organisation.")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -239,9 +240,16 @@ func ListDiscussions(getGQLClient GetGQLClientFn, t translations.TranslationHelp
 				totalCount = fragment.TotalCount
 			}
 
+			output, err := applyLockdown(ctx, cache, flags, discussions, func(discussion *github.Discussion) contentOrigin {
+				return contentOrigin{Login: discussion.GetUser().GetLogin(), Owner: owner, Repo: repo}
+			}, redactDiscussion)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+			}
+
 			// Create response with pagination info
 			response := map[string]interface{}{
-				"discussions": discussions,
+				"discussions": output,
 				"pageInfo": map[string]interface{}{
 					"hasNextPage":     pageInfo.HasNextPage,
 					"hasPreviousPage": pageInfo.HasPreviousPage,
@@ -259,7 +267,7 @@ func ListDiscussions(getGQLClient GetGQLClientFn, t translations.TranslationHelp
 		}
 }
 
-func GetDiscussion(getGQLClient GetGQLClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func GetDiscussion(getGQLClient GetGQLClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_discussion",
 			mcp.WithDescription(t("TOOL_GET_DISCUSSION_DESCRIPTION", "Get a specific discussion by ID")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -308,6 +316,9 @@ func GetDiscussion(getGQLClient GetGQLClientFn, t translations.TranslationHelper
 						Category       struct {
 							Name githubv4.String
 						} `graphql:"category"`
+						Author struct {
+							Login githubv4.String
+						}
 					} `graphql:"discussion(number: $discussionNumber)"`
 				} `graphql:"repository(owner: $owner, name: $repo)"`
 			}
@@ -343,7 +354,19 @@ func GetDiscussion(getGQLClient GetGQLClientFn, t translations.TranslationHelper
 				response["answerChosenAt"] = d.AnswerChosenAt.Time
 			}
 
-			out, err := json.Marshal(response)
+			output, err := applyLockdownItem(ctx, cache, flags, response, contentOrigin{Login: string(d.Author.Login), Owner: params.Owner, Repo: params.Repo},
+				func(discussion map[string]interface{}, placeholder string) {
+					discussion["title"] = placeholder
+					discussion["body"] = placeholder
+				})
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+			}
+			if output == nil {
+				return mcp.NewToolResultError("access to discussion is restricted by lockdown mode"), nil
+			}
+
+			out, err := json.Marshal(output)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal discussion: %w", err)
 			}
@@ -352,7 +375,7 @@ func GetDiscussion(getGQLClient GetGQLClientFn, t translations.TranslationHelper
 		}
 }
 
-func GetDiscussionComments(getGQLClient GetGQLClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func GetDiscussionComments(getGQLClient GetGQLClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_discussion_comments",
 			mcp.WithDescription(t("TOOL_GET_DISCUSSION_COMMENTS_DESCRIPTION", "Get comments from a discussion")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -406,7 +429,10 @@ func GetDiscussionComments(getGQLClient GetGQLClientFn, t translations.Translati
 					Discussion struct {
 						Comments struct {
 							Nodes []struct {
-								Body githubv4.String
+								Body   githubv4.String
+								Author struct {
+									Login githubv4.String
+								}
 							}
 							PageInfo struct {
 								HasNextPage     githubv4.Boolean
@@ -436,12 +462,22 @@ func GetDiscussionComments(getGQLClient GetGQLClientFn, t translations.Translati
 
 			var comments []*github.IssueComment
 			for _, c := range q.Repository.Discussion.Comments.Nodes {
-				comments = append(comments, &github.IssueComment{Body: github.Ptr(string(c.Body))})
+				comments = append(comments, &github.IssueComment{
+					Body: github.Ptr(string(c.Body)),
+					User: &github.User{Login: github.Ptr(string(c.Author.Login))},
+				})
+			}
+
+			output, err := applyLockdown(ctx, cache, flags, comments, func(comment *github.IssueComment) contentOrigin {
+				return contentOrigin{Login: comment.GetUser().GetLogin(), Owner: params.Owner, Repo: params.Repo}
+			}, redactIssueComment)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
 			}
 
 			// Create response with pagination info
 			response := map[string]interface{}{
-				"comments": comments,
+				"comments": output,
 				"pageInfo": map[string]interface{}{
 					"hasNextPage":     q.Repository.Discussion.Comments.PageInfo.HasNextPage,
 					"hasPreviousPage": q.Repository.Discussion.Comments.PageInfo.HasPreviousPage,
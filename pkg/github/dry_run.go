@@ -0,0 +1,418 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/google/go-github/v79/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	dryRunParam = "dry_run"
+
+	// dryRunPlaceholder stands in for values, such as the SHA of a created commit, that only exist once an earlier
+	// request of the plan has been sent.
+	dryRunPlaceholder = "<dry-run>"
+)
+
+// PlannedRequest is an API request that a tool would send.
+type PlannedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   any    `json:"body,omitempty"`
+}
+
+// DryRunPlan is the result of a write tool called in dry-run mode.
+type DryRunPlan struct {
+	DryRun   bool             `json:"dry_run"`
+	Tool     string           `json:"tool"`
+	Requests []PlannedRequest `json:"requests"`
+	// Diff is the unified diff of the files the tool would change, for tools that write files.
+	Diff string `json:"diff,omitempty"`
+	// Incomplete explains why the plan may lack requests, when the tool could not continue with simulated
+	// responses.
+	Incomplete string `json:"incomplete,omitempty"`
+}
+
+// dryRunDiffs compute the changes to files made by tools that write files.
+var dryRunDiffs = map[string]func(ctx context.Context, client *github.Client, args map[string]any) (string, error){
+	"create_or_update_file": func(ctx context.Context, client *github.Client, args map[string]any) (string, error) {
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		branch, _ := args["branch"].(string)
+		path, _ := args["path"].(string)
+		content, _ := args["content"].(string)
+		return fileDiff(ctx, client, owner, repo, branch, path, content)
+	},
+	"push_files": func(ctx context.Context, client *github.Client, args map[string]any) (string, error) {
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		branch, _ := args["branch"].(string)
+		files, _ := args["files"].([]any)
+		var diffs strings.Builder
+		for _, file := range files {
+			file, _ := file.(map[string]any)
+			path, _ := file["path"].(string)
+			content, hasContent := file["content"].(string)
+			if encoding, _ := file["encoding"].(string); encoding == "base64" {
+				fmt.Fprintf(&diffs, "Binary file b/%s changed\n", path)
+				hasContent = false
+				if file["operation"] != pushOperationRename {
+					continue
+				}
+			}
+			switch file["operation"] {
+			case pushOperationDelete:
+				diff, err := fileDeletionDiff(ctx, client, owner, repo, branch, path)
+				if err != nil {
+					return "", err
+				}
+				diffs.WriteString(diff)
+				continue
+			case pushOperationRename:
+				previousPath, _ := file["previous_path"].(string)
+				fmt.Fprintf(&diffs, "rename from %s\nrename to %s\n", previousPath, path)
+				if !hasContent {
+					continue
+				}
+				current, err := fileContent(ctx, client, owner, repo, branch, previousPath)
+				if err != nil {
+					return "", err
+				}
+				diff, err := unifiedDiff(current, content, "a/"+previousPath, "b/"+path)
+				if err != nil {
+					return "", err
+				}
+				diffs.WriteString(diff)
+				continue
+			}
+			diff, err := fileDiff(ctx, client, owner, repo, branch, path, content)
+			if err != nil {
+				return "", err
+			}
+			diffs.WriteString(diff)
+		}
+		return diffs.String(), nil
+	},
+	"apply_patch": func(ctx context.Context, client *github.Client, args map[string]any) (string, error) {
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		branch, _ := args["branch"].(string)
+		patch, _ := args["patch"].(string)
+		edits, _ := args["edits"].([]any)
+		files, err := parseFilePatches(patch, edits)
+		if err != nil {
+			return "", err
+		}
+		var diffs strings.Builder
+		for _, file := range files {
+			current, err := fileContent(ctx, client, owner, repo, branch, file.Path)
+			if err != nil {
+				return "", err
+			}
+			content, err := patchContent(file, current)
+			if err != nil {
+				return "", err
+			}
+			fromFile, toFile := "a/"+file.Path, "b/"+file.Path
+			if current == nil {
+				fromFile = "/dev/null"
+			}
+			if content == nil {
+				toFile = "/dev/null"
+				content = new(string)
+			}
+			diff, err := unifiedDiff(current, *content, fromFile, toFile)
+			if err != nil {
+				return "", err
+			}
+			diffs.WriteString(diff)
+		}
+		return diffs.String(), nil
+	},
+}
+
+type dryRunRecorderKey struct{}
+
+// dryRunRecorder collects the mutating requests sent during a dry run.
+type dryRunRecorder struct {
+	mu       sync.Mutex
+	requests []PlannedRequest
+}
+
+func (r *dryRunRecorder) record(request PlannedRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, request)
+}
+
+func (r *dryRunRecorder) planned() []PlannedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]PlannedRequest{}, r.requests...)
+}
+
+// IsDryRun reports whether ctx belongs to a tool call in dry-run mode.
+func IsDryRun(ctx context.Context) bool {
+	_, ok := ctx.Value(dryRunRecorderKey{}).(*dryRunRecorder)
+	return ok
+}
+
+// DryRunTransport records, instead of sending, the mutating requests of tool calls in dry-run mode, and answers
+// them with simulated responses. Reads are sent as usual, so that tools still validate their inputs and resolve
+// refs and IDs. Outside dry-run mode, requests are passed through unchanged.
+type DryRunTransport struct {
+	Transport http.RoundTripper
+}
+
+// NewDryRunTransport wraps transport, or http.DefaultTransport if it is nil.
+func NewDryRunTransport(transport http.RoundTripper) *DryRunTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &DryRunTransport{Transport: transport}
+}
+
+func (t *DryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder, ok := req.Context().Value(dryRunRecorderKey{}).(*dryRunRecorder)
+	if !ok {
+		return t.Transport.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	graphQLMutation := false
+	if req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/graphql") {
+		var gql struct {
+			Query string `json:"query"`
+		}
+		// Requests that cannot be decoded or parsed may hide a mutation, so they are not sent
+		if json.Unmarshal(body, &gql) == nil && !containsGraphQLMutation(gql.Query) {
+			// GraphQL queries only read
+			return t.Transport.RoundTrip(req)
+		}
+		graphQLMutation = true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.Transport.RoundTrip(req)
+	}
+
+	planned := PlannedRequest{Method: req.Method, URL: req.URL.String()}
+	if len(body) > 0 {
+		var decoded any
+		if json.Unmarshal(body, &decoded) == nil {
+			planned.Body = decoded
+		} else {
+			planned.Body = fmt.Sprintf("(%d bytes of %s)", len(body), req.Header.Get("Content-Type"))
+		}
+	}
+	recorder.record(planned)
+
+	return simulatedResponse(req, graphQLMutation), nil
+}
+
+// simulatedResponse answers a mutating request with placeholders for the fields that tools commonly read from
+// the objects they create, such as SHAs and numbers.
+func simulatedResponse(req *http.Request, graphQLMutation bool) *http.Response {
+	status := http.StatusOK
+	var body string
+	switch {
+	case graphQLMutation:
+		body = `{"data":{}}`
+	case req.Method == http.MethodDelete:
+		status = http.StatusNoContent
+	default:
+		if req.Method == http.MethodPost {
+			status = http.StatusCreated
+		}
+		data, _ := json.Marshal(map[string]any{
+			"id":      0,
+			"number":  0,
+			"sha":     dryRunPlaceholder,
+			"node_id": dryRunPlaceholder,
+		})
+		body = string(data)
+	}
+	return &http.Response{
+		Status:     http.StatusText(status),
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+// ApplyDryRun adds a dry_run parameter to every write tool. Calls setting it, or every call if always is set,
+// return the plan of requests the tool would send instead of sending them. The clients of the tools must use a
+// DryRunTransport.
+func ApplyDryRun(tsg *toolsets.ToolsetGroup, always bool, getClient GetClientFn) {
+	tsg.MapTools(func(st server.ServerTool) server.ServerTool {
+		if st.Tool.Annotations.ReadOnlyHint != nil && *st.Tool.Annotations.ReadOnlyHint {
+			return st
+		}
+		st.Handler = dryRunHandler(st.Tool.Name, always, getClient, st.Handler)
+		if st.Tool.RawInputSchema == nil {
+			properties := make(map[string]any, len(st.Tool.InputSchema.Properties)+1)
+			for name, property := range st.Tool.InputSchema.Properties {
+				properties[name] = property
+			}
+			properties[dryRunParam] = map[string]any{
+				"type":        "boolean",
+				"description": "Validate the inputs and return the API requests the tool would send, without changing anything",
+			}
+			st.Tool.InputSchema.Properties = properties
+		}
+		return st
+	})
+}
+
+func dryRunHandler(toolName string, always bool, getClient GetClientFn, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		dryRun, _ := args[dryRunParam].(bool)
+		delete(args, dryRunParam)
+		if !dryRun && !always {
+			return next(ctx, request)
+		}
+
+		recorder := &dryRunRecorder{}
+		ctx = context.WithValue(ctx, dryRunRecorderKey{}, recorder)
+		result, err := next(ctx, request)
+		requests := recorder.planned()
+		if len(requests) == 0 && (err != nil || result == nil || result.IsError) {
+			// The inputs are invalid, or refs and IDs could not be resolved
+			return result, err
+		}
+
+		plan := DryRunPlan{DryRun: true, Tool: toolName, Requests: requests}
+		switch {
+		case err != nil:
+			plan.Incomplete = err.Error()
+		case result != nil && result.IsError:
+			plan.Incomplete = resultText(result)
+		}
+		if diff, ok := dryRunDiffs[toolName]; ok {
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+			if plan.Diff, err = diff(ctx, client, args); err != nil {
+				return nil, fmt.Errorf("failed to compute diff: %w", err)
+			}
+		}
+		return MarshalledTextResult(plan), nil
+	}
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// fileDiff returns the unified diff between the file at path on branch, if any, and content.
+func fileDiff(ctx context.Context, client *github.Client, owner, repo, branch, path, content string) (string, error) {
+	current, err := fileContent(ctx, client, owner, repo, branch, path)
+	if err != nil {
+		return "", err
+	}
+	fromFile := "a/" + path
+	if current == nil {
+		fromFile = "/dev/null"
+	}
+	return unifiedDiff(current, content, fromFile, "b/"+path)
+}
+
+// fileDeletionDiff returns the unified diff removing the file at path on branch.
+func fileDeletionDiff(ctx context.Context, client *github.Client, owner, repo, branch, path string) (string, error) {
+	current, err := fileContent(ctx, client, owner, repo, branch, path)
+	if err != nil {
+		return "", err
+	}
+	if current == nil {
+		return "", fmt.Errorf("cannot delete %s: no such file on branch %s", path, branch)
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(*current),
+		FromFile: "a/" + path,
+		ToFile:   "/dev/null",
+		Context:  3,
+	})
+}
+
+// fileContent returns the content of the file at path on branch, or nil if there is no such file.
+func fileContent(ctx context.Context, client *github.Client, owner, repo, branch, path string) (*string, error) {
+	file, _, resp, err := client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: branch})
+	if resp != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+	switch {
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get %s: %w", path, err)
+	case file == nil:
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return &content, nil
+}
+
+// unifiedDiff returns the unified diff from current, or an empty file if it is nil, to content.
+func unifiedDiff(current *string, content, fromFile, toFile string) (string, error) {
+	var a []string
+	if current != nil {
+		a = diffLines(*current)
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        a,
+		B:        diffLines(content),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
+
+// diffLines splits s into lines that each end with a newline, as difflib expects.
+func diffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if last := lines[len(lines)-1]; last == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] = last + "\n"
+	}
+	return lines
+}
@@ -0,0 +1,280 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dryRunClient returns a client whose requests go through a DryRunTransport to the mocked endpoints.
+func dryRunClient(options ...mock.MockBackendOption) *github.Client {
+	httpClient := mock.NewMockedHTTPClient(options...)
+	httpClient.Transport = NewDryRunTransport(httpClient.Transport)
+	return github.NewClient(httpClient)
+}
+
+func Test_DryRun_PushFiles(t *testing.T) {
+	client := dryRunClient(
+		mock.WithRequestMatch(mock.GetReposGitRefByOwnerByRepoByRef, &github.Reference{
+			Ref:    github.Ptr("refs/heads/main"),
+			Object: &github.GitObject{SHA: github.Ptr("abc123")},
+		}),
+		mock.WithRequestMatch(mock.GetReposGitCommitsByOwnerByRepoByCommitSha, &github.Commit{
+			SHA:  github.Ptr("abc123"),
+			Tree: &github.Tree{SHA: github.Ptr("tree456")},
+		}),
+		mock.WithRequestMatchHandler(mock.GetReposContentsByOwnerByRepoByPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasSuffix(r.URL.Path, "/README.md") {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(&github.RepositoryContent{
+				Type:    github.Ptr("file"),
+				Path:    github.Ptr("README.md"),
+				Content: github.Ptr("# Title\nOld line\n"),
+			})
+		})),
+	)
+
+	tsg := DefaultToolsetGroup(false, stubGetClientFn(client), stubGetGQLClientFn(nil), stubGetRawClientFn(nil), translations.NullTranslationHelper, 5000, FeatureFlags{}, nil)
+	require.NoError(t, tsg.EnableToolsets([]string{"repos"}, nil))
+	ApplyDryRun(tsg, false, stubGetClientFn(client))
+
+	var handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	for _, tool := range tsg.Toolsets["repos"].GetActiveTools() {
+		if tool.Tool.Name == "push_files" {
+			handler = tool.Handler
+			assert.Contains(t, tool.Tool.InputSchema.Properties, dryRunParam)
+		}
+		if tool.Tool.Name == "get_file_contents" {
+			assert.NotContains(t, tool.Tool.InputSchema.Properties, dryRunParam, "read-only tools have no dry run")
+		}
+	}
+	require.NotNil(t, handler)
+
+	result, err := handler(context.Background(), createMCPRequest(map[string]any{
+		"owner":   "owner",
+		"repo":    "repo",
+		"branch":  "main",
+		"message": "Update docs",
+		"dry_run": true,
+		"files": []any{
+			map[string]any{"path": "README.md", "content": "# Title\nNew line\n"},
+			map[string]any{"path": "docs/new.md", "content": "Hello\n"},
+		},
+	}))
+	require.NoError(t, err)
+
+	var plan DryRunPlan
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &plan))
+	assert.True(t, plan.DryRun)
+	assert.Equal(t, "push_files", plan.Tool)
+	assert.Empty(t, plan.Incomplete)
+
+	require.Len(t, plan.Requests, 3)
+	assert.Equal(t, "POST", plan.Requests[0].Method)
+	assert.True(t, strings.HasSuffix(plan.Requests[0].URL, "/repos/owner/repo/git/trees"))
+	assert.Equal(t, "tree456", plan.Requests[0].Body.(map[string]any)["base_tree"], "refs are resolved before planning")
+	assert.Equal(t, "POST", plan.Requests[1].Method)
+	assert.True(t, strings.HasSuffix(plan.Requests[1].URL, "/repos/owner/repo/git/commits"))
+	assert.Equal(t, dryRunPlaceholder, plan.Requests[1].Body.(map[string]any)["tree"])
+	assert.Equal(t, "PATCH", plan.Requests[2].Method)
+	assert.True(t, strings.HasSuffix(plan.Requests[2].URL, "/repos/owner/repo/git/refs/heads/main"))
+
+	assert.Equal(t, `--- a/README.md
++++ b/README.md
+@@ -1,2 +1,2 @@
+ # Title
+-Old line
++New line
+--- /dev/null
++++ b/docs/new.md
+@@ -0,0 +1 @@
++Hello
+`, plan.Diff)
+}
+
+func Test_DryRun_Handler(t *testing.T) {
+	writeHandler := func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		assert.NotContains(t, request.GetArguments(), dryRunParam)
+		if _, ok := request.GetArguments()["title"]; !ok {
+			return mcp.NewToolResultError("missing required parameter: title"), nil
+		}
+		return mcp.NewToolResultText("created"), nil
+	}
+
+	t.Run("without dry run the tool runs", func(t *testing.T) {
+		handler := dryRunHandler("create_issue", false, stubGetClientFn(nil), writeHandler)
+		result, err := handler(context.Background(), createMCPRequest(map[string]any{"title": "x", "dry_run": false}))
+		require.NoError(t, err)
+		assert.Equal(t, "created", getTextResult(t, result).Text)
+	})
+
+	t.Run("invalid inputs are reported as errors", func(t *testing.T) {
+		handler := dryRunHandler("create_issue", true, stubGetClientFn(nil), writeHandler)
+		result, err := handler(context.Background(), createMCPRequest(map[string]any{}))
+		require.NoError(t, err)
+		assert.Equal(t, "missing required parameter: title", getErrorResult(t, result).Text)
+	})
+
+	t.Run("server-wide dry run plans every call", func(t *testing.T) {
+		client := dryRunClient()
+		handler := dryRunHandler("create_issue", true, stubGetClientFn(client), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			issue, resp, err := client.Issues.Create(ctx, "owner", "repo", &github.IssueRequest{Title: github.Ptr("Bug")})
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+			assert.Equal(t, 0, issue.GetNumber())
+			_, _, err = client.Issues.AddLabelsToIssue(ctx, "owner", "repo", issue.GetNumber(), []string{"bug"})
+			if err != nil {
+				return mcp.NewToolResultError("failed to add labels: " + err.Error()), nil
+			}
+			return mcp.NewToolResultText("created"), nil
+		})
+		result, err := handler(context.Background(), createMCPRequest(map[string]any{"title": "Bug"}))
+		require.NoError(t, err)
+
+		var plan DryRunPlan
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &plan))
+		require.Len(t, plan.Requests, 2)
+		assert.Equal(t, map[string]any{"title": "Bug"}, plan.Requests[0].Body)
+		assert.Equal(t, []any{"bug"}, plan.Requests[1].Body)
+		// Simulated responses cannot have every shape, so the tool may stop early
+		assert.Contains(t, plan.Incomplete, "failed to add labels")
+	})
+}
+
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, req)
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func Test_DryRunTransport_GraphQL(t *testing.T) {
+	next := &recordingTransport{}
+	transport := NewDryRunTransport(next)
+	recorder := &dryRunRecorder{}
+	ctx := context.WithValue(context.Background(), dryRunRecorderKey{}, recorder)
+
+	post := func(ctx context.Context, query string) *http.Response {
+		body, _ := json.Marshal(map[string]any{"query": query, "variables": map[string]any{"id": "I_1"}})
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.github.com/graphql", strings.NewReader(string(body)))
+		require.NoError(t, err)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	post(ctx, "query($id:ID!){node(id: $id){id}}")
+	assert.Len(t, next.requests, 1, "queries are sent")
+
+	resp := post(ctx, "mutation($id:ID!){closeIssue(input: {issueId: $id}){clientMutationId}}")
+	assert.Len(t, next.requests, 1, "mutations are not sent")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, recorder.planned(), 1)
+	assert.Equal(t, map[string]any{"id": "I_1"}, recorder.planned()[0].Body.(map[string]any)["variables"])
+
+	post(ctx, "fragment F on Issue{id} mutation($id:ID!){closeIssue(input: {issueId: $id}){issue{...F}}}")
+	assert.Len(t, next.requests, 1, "mutations after other definitions are not sent")
+
+	post(ctx, "query{viewer{login}")
+	assert.Len(t, next.requests, 1, "documents that cannot be parsed are not sent")
+	assert.Len(t, recorder.planned(), 3)
+
+	// Outside dry runs, mutations are sent
+	post(context.Background(), "mutation{x}")
+	assert.Len(t, next.requests, 2)
+}
+
+func Test_DryRun_PushFilesOperations(t *testing.T) {
+	client := dryRunClient(
+		mock.WithRequestMatchHandler(mock.GetReposContentsByOwnerByRepoByPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(&github.RepositoryContent{
+				Type:    github.Ptr("file"),
+				Path:    github.Ptr(strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/contents/")),
+				Content: github.Ptr("One\nTwo\n"),
+			})
+		})),
+	)
+
+	diff, err := dryRunDiffs["push_files"](context.Background(), client, map[string]any{
+		"owner":  "owner",
+		"repo":   "repo",
+		"branch": "main",
+		"files": []any{
+			map[string]any{"path": "old.md", "operation": "delete"},
+			map[string]any{"path": "new.md", "operation": "rename", "previous_path": "moved.md"},
+			map[string]any{"path": "edited.md", "operation": "rename", "previous_path": "draft.md", "content": "One\nThree\n"},
+			map[string]any{"path": "logo.png", "content": "iVBORw0KGgo=", "encoding": "base64"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `--- a/old.md
++++ /dev/null
+@@ -1,2 +0,0 @@
+-One
+-Two
+rename from moved.md
+rename to new.md
+rename from draft.md
+rename to edited.md
+--- a/draft.md
++++ b/edited.md
+@@ -1,2 +1,2 @@
+ One
+-Two
++Three
+Binary file b/logo.png changed
+`, diff)
+}
+
+func Test_DryRun_ApplyPatch(t *testing.T) {
+	client := dryRunClient(
+		mock.WithRequestMatchHandler(mock.GetReposContentsByOwnerByRepoByPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/new.md") {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(&github.RepositoryContent{
+				Type:    github.Ptr("file"),
+				Path:    github.Ptr(strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/contents/")),
+				Content: github.Ptr("One\nTwo\n"),
+			})
+		})),
+	)
+
+	diff, err := dryRunDiffs["apply_patch"](context.Background(), client, map[string]any{
+		"owner":  "owner",
+		"repo":   "repo",
+		"branch": "main",
+		"edits": []any{
+			map[string]any{"path": "edited.md", "search": "Two", "replace": "Three"},
+			map[string]any{"path": "new.md", "search": "", "replace": "New\n"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `--- a/edited.md
++++ b/edited.md
+@@ -1,2 +1,2 @@
+ One
+-Two
++Three
+--- /dev/null
++++ b/new.md
+@@ -0,0 +1 @@
++New
+`, diff)
+}
@@ -43,22 +43,77 @@ func EnableToolset(s *server.MCPServer, toolsetGroup *toolsets.ToolsetGroup, t t
 			if toolset == nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Toolset %s not found", toolsetName)), nil
 			}
-			if toolset.Enabled {
+			if !toolset.SetEnabled(true) {
 				return mcp.NewToolResultText(fmt.Sprintf("Toolset %s is already enabled", toolsetName)), nil
 			}
 
-			toolset.Enabled = true
-
 			// caution: this currently affects the global tools and notifies all clients:
 			//
 			// Send notification to all initialized sessions
 			// s.sendNotificationToAllClients("notifications/tools/list_changed", nil)
 			s.AddTools(toolset.GetActiveTools()...)
+			// Prompts and resources are part of the toolset too, and follow the same read-only rules as its tools
+			if prompts := toolset.GetActivePrompts(); len(prompts) > 0 {
+				s.AddPrompts(prompts...)
+			}
+			if len(toolset.GetActiveResourceTemplates()) > 0 {
+				toolset.RegisterResourcesTemplates(s)
+			}
 
 			return mcp.NewToolResultText(fmt.Sprintf("Toolset %s enabled", toolsetName)), nil
 		}
 }
 
+func DisableToolset(s *server.MCPServer, toolsetGroup *toolsets.ToolsetGroup, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("disable_toolset",
+			mcp.WithDescription(t("TOOL_DISABLE_TOOLSET_DESCRIPTION", "Disable one of the enabled sets of tools the GitHub MCP server provides, removing its tools, prompts and resources. Use this to reduce the number of tools once a toolset is no longer needed")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title: t("TOOL_DISABLE_TOOLSET_USER_TITLE", "Disable a toolset"),
+				// Not modifying GitHub data so no need to show a warning
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("toolset",
+				mcp.Required(),
+				mcp.Description("The name of the toolset to disable"),
+				ToolsetEnum(toolsetGroup),
+			),
+		),
+		func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			toolsetName, err := RequiredParam[string](request, "toolset")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			toolset := toolsetGroup.Toolsets[toolsetName]
+			if toolset == nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Toolset %s not found", toolsetName)), nil
+			}
+			if !toolset.SetEnabled(false) {
+				return mcp.NewToolResultText(fmt.Sprintf("Toolset %s is already disabled", toolsetName)), nil
+			}
+			toolset.UnregisterTools(s)
+			toolset.UnregisterPrompts(s)
+
+			// Some tools are shared between toolsets, so restore those still provided by another enabled toolset
+			removed := make(map[string]bool)
+			for _, st := range toolset.GetAvailableTools() {
+				removed[st.Tool.Name] = true
+			}
+			var shared []server.ServerTool
+			for _, other := range toolsetGroup.Toolsets {
+				for _, st := range other.GetActiveTools() {
+					if removed[st.Tool.Name] {
+						shared = append(shared, st)
+					}
+				}
+			}
+			if len(shared) > 0 {
+				s.AddTools(shared...)
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Toolset %s disabled", toolsetName)), nil
+		}
+}
+
 func ListAvailableToolsets(toolsetGroup *toolsets.ToolsetGroup, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("list_available_toolsets",
 			mcp.WithDescription(t("TOOL_LIST_AVAILABLE_TOOLSETS_DESCRIPTION", "List all available toolsets this GitHub MCP server can offer, providing the enabled status of each. Use this when a task could be achieved with a GitHub tool and the currently available tools aren't enough. Call get_toolset_tools with these toolset names to discover specific tools you can call")),
@@ -78,7 +133,7 @@ func ListAvailableToolsets(toolsetGroup *toolsets.ToolsetGroup, t translations.T
 						"name":              name,
 						"description":       ts.Description,
 						"can_enable":        "true",
-						"currently_enabled": fmt.Sprintf("%t", ts.Enabled),
+						"currently_enabled": fmt.Sprintf("%t", ts.IsEnabled()),
 					}
 					payload = append(payload, t)
 				}
@@ -0,0 +1,137 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/github/github-mcp-server/internal/toolsnaps"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDynamicTestServer(t *testing.T) (*server.MCPServer, *toolsets.ToolsetGroup) {
+	t.Helper()
+	tsg := DefaultToolsetGroup(false, stubGetClientFn(nil), stubGetGQLClientFn(nil), stubGetRawClientFn(nil), translations.NullTranslationHelper, 5000, FeatureFlags{}, nil)
+	s := server.NewMCPServer("test", "0.0.1",
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(true, true),
+		server.WithPromptCapabilities(true),
+	)
+	return s, tsg
+}
+
+// serverNames returns the names, or URI templates, of the items the server lists for method.
+func serverNames(t *testing.T, s *server.MCPServer, method mcp.MCPMethod) []string {
+	t.Helper()
+	response := s.HandleMessage(context.Background(), []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":%q}`, method)))
+	out, err := json.Marshal(response)
+	require.NoError(t, err)
+
+	var listed struct {
+		Result struct {
+			Tools []struct {
+				Name string `json:"name"`
+			} `json:"tools"`
+			Prompts []struct {
+				Name string `json:"name"`
+			} `json:"prompts"`
+			ResourceTemplates []struct {
+				URITemplate string `json:"uriTemplate"`
+			} `json:"resourceTemplates"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(out, &listed))
+
+	names := []string{}
+	for _, tool := range listed.Result.Tools {
+		names = append(names, tool.Name)
+	}
+	for _, prompt := range listed.Result.Prompts {
+		names = append(names, prompt.Name)
+	}
+	for _, template := range listed.Result.ResourceTemplates {
+		names = append(names, template.URITemplate)
+	}
+	return names
+}
+
+func callDynamicTool(t *testing.T, handler server.ToolHandlerFunc, toolset string) string {
+	t.Helper()
+	result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{"toolset": toolset}))
+	require.NoError(t, err)
+	return getTextResult(t, result).Text
+}
+
+func Test_EnableToolset(t *testing.T) {
+	s, tsg := newDynamicTestServer(t)
+	tool, handler := EnableToolset(s, tsg, translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "enable_toolset", tool.Name)
+	assert.Contains(t, tool.InputSchema.Properties, "toolset")
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"toolset"})
+
+	assert.Equal(t, "Toolset issues enabled", callDynamicTool(t, handler, "issues"))
+	assert.True(t, tsg.IsEnabled("issues"))
+	assert.Contains(t, serverNames(t, s, mcp.MethodToolsList), "issue_write")
+	assert.Contains(t, serverNames(t, s, mcp.MethodPromptsList), "AssignCodingAgent")
+
+	assert.Equal(t, "Toolset issues is already enabled", callDynamicTool(t, handler, "issues"))
+
+	result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{"toolset": "unknown"}))
+	require.NoError(t, err)
+	assert.Equal(t, "Toolset unknown not found", getErrorResult(t, result).Text)
+}
+
+func Test_DisableToolset(t *testing.T) {
+	s, tsg := newDynamicTestServer(t)
+	tool, handler := DisableToolset(s, tsg, translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "disable_toolset", tool.Name)
+	assert.Contains(t, tool.InputSchema.Properties, "toolset")
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"toolset"})
+
+	assert.Equal(t, "Toolset issues is already disabled", callDynamicTool(t, handler, "issues"))
+
+	result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{"toolset": "unknown"}))
+	require.NoError(t, err)
+	assert.Equal(t, "Toolset unknown not found", getErrorResult(t, result).Text)
+}
+
+func Test_EnableThenDisableToolset(t *testing.T) {
+	s, tsg := newDynamicTestServer(t)
+	_, enable := EnableToolset(s, tsg, translations.NullTranslationHelper)
+	_, disable := DisableToolset(s, tsg, translations.NullTranslationHelper)
+
+	// The issues toolset has prompts, and the repos toolset has resource templates
+	assert.Equal(t, "Toolset issues enabled", callDynamicTool(t, enable, "issues"))
+	assert.Equal(t, "Toolset repos enabled", callDynamicTool(t, enable, "repos"))
+	assert.Contains(t, serverNames(t, s, mcp.MethodToolsList), "issue_read")
+	assert.Contains(t, serverNames(t, s, mcp.MethodToolsList), "get_file_contents")
+	assert.Contains(t, serverNames(t, s, mcp.MethodPromptsList), "AssignCodingAgent")
+	assert.Contains(t, serverNames(t, s, mcp.MethodResourcesTemplatesList), "repo://{owner}/{repo}/contents{/path*}")
+
+	assert.Equal(t, "Toolset issues disabled", callDynamicTool(t, disable, "issues"))
+	assert.False(t, tsg.IsEnabled("issues"))
+	assert.NotContains(t, serverNames(t, s, mcp.MethodToolsList), "issue_read")
+	assert.NotContains(t, serverNames(t, s, mcp.MethodPromptsList), "AssignCodingAgent")
+	assert.Contains(t, serverNames(t, s, mcp.MethodToolsList), "get_file_contents")
+
+	assert.Equal(t, "Toolset repos disabled", callDynamicTool(t, disable, "repos"))
+	assert.NotContains(t, serverNames(t, s, mcp.MethodToolsList), "get_file_contents")
+
+	// Resource templates cannot be removed from the server, so reads of a disabled toolset's templates fail
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"repo://owner/repo/contents/README.md"}}`))
+	out, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "toolset repos is disabled")
+
+	assert.Equal(t, "Toolset repos is already disabled", callDynamicTool(t, disable, "repos"))
+}
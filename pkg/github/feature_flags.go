@@ -3,4 +3,7 @@ package github
 // FeatureFlags defines runtime feature toggles that adjust tool behavior.
 type FeatureFlags struct {
 	LockdownMode bool
+	// LockdownRedact keeps content from untrusted authors in lockdown mode with its text replaced,
+	// instead of withholding it.
+	LockdownRedact bool
 }
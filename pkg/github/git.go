@@ -1,16 +1,20 @@
 package github
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/lockdown"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/google/go-github/v79/github"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/shurcooL/githubv4"
 )
 
 // TreeEntryResponse represents a single entry in a Git tree.
@@ -158,3 +162,397 @@ func GetRepositoryTree(getClient GetClientFn, t translations.TranslationHelperFu
 			return mcp.NewToolResultText(string(r)), nil
 		}
 }
+
+// BlameCommit is the commit that last changed the lines of a blame hunk.
+type BlameCommit struct {
+	SHA         string       `json:"sha"`
+	Message     string       `json:"message"`
+	AuthorName  string       `json:"author_name,omitempty"`
+	AuthorEmail string       `json:"author_email,omitempty"`
+	AuthorLogin string       `json:"author_login,omitempty"`
+	Date        string       `json:"date,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	PullRequest *BlamePRInfo `json:"pull_request,omitempty"`
+}
+
+// BlamePRInfo is the pull request that introduced the commit of a blame hunk.
+type BlamePRInfo struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+}
+
+// BlameHunk is a range of consecutive lines last changed by the same commit.
+type BlameHunk struct {
+	StartLine int          `json:"start_line"`
+	EndLine   int          `json:"end_line"`
+	Commit    *BlameCommit `json:"commit"`
+}
+
+// BlameResponse is the blame of a file, or of a range of its lines.
+type BlameResponse struct {
+	Path  string `json:"path"`
+	Ref   string `json:"ref"`
+	SHA   string `json:"sha"`
+	Hunks []any  `json:"hunks"`
+}
+
+type blameQuery struct {
+	Repository struct {
+		Object struct {
+			Commit struct {
+				OID   githubv4.GitObjectID
+				Blame struct {
+					Ranges []struct {
+						StartingLine githubv4.Int
+						EndingLine   githubv4.Int
+						Commit       struct {
+							OID             githubv4.GitObjectID
+							MessageHeadline githubv4.String
+							CommittedDate   githubv4.DateTime
+							URL             githubv4.URI
+							Author          struct {
+								Name  githubv4.String
+								Email githubv4.String
+								User  *struct {
+									Login githubv4.String
+								}
+							}
+							AssociatedPullRequests struct {
+								Nodes []struct {
+									Number githubv4.Int
+									Title  githubv4.String
+									URL    githubv4.URI
+								}
+							} `graphql:"associatedPullRequests(first: 1)"`
+						}
+					}
+				} `graphql:"blame(path: $path)"`
+			} `graphql:"... on Commit"`
+		} `graphql:"object(expression: $ref)"`
+	} `graphql:"repository(owner: $owner, name: $repo)"`
+}
+
+// GetFileBlame creates a tool to get the blame of a file, showing the commit that last changed each line.
+func GetFileBlame(getGQLClient GetGQLClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("get_file_blame",
+			mcp.WithDescription(t("TOOL_GET_FILE_BLAME_DESCRIPTION", "Get the blame of a file in a GitHub repository: for each range of lines, the commit that last changed them, with its author and the pull request that introduced it")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_GET_FILE_BLAME_USER_TITLE", "Get file blame"),
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("path",
+				mcp.Required(),
+				mcp.Description("Path to the file"),
+			),
+			mcp.WithString("ref",
+				mcp.Description("Commit SHA, branch or tag name to blame the file at. Defaults to the default branch"),
+			),
+			mcp.WithNumber("start_line",
+				mcp.Description("First line to blame, starting at 1. Defaults to the first line of the file"),
+				mcp.Min(1),
+			),
+			mcp.WithNumber("end_line",
+				mcp.Description("Last line to blame. Defaults to the last line of the file"),
+				mcp.Min(1),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			path, err := RequiredParam[string](request, "path")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			ref, err := OptionalParam[string](request, "ref")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if ref == "" {
+				ref = "HEAD"
+			}
+			startLine, err := OptionalIntParam(request, "start_line")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			endLine, err := OptionalIntParam(request, "end_line")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if startLine > 0 && endLine > 0 && endLine < startLine {
+				return mcp.NewToolResultError("end_line must not be before start_line"), nil
+			}
+
+			client, err := getGQLClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub GQL client: %w", err)
+			}
+
+			var query blameQuery
+			vars := map[string]any{
+				"owner": githubv4.String(owner),
+				"repo":  githubv4.String(repo),
+				"ref":   githubv4.String(ref),
+				"path":  githubv4.String(strings.TrimPrefix(path, "/")),
+			}
+			if err := client.Query(ctx, &query, vars); err != nil {
+				return ghErrors.NewGitHubGraphQLErrorResponse(ctx, fmt.Sprintf("failed to get blame of %s", path), err), nil
+			}
+			commit := query.Repository.Object.Commit
+			if commit.OID == "" {
+				return mcp.NewToolResultError(fmt.Sprintf("ref %s not found in %s/%s", ref, owner, repo)), nil
+			}
+
+			var hunks []BlameHunk
+			for _, r := range commit.Blame.Ranges {
+				hunk := BlameHunk{StartLine: int(r.StartingLine), EndLine: int(r.EndingLine)}
+				// Clip the hunks to the requested lines
+				if startLine > 0 {
+					hunk.StartLine = max(hunk.StartLine, startLine)
+				}
+				if endLine > 0 {
+					hunk.EndLine = min(hunk.EndLine, endLine)
+				}
+				if hunk.StartLine > hunk.EndLine {
+					continue
+				}
+				hunk.Commit = &BlameCommit{
+					SHA:         string(r.Commit.OID),
+					Message:     string(r.Commit.MessageHeadline),
+					AuthorName:  string(r.Commit.Author.Name),
+					AuthorEmail: string(r.Commit.Author.Email),
+					Date:        r.Commit.CommittedDate.UTC().Format(time.RFC3339),
+					URL:         r.Commit.URL.String(),
+				}
+				if r.Commit.Author.User != nil {
+					hunk.Commit.AuthorLogin = string(r.Commit.Author.User.Login)
+				}
+				if prs := r.Commit.AssociatedPullRequests.Nodes; len(prs) > 0 {
+					hunk.Commit.PullRequest = &BlamePRInfo{
+						Number: int(prs[0].Number),
+						Title:  string(prs[0].Title),
+						URL:    prs[0].URL.String(),
+					}
+				}
+				hunks = append(hunks, hunk)
+			}
+			if len(hunks) == 0 && (startLine > 0 || endLine > 0) {
+				return mcp.NewToolResultError(fmt.Sprintf("%s has no lines in the requested range", path)), nil
+			}
+
+			output, err := applyLockdown(ctx, cache, flags, hunks, func(hunk BlameHunk) contentOrigin {
+				return contentOrigin{Login: hunk.Commit.AuthorLogin, Owner: owner, Repo: repo}
+			}, redactBlameHunk)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+			}
+			if output == nil {
+				output = []any{}
+			}
+
+			r, err := json.Marshal(BlameResponse{
+				Path:  path,
+				Ref:   ref,
+				SHA:   string(commit.OID),
+				Hunks: output,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
+
+// FileHistoryCommit is a commit that changed a file, with the path the file had at that commit.
+type FileHistoryCommit struct {
+	MinimalCommit
+	Path string `json:"path"`
+}
+
+// FileRename is a rename of a file followed by get_file_history.
+type FileRename struct {
+	SHA  string `json:"sha"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// FileHistoryResponse is the history of a file, newest commit first.
+type FileHistoryResponse struct {
+	Path    string       `json:"path"`
+	Commits []any        `json:"commits"`
+	Renames []FileRename `json:"renames,omitempty"`
+	// NextSHA and NextPath continue the history when it is longer than the requested number of commits.
+	NextSHA  string `json:"next_sha,omitempty"`
+	NextPath string `json:"next_path,omitempty"`
+}
+
+// GetFileHistory creates a tool to list the commits that changed a file, following its renames.
+func GetFileHistory(getClient GetClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("get_file_history",
+			mcp.WithDescription(t("TOOL_GET_FILE_HISTORY_DESCRIPTION", "List the commits that changed a file in a GitHub repository, newest first. Follows renames of the file, so that its history before a rename is included.")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_GET_FILE_HISTORY_USER_TITLE", "Get file history"),
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("path",
+				mcp.Required(),
+				mcp.Description("Path to the file"),
+			),
+			mcp.WithString("sha",
+				mcp.Description("Commit SHA, branch or tag name to start the history from. Defaults to the default branch. To continue a history, pass the next_sha and next_path of the previous result"),
+			),
+			mcp.WithBoolean("follow_renames",
+				mcp.Description("Whether to continue the history under the previous path of a renamed file. Default is true."),
+				mcp.DefaultBool(true),
+			),
+			mcp.WithNumber("perPage",
+				mcp.Description("Maximum number of commits to return (min 1, max 100). Default is 30."),
+				mcp.Min(1),
+				mcp.Max(100),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			path, err := RequiredParam[string](request, "path")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			sha, err := OptionalParam[string](request, "sha")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			followRenames, err := OptionalBoolParamWithDefault(request, "follow_renames", true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			limit, err := OptionalIntParamWithDefault(request, "perPage", 30)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			history := FileHistoryResponse{Path: path}
+			var commits []FileHistoryCommit
+			currentPath := strings.TrimPrefix(path, "/")
+			for len(commits) < limit {
+				requested := limit - len(commits)
+				listed, resp, err := client.Repositories.ListCommits(ctx, owner, repo, &github.CommitsListOptions{
+					SHA:         sha,
+					Path:        currentPath,
+					ListOptions: github.ListOptions{PerPage: requested},
+				})
+				if err != nil {
+					return ghErrors.NewGitHubAPIErrorResponse(ctx,
+						fmt.Sprintf("failed to list commits of %s", currentPath),
+						resp,
+						err,
+					), nil
+				}
+				_ = resp.Body.Close()
+
+				for _, commit := range listed {
+					commits = append(commits, FileHistoryCommit{MinimalCommit: convertToMinimalCommit(commit, false), Path: currentPath})
+				}
+				if len(listed) == 0 {
+					break
+				}
+				oldest := listed[len(listed)-1]
+				// The history may go on beyond the requested number of commits
+				full := len(listed) == requested
+				if len(oldest.Parents) == 0 || !full && !followRenames {
+					break
+				}
+
+				// The oldest commit of a path either added the file, or renamed it from another path. A full page
+				// may also end on a commit that only changed the file
+				previousPath := ""
+				if followRenames {
+					detail, resp, err := client.Repositories.GetCommit(ctx, owner, repo, oldest.GetSHA(), nil)
+					if err != nil {
+						return ghErrors.NewGitHubAPIErrorResponse(ctx,
+							fmt.Sprintf("failed to get commit: %s", oldest.GetSHA()),
+							resp,
+							err,
+						), nil
+					}
+					_ = resp.Body.Close()
+					for _, file := range detail.Files {
+						if file.GetFilename() == currentPath && file.GetStatus() == "renamed" {
+							previousPath = file.GetPreviousFilename()
+						}
+					}
+				}
+				if previousPath != "" {
+					history.Renames = append(history.Renames, FileRename{SHA: oldest.GetSHA(), From: previousPath, To: currentPath})
+				}
+				if full {
+					// The continuation starts under the path the file has at the parent
+					history.NextSHA = oldest.Parents[0].GetSHA()
+					history.NextPath = cmp.Or(previousPath, currentPath)
+					break
+				}
+				if previousPath == "" {
+					break
+				}
+				sha = oldest.Parents[0].GetSHA()
+				currentPath = previousPath
+			}
+
+			history.Commits, err = applyLockdown(ctx, cache, flags, commits, func(commit FileHistoryCommit) contentOrigin {
+				origin := contentOrigin{Owner: owner, Repo: repo}
+				if commit.Author != nil {
+					origin.Login = commit.Author.Login
+				}
+				return origin
+			}, func(commit FileHistoryCommit, placeholder string) {
+				redactMinimalCommit(commit.MinimalCommit, placeholder)
+			})
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+			}
+			if history.Commits == nil {
+				history.Commits = []any{}
+			}
+
+			r, err := json.Marshal(history)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
@@ -0,0 +1,1015 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"unicode/utf8"
+
+	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxGitBlobBytes is the size of the largest blob returned by get_git_blob.
+const maxGitBlobBytes = 1 << 20
+
+// GitObjectResponse identifies a Git object.
+type GitObjectResponse struct {
+	Type string `json:"type"`
+	SHA  string `json:"sha"`
+}
+
+// GitRefResponse is a Git reference and the object it points to.
+type GitRefResponse struct {
+	Ref    string            `json:"ref"`
+	Object GitObjectResponse `json:"object"`
+}
+
+// GitBlobResponse is the content of a Git blob. Content that is not valid UTF-8 text is base64 encoded.
+type GitBlobResponse struct {
+	SHA      string `json:"sha"`
+	Size     int    `json:"size"`
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+}
+
+// GitCommitResponse is a Git commit object.
+type GitCommitResponse struct {
+	SHA       string               `json:"sha"`
+	HTMLURL   string               `json:"html_url,omitempty"`
+	Message   string               `json:"message"`
+	Author    *MinimalCommitAuthor `json:"author,omitempty"`
+	Committer *MinimalCommitAuthor `json:"committer,omitempty"`
+	TreeSHA   string               `json:"tree_sha"`
+	Parents   []string             `json:"parents"`
+	Verified  bool                 `json:"verified"`
+}
+
+// GitTagResponse is an annotated Git tag object.
+type GitTagResponse struct {
+	SHA      string               `json:"sha"`
+	Tag      string               `json:"tag"`
+	Message  string               `json:"message"`
+	Tagger   *MinimalCommitAuthor `json:"tagger,omitempty"`
+	Object   GitObjectResponse    `json:"object"`
+	Verified bool                 `json:"verified"`
+	// Ref is the tag reference created for the tag, if any.
+	Ref string `json:"ref,omitempty"`
+}
+
+func convertToGitRefResponse(ref *github.Reference) GitRefResponse {
+	return GitRefResponse{
+		Ref: ref.GetRef(),
+		Object: GitObjectResponse{
+			Type: ref.GetObject().GetType(),
+			SHA:  ref.GetObject().GetSHA(),
+		},
+	}
+}
+
+func convertToGitCommitAuthor(author *github.CommitAuthor) *MinimalCommitAuthor {
+	if author == nil {
+		return nil
+	}
+	minimalAuthor := &MinimalCommitAuthor{
+		Name:  author.GetName(),
+		Email: author.GetEmail(),
+	}
+	if author.Date != nil {
+		minimalAuthor.Date = author.Date.Format("2006-01-02T15:04:05Z")
+	}
+	return minimalAuthor
+}
+
+func convertToGitCommitResponse(commit *github.Commit) GitCommitResponse {
+	response := GitCommitResponse{
+		SHA:       commit.GetSHA(),
+		HTMLURL:   commit.GetHTMLURL(),
+		Message:   commit.GetMessage(),
+		Author:    convertToGitCommitAuthor(commit.Author),
+		Committer: convertToGitCommitAuthor(commit.Committer),
+		TreeSHA:   commit.GetTree().GetSHA(),
+		Parents:   make([]string, 0, len(commit.Parents)),
+		Verified:  commit.GetVerification().GetVerified(),
+	}
+	for _, parent := range commit.Parents {
+		response.Parents = append(response.Parents, parent.GetSHA())
+	}
+	return response
+}
+
+func convertToGitTagResponse(tag *github.Tag) GitTagResponse {
+	return GitTagResponse{
+		SHA:     tag.GetSHA(),
+		Tag:     tag.GetTag(),
+		Message: tag.GetMessage(),
+		Tagger:  convertToGitCommitAuthor(tag.Tagger),
+		Object: GitObjectResponse{
+			Type: tag.GetObject().GetType(),
+			SHA:  tag.GetObject().GetSHA(),
+		},
+		Verified: tag.GetVerification().GetVerified(),
+	}
+}
+
+// GetGitBlob creates a tool to get a Git blob by its SHA.
+func GetGitBlob(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("get_git_blob",
+			mcp.WithDescription(t("TOOL_GET_GIT_BLOB_DESCRIPTION", "Get the content of a Git blob by its SHA, as listed by get_repository_tree. Binary content is base64 encoded")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_GET_GIT_BLOB_USER_TITLE", "Get Git blob"),
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("sha",
+				mcp.Required(),
+				mcp.Description("SHA of the blob"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			sha, err := RequiredParam[string](request, "sha")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			blob, resp, err := client.Git.GetBlob(ctx, owner, repo, sha)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					fmt.Sprintf("failed to get blob: %s", sha),
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if blob.GetSize() > maxGitBlobBytes {
+				return mcp.NewToolResultError(fmt.Sprintf("blob %s is %d bytes, more than the %d bytes that can be returned", sha, blob.GetSize(), maxGitBlobBytes)), nil
+			}
+
+			response := GitBlobResponse{SHA: blob.GetSHA(), Size: blob.GetSize(), Encoding: "base64", Content: blob.GetContent()}
+			if blob.GetEncoding() == "base64" {
+				content, err := base64.StdEncoding.DecodeString(blob.GetContent())
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode blob: %w", err)
+				}
+				response.Content = base64.StdEncoding.EncodeToString(content)
+				if utf8.Valid(content) && !bytes.ContainsRune(content, 0) {
+					response.Encoding = "utf-8"
+					response.Content = string(content)
+				}
+			} else {
+				response.Encoding = blob.GetEncoding()
+			}
+			return MarshalledTextResult(response), nil
+		}
+}
+
+// GetGitCommit creates a tool to get a Git commit object by its SHA.
+func GetGitCommit(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("get_git_commit",
+			mcp.WithDescription(t("TOOL_GET_GIT_COMMIT_DESCRIPTION", "Get a Git commit object by its SHA: its message, author, committer, tree and parents. Use get_commit for the changed files")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_GET_GIT_COMMIT_USER_TITLE", "Get Git commit"),
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("sha",
+				mcp.Required(),
+				mcp.Description("SHA of the commit"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			sha, err := RequiredParam[string](request, "sha")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			commit, resp, err := client.Git.GetCommit(ctx, owner, repo, sha)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					fmt.Sprintf("failed to get commit: %s", sha),
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			return MarshalledTextResult(convertToGitCommitResponse(commit)), nil
+		}
+}
+
+// GetGitRef creates a tool to get a Git reference.
+func GetGitRef(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("get_git_ref",
+			mcp.WithDescription(t("TOOL_GET_GIT_REF_DESCRIPTION", "Get a Git reference and the SHA of the object it points to")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_GET_GIT_REF_USER_TITLE", "Get Git reference"),
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("ref",
+				mcp.Required(),
+				mcp.Description("Fully qualified reference, such as 'heads/main' or 'tags/v1.0.0'. The 'refs/' prefix is optional"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			ref, err := RequiredParam[string](request, "ref")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			reference, resp, err := client.Git.GetRef(ctx, owner, repo, ref)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					fmt.Sprintf("failed to get reference: %s", ref),
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			return MarshalledTextResult(convertToGitRefResponse(reference)), nil
+		}
+}
+
+// ListGitRefs creates a tool to list the Git references that start with a prefix.
+func ListGitRefs(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("list_git_refs",
+			mcp.WithDescription(t("TOOL_LIST_GIT_REFS_DESCRIPTION", "List the Git references of a repository that start with a prefix")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_LIST_GIT_REFS_USER_TITLE", "List Git references"),
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("prefix",
+				mcp.Description("Prefix of the references, such as 'heads/feature' or 'tags/v1.'. Lists all references by default"),
+			),
+			WithPagination(),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			prefix, err := OptionalParam[string](request, "prefix")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			pagination, err := OptionalPaginationParams(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			refs, resp, err := client.Git.ListMatchingRefs(ctx, owner, repo, &github.ReferenceListOptions{
+				Ref: prefix,
+				ListOptions: github.ListOptions{
+					Page:    pagination.Page,
+					PerPage: pagination.PerPage,
+				},
+			})
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					"failed to list references",
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			response := make([]GitRefResponse, 0, len(refs))
+			for _, ref := range refs {
+				response = append(response, convertToGitRefResponse(ref))
+			}
+			return MarshalledTextResult(response), nil
+		}
+}
+
+// GetGitTag creates a tool to get an annotated Git tag object by its SHA.
+func GetGitTag(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("get_git_tag",
+			mcp.WithDescription(t("TOOL_GET_GIT_TAG_DESCRIPTION", "Get an annotated Git tag object by its SHA, which is the object SHA of a tag reference of type 'tag'")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_GET_GIT_TAG_USER_TITLE", "Get Git tag"),
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("sha",
+				mcp.Required(),
+				mcp.Description("SHA of the tag object"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			sha, err := RequiredParam[string](request, "sha")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			tag, resp, err := client.Git.GetTag(ctx, owner, repo, sha)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					fmt.Sprintf("failed to get tag: %s", sha),
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			return MarshalledTextResult(convertToGitTagResponse(tag)), nil
+		}
+}
+
+// CreateGitBlob creates a tool to create a Git blob.
+func CreateGitBlob(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("create_git_blob",
+			mcp.WithDescription(t("TOOL_CREATE_GIT_BLOB_DESCRIPTION", "Create a Git blob from content, returning its SHA for use in create_git_tree")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_CREATE_GIT_BLOB_USER_TITLE", "Create Git blob"),
+				ReadOnlyHint: ToBoolPtr(false),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("content",
+				mcp.Required(),
+				mcp.Description("Content of the blob"),
+			),
+			mcp.WithString("encoding",
+				mcp.Description("Encoding of content: utf-8 (default) or base64 for binary content"),
+				mcp.Enum("utf-8", "base64"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			content, err := RequiredParam[string](request, "content")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			encoding, err := OptionalParam[string](request, "encoding")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			switch encoding {
+			case "":
+				encoding = "utf-8"
+			case "utf-8":
+			case "base64":
+				if _, err := base64.StdEncoding.DecodeString(content); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("content is not valid base64: %v", err)), nil
+				}
+			default:
+				return mcp.NewToolResultError(fmt.Sprintf("unknown encoding %q: must be utf-8 or base64", encoding)), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			blob, resp, err := client.Git.CreateBlob(ctx, owner, repo, github.Blob{
+				Content:  github.Ptr(content),
+				Encoding: github.Ptr(encoding),
+			})
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					"failed to create blob",
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			return MarshalledTextResult(GitObjectResponse{Type: "blob", SHA: blob.GetSHA()}), nil
+		}
+}
+
+// gitTreeEntryModes are the default modes of tree entries by their type.
+var gitTreeEntryModes = map[string]string{
+	"blob":   defaultFileMode,
+	"tree":   "040000",
+	"commit": "160000",
+}
+
+// parseGitTreeEntries converts the tree argument of create_git_tree to tree entries.
+func parseGitTreeEntries(entriesObj []interface{}) ([]*github.TreeEntry, error) {
+	entries := make([]*github.TreeEntry, 0, len(entriesObj))
+	for _, entryObj := range entriesObj {
+		entryMap, ok := entryObj.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("each tree entry must be an object with a path")
+		}
+		path, ok := entryMap["path"].(string)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("each tree entry must have a path")
+		}
+		entryType, _ := entryMap["type"].(string)
+		if entryType == "" {
+			entryType = "blob"
+		}
+		mode, ok := gitTreeEntryModes[entryType]
+		if !ok {
+			return nil, fmt.Errorf("tree entry %s has invalid type %q: must be blob, tree or commit", path, entryType)
+		}
+		if m, _ := entryMap["mode"].(string); m != "" {
+			mode = m
+		}
+		entry := &github.TreeEntry{
+			Path: github.Ptr(path),
+			Mode: github.Ptr(mode),
+			Type: github.Ptr(entryType),
+		}
+
+		sha, _ := entryMap["sha"].(string)
+		content, hasContent := entryMap["content"].(string)
+		remove, _ := entryMap["delete"].(bool)
+		switch {
+		case remove:
+			// An entry with neither content nor SHA deletes the path
+			if sha != "" || hasContent {
+				return nil, fmt.Errorf("tree entry %s is deleted, so it takes no sha or content", path)
+			}
+		case sha != "" && hasContent:
+			return nil, fmt.Errorf("tree entry %s must have either sha or content, not both", path)
+		case sha != "":
+			entry.SHA = github.Ptr(sha)
+		case hasContent:
+			if entryType != "blob" {
+				return nil, fmt.Errorf("tree entry %s has content, so its type must be blob", path)
+			}
+			entry.Content = github.Ptr(content)
+		default:
+			return nil, fmt.Errorf("tree entry %s must have a sha or content, or be deleted", path)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// CreateGitTree creates a tool to create a Git tree.
+func CreateGitTree(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("create_git_tree",
+			mcp.WithDescription(t("TOOL_CREATE_GIT_TREE_DESCRIPTION", "Create a Git tree, optionally on top of a base tree, returning its SHA for use in create_git_commit. Paths of the base tree that are not listed are kept")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_CREATE_GIT_TREE_USER_TITLE", "Create Git tree"),
+				ReadOnlyHint: ToBoolPtr(false),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("base_tree",
+				mcp.Description("SHA of the tree to change. Without it, the tree contains only the given entries"),
+			),
+			mcp.WithArray("tree",
+				mcp.Required(),
+				mcp.Items(
+					map[string]interface{}{
+						"type":                 "object",
+						"additionalProperties": false,
+						"required":             []string{"path"},
+						"properties": map[string]interface{}{
+							"path": map[string]interface{}{
+								"type":        "string",
+								"description": "path of the entry in the tree",
+							},
+							"type": map[string]interface{}{
+								"type":        "string",
+								"description": "blob (default) for a file, tree for a directory, or commit for a submodule",
+								"enum":        []string{"blob", "tree", "commit"},
+							},
+							"mode": map[string]interface{}{
+								"type":        "string",
+								"description": "mode of the entry. Defaults to 100644 for a blob, 040000 for a tree and 160000 for a commit. Use 100755 for an executable and 120000 for a symlink",
+								"enum":        []string{"100644", "100755", "040000", "160000", "120000"},
+							},
+							"sha": map[string]interface{}{
+								"type":        "string",
+								"description": "SHA of the object of the entry",
+							},
+							"content": map[string]interface{}{
+								"type":        "string",
+								"description": "UTF-8 content of a blob entry, instead of sha",
+							},
+							"delete": map[string]interface{}{
+								"type":        "boolean",
+								"description": "whether to delete the path from the base tree",
+							},
+						},
+					}),
+				mcp.Description("Entries of the tree, each with a path and either a sha, content, or delete set to true"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			baseTree, err := OptionalParam[string](request, "base_tree")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			entriesObj, ok := request.GetArguments()["tree"].([]interface{})
+			if !ok || len(entriesObj) == 0 {
+				return mcp.NewToolResultError("tree parameter must be a non-empty array of entries"), nil
+			}
+			entries, err := parseGitTreeEntries(entriesObj)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			tree, resp, err := client.Git.CreateTree(ctx, owner, repo, baseTree, entries)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					"failed to create tree",
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			return MarshalledTextResult(GitObjectResponse{Type: "tree", SHA: tree.GetSHA()}), nil
+		}
+}
+
+// CreateGitCommit creates a tool to create a Git commit object.
+func CreateGitCommit(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("create_git_commit",
+			mcp.WithDescription(t("TOOL_CREATE_GIT_COMMIT_DESCRIPTION", "Create a Git commit object from a tree and its parents. The commit is not on any branch until a reference is updated to it with update_git_ref")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_CREATE_GIT_COMMIT_USER_TITLE", "Create Git commit"),
+				ReadOnlyHint: ToBoolPtr(false),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("message",
+				mcp.Required(),
+				mcp.Description("Commit message"),
+			),
+			mcp.WithString("tree",
+				mcp.Required(),
+				mcp.Description("SHA of the tree of the commit"),
+			),
+			mcp.WithArray("parents",
+				mcp.Description("SHAs of the parent commits: one for a regular commit, several for a merge commit, none for a root commit"),
+				mcp.Items(map[string]interface{}{
+					"type": "string",
+				}),
+			),
+			mcp.WithString("author_name",
+				mcp.Description("Name of the author. Defaults to the authenticated user"),
+			),
+			mcp.WithString("author_email",
+				mcp.Description("Email of the author, required with author_name"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			message, err := RequiredParam[string](request, "message")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			treeSHA, err := RequiredParam[string](request, "tree")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			parents, err := OptionalStringArrayParam(request, "parents")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			authorName, err := OptionalParam[string](request, "author_name")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			authorEmail, err := OptionalParam[string](request, "author_email")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if (authorName == "") != (authorEmail == "") {
+				return mcp.NewToolResultError("author_name and author_email must be given together"), nil
+			}
+
+			commit := github.Commit{
+				Message: github.Ptr(message),
+				Tree:    &github.Tree{SHA: github.Ptr(treeSHA)},
+			}
+			for _, parent := range parents {
+				commit.Parents = append(commit.Parents, &github.Commit{SHA: github.Ptr(parent)})
+			}
+			if authorName != "" {
+				commit.Author = &github.CommitAuthor{Name: github.Ptr(authorName), Email: github.Ptr(authorEmail)}
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			created, resp, err := client.Git.CreateCommit(ctx, owner, repo, commit, nil)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					"failed to create commit",
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			return MarshalledTextResult(convertToGitCommitResponse(created)), nil
+		}
+}
+
+// UpdateGitRef creates a tool to point a Git reference to another commit.
+func UpdateGitRef(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("update_git_ref",
+			mcp.WithDescription(t("TOOL_UPDATE_GIT_REF_DESCRIPTION", "Point a Git reference, such as a branch, to another commit. Without force, the update must be a fast-forward")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:           t("TOOL_UPDATE_GIT_REF_USER_TITLE", "Update Git reference"),
+				ReadOnlyHint:    ToBoolPtr(false),
+				DestructiveHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("ref",
+				mcp.Required(),
+				mcp.Description("Fully qualified reference, such as 'heads/main'. The 'refs/' prefix is optional"),
+			),
+			mcp.WithString("sha",
+				mcp.Required(),
+				mcp.Description("SHA of the commit to point the reference to"),
+			),
+			mcp.WithBoolean("force",
+				mcp.Description("Whether to allow an update that is not a fast-forward, discarding the commits only reachable from the current SHA. Default is false"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			ref, err := RequiredParam[string](request, "ref")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			sha, err := RequiredParam[string](request, "sha")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			force, err := OptionalParam[bool](request, "force")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			updated, resp, err := client.Git.UpdateRef(ctx, owner, repo, ref, github.UpdateRef{
+				SHA:   sha,
+				Force: github.Ptr(force),
+			})
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					fmt.Sprintf("failed to update reference: %s", ref),
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			return MarshalledTextResult(convertToGitRefResponse(updated)), nil
+		}
+}
+
+// DeleteGitRef creates a tool to delete a Git reference.
+func DeleteGitRef(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("delete_git_ref",
+			mcp.WithDescription(t("TOOL_DELETE_GIT_REF_DESCRIPTION", "Delete a Git reference, such as a branch or a tag")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:           t("TOOL_DELETE_GIT_REF_USER_TITLE", "Delete Git reference"),
+				ReadOnlyHint:    ToBoolPtr(false),
+				DestructiveHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("ref",
+				mcp.Required(),
+				mcp.Description("Fully qualified reference, such as 'heads/feature' or 'tags/v1.0.0'. The 'refs/' prefix is optional"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			ref, err := RequiredParam[string](request, "ref")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			resp, err := client.Git.DeleteRef(ctx, owner, repo, ref)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					fmt.Sprintf("failed to delete reference: %s", ref),
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			return mcp.NewToolResultText(fmt.Sprintf("deleted reference %s", ref)), nil
+		}
+}
+
+// CreateGitTag creates a tool to create an annotated Git tag.
+func CreateGitTag(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("create_git_tag",
+			mcp.WithDescription(t("TOOL_CREATE_GIT_TAG_DESCRIPTION", "Create an annotated Git tag with a message, and the tag reference pointing to it")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_CREATE_GIT_TAG_USER_TITLE", "Create Git tag"),
+				ReadOnlyHint: ToBoolPtr(false),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("tag",
+				mcp.Required(),
+				mcp.Description("Name of the tag (e.g., 'v1.0.0')"),
+			),
+			mcp.WithString("message",
+				mcp.Required(),
+				mcp.Description("Message of the tag"),
+			),
+			mcp.WithString("object",
+				mcp.Required(),
+				mcp.Description("SHA of the object to tag, usually a commit"),
+			),
+			mcp.WithString("type",
+				mcp.Description("Type of the tagged object. Default is commit"),
+				mcp.Enum("commit", "tree", "blob"),
+			),
+			mcp.WithString("tagger_name",
+				mcp.Description("Name of the tagger. Defaults to the authenticated user"),
+			),
+			mcp.WithString("tagger_email",
+				mcp.Description("Email of the tagger, required with tagger_name"),
+			),
+			mcp.WithBoolean("create_ref",
+				mcp.Description("Whether to create the reference tags/<tag> pointing to the tag. Without it, the tag object is unreachable. Default is true"),
+				mcp.DefaultBool(true),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			tagName, err := RequiredParam[string](request, "tag")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			message, err := RequiredParam[string](request, "message")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			object, err := RequiredParam[string](request, "object")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			objectType, err := OptionalParam[string](request, "type")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if objectType == "" {
+				objectType = "commit"
+			}
+			taggerName, err := OptionalParam[string](request, "tagger_name")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			taggerEmail, err := OptionalParam[string](request, "tagger_email")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if (taggerName == "") != (taggerEmail == "") {
+				return mcp.NewToolResultError("tagger_name and tagger_email must be given together"), nil
+			}
+			createRef, err := OptionalBoolParamWithDefault(request, "create_ref", true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			newTag := github.CreateTag{
+				Tag:     tagName,
+				Message: message,
+				Object:  object,
+				Type:    objectType,
+			}
+			if taggerName != "" {
+				newTag.Tagger = &github.CommitAuthor{Name: github.Ptr(taggerName), Email: github.Ptr(taggerEmail)}
+			}
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			tag, resp, err := client.Git.CreateTag(ctx, owner, repo, newTag)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					fmt.Sprintf("failed to create tag: %s", tagName),
+					resp,
+					err,
+				), nil
+			}
+			_ = resp.Body.Close()
+			response := convertToGitTagResponse(tag)
+
+			if createRef {
+				ref, resp, err := client.Git.CreateRef(ctx, owner, repo, github.CreateRef{
+					Ref: "refs/tags/" + tagName,
+					SHA: tag.GetSHA(),
+				})
+				if err != nil {
+					return ghErrors.NewGitHubAPIErrorResponse(ctx,
+						fmt.Sprintf("created tag object %s, but failed to create reference tags/%s", tag.GetSHA(), tagName),
+						resp,
+						err,
+					), nil
+				}
+				_ = resp.Body.Close()
+				response.Ref = ref.GetRef()
+			}
+
+			return MarshalledTextResult(response), nil
+		}
+}
@@ -0,0 +1,705 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/github/github-mcp-server/internal/toolsnaps"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetGitBlob(t *testing.T) {
+	tool, _ := GetGitBlob(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "get_git_blob", tool.Name)
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "sha"})
+
+	tests := []struct {
+		name             string
+		mockedClient     *http.Client
+		expectError      bool
+		expectedErrMsg   string
+		expectedResponse GitBlobResponse
+	}{
+		{
+			name: "text blob is decoded",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitBlobsByOwnerByRepoByFileSha,
+					expectPath(t, "/repos/owner/repo/git/blobs/abc123").andThen(
+						mockResponse(t, http.StatusOK, &github.Blob{
+							SHA:      github.Ptr("abc123"),
+							Size:     github.Ptr(6),
+							Encoding: github.Ptr("base64"),
+							Content:  github.Ptr("aGVs\nbG8K\n"),
+						}),
+					),
+				),
+			),
+			expectedResponse: GitBlobResponse{SHA: "abc123", Size: 6, Encoding: "utf-8", Content: "hello\n"},
+		},
+		{
+			name: "binary blob stays base64",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposGitBlobsByOwnerByRepoByFileSha,
+					&github.Blob{
+						SHA:      github.Ptr("abc123"),
+						Size:     github.Ptr(4),
+						Encoding: github.Ptr("base64"),
+						Content:  github.Ptr("iVBORw=="),
+					},
+				),
+			),
+			expectedResponse: GitBlobResponse{SHA: "abc123", Size: 4, Encoding: "base64", Content: "iVBORw=="},
+		},
+		{
+			name: "blob too large",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposGitBlobsByOwnerByRepoByFileSha,
+					&github.Blob{SHA: github.Ptr("abc123"), Size: github.Ptr(maxGitBlobBytes + 1)},
+				),
+			),
+			expectError:    true,
+			expectedErrMsg: "more than the 1048576 bytes",
+		},
+		{
+			name: "blob not found",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitBlobsByOwnerByRepoByFileSha,
+					mockResponse(t, http.StatusNotFound, map[string]string{"message": "Not Found"}),
+				),
+			),
+			expectError:    true,
+			expectedErrMsg: "failed to get blob: abc123",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, handler := GetGitBlob(stubGetClientFn(github.NewClient(tc.mockedClient)), translations.NullTranslationHelper)
+			result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+				"owner": "owner",
+				"repo":  "repo",
+				"sha":   "abc123",
+			}))
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			var response GitBlobResponse
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+			assert.Equal(t, tc.expectedResponse, response)
+		})
+	}
+}
+
+func Test_GetGitCommit(t *testing.T) {
+	tool, _ := GetGitCommit(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "get_git_commit", tool.Name)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "sha"})
+
+	mockedClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetReposGitCommitsByOwnerByRepoByCommitSha,
+			&github.Commit{
+				SHA:          github.Ptr("c2"),
+				Message:      github.Ptr("Fix bug"),
+				Author:       &github.CommitAuthor{Name: github.Ptr("Octocat"), Email: github.Ptr("octocat@github.com")},
+				Tree:         &github.Tree{SHA: github.Ptr("t2")},
+				Parents:      []*github.Commit{{SHA: github.Ptr("c1")}},
+				Verification: &github.SignatureVerification{Verified: github.Ptr(true)},
+			},
+		),
+	)
+	_, handler := GetGitCommit(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+	result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+		"owner": "owner",
+		"repo":  "repo",
+		"sha":   "c2",
+	}))
+	require.NoError(t, err)
+
+	var response GitCommitResponse
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+	assert.Equal(t, GitCommitResponse{
+		SHA:      "c2",
+		Message:  "Fix bug",
+		Author:   &MinimalCommitAuthor{Name: "Octocat", Email: "octocat@github.com"},
+		TreeSHA:  "t2",
+		Parents:  []string{"c1"},
+		Verified: true,
+	}, response)
+}
+
+func Test_GetGitRef(t *testing.T) {
+	tool, _ := GetGitRef(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "get_git_ref", tool.Name)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "ref"})
+
+	mockedClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposGitRefByOwnerByRepoByRef,
+			expectPath(t, "/repos/owner/repo/git/ref/tags/v1.0.0").andThen(
+				mockResponse(t, http.StatusOK, &github.Reference{
+					Ref:    github.Ptr("refs/tags/v1.0.0"),
+					Object: &github.GitObject{Type: github.Ptr("tag"), SHA: github.Ptr("tag1")},
+				}),
+			),
+		),
+	)
+	_, handler := GetGitRef(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+	result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+		"owner": "owner",
+		"repo":  "repo",
+		"ref":   "refs/tags/v1.0.0",
+	}))
+	require.NoError(t, err)
+
+	var response GitRefResponse
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+	assert.Equal(t, GitRefResponse{Ref: "refs/tags/v1.0.0", Object: GitObjectResponse{Type: "tag", SHA: "tag1"}}, response)
+}
+
+func Test_ListGitRefs(t *testing.T) {
+	tool, _ := ListGitRefs(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "list_git_refs", tool.Name)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo"})
+
+	// The ref pattern of the upstream mock does not match prefixes that contain a slash
+	mockedClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.EndpointPattern{Pattern: "/repos/{owner}/{repo}/git/matching-refs/{ref:.+}", Method: http.MethodGet},
+			expect(t, expectations{
+				path:        "/repos/owner/repo/git/matching-refs/heads/feature",
+				queryParams: map[string]string{"page": "2", "per_page": "10"},
+			}).andThen(
+				mockResponse(t, http.StatusOK, []*github.Reference{
+					{Ref: github.Ptr("refs/heads/feature-a"), Object: &github.GitObject{Type: github.Ptr("commit"), SHA: github.Ptr("a1")}},
+					{Ref: github.Ptr("refs/heads/feature-b"), Object: &github.GitObject{Type: github.Ptr("commit"), SHA: github.Ptr("b1")}},
+				}),
+			),
+		),
+	)
+	_, handler := ListGitRefs(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+	result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+		"owner":   "owner",
+		"repo":    "repo",
+		"prefix":  "heads/feature",
+		"page":    float64(2),
+		"perPage": float64(10),
+	}))
+	require.NoError(t, err)
+
+	var response []GitRefResponse
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+	require.Len(t, response, 2)
+	assert.Equal(t, "refs/heads/feature-b", response[1].Ref)
+	assert.Equal(t, "b1", response[1].Object.SHA)
+}
+
+func Test_GetGitTag(t *testing.T) {
+	tool, _ := GetGitTag(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "get_git_tag", tool.Name)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "sha"})
+
+	mockedClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetReposGitTagsByOwnerByRepoByTagSha,
+			&github.Tag{
+				SHA:     github.Ptr("tag1"),
+				Tag:     github.Ptr("v1.0.0"),
+				Message: github.Ptr("First release"),
+				Tagger:  &github.CommitAuthor{Name: github.Ptr("Octocat"), Email: github.Ptr("octocat@github.com")},
+				Object:  &github.GitObject{Type: github.Ptr("commit"), SHA: github.Ptr("c1")},
+			},
+		),
+	)
+	_, handler := GetGitTag(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+	result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+		"owner": "owner",
+		"repo":  "repo",
+		"sha":   "tag1",
+	}))
+	require.NoError(t, err)
+
+	var response GitTagResponse
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+	assert.Equal(t, "v1.0.0", response.Tag)
+	assert.Equal(t, "First release", response.Message)
+	assert.Equal(t, GitObjectResponse{Type: "commit", SHA: "c1"}, response.Object)
+}
+
+func Test_CreateGitBlob(t *testing.T) {
+	tool, _ := CreateGitBlob(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "create_git_blob", tool.Name)
+	assert.False(t, *tool.Annotations.ReadOnlyHint)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "content"})
+
+	tests := []struct {
+		name           string
+		mockedClient   *http.Client
+		requestArgs    map[string]interface{}
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "creates text blob",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitBlobsByOwnerByRepo,
+					expectRequestBody(t, map[string]any{"content": "hello\n", "encoding": "utf-8"}).andThen(
+						mockResponse(t, http.StatusCreated, &github.Blob{SHA: github.Ptr("blob1")}),
+					),
+				),
+			),
+			requestArgs: map[string]interface{}{"owner": "owner", "repo": "repo", "content": "hello\n"},
+		},
+		{
+			name: "creates binary blob",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitBlobsByOwnerByRepo,
+					expectRequestBody(t, map[string]any{"content": "iVBORw==", "encoding": "base64"}).andThen(
+						mockResponse(t, http.StatusCreated, &github.Blob{SHA: github.Ptr("blob1")}),
+					),
+				),
+			),
+			requestArgs: map[string]interface{}{"owner": "owner", "repo": "repo", "content": "iVBORw==", "encoding": "base64"},
+		},
+		{
+			name:           "invalid base64 content",
+			mockedClient:   mock.NewMockedHTTPClient(),
+			requestArgs:    map[string]interface{}{"owner": "owner", "repo": "repo", "content": "not base64!", "encoding": "base64"},
+			expectError:    true,
+			expectedErrMsg: "content is not valid base64",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, handler := CreateGitBlob(stubGetClientFn(github.NewClient(tc.mockedClient)), translations.NullTranslationHelper)
+			result, err := handler(context.Background(), createMCPRequest(tc.requestArgs))
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			var response GitObjectResponse
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+			assert.Equal(t, GitObjectResponse{Type: "blob", SHA: "blob1"}, response)
+		})
+	}
+}
+
+func Test_CreateGitTree(t *testing.T) {
+	tool, _ := CreateGitTree(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "create_git_tree", tool.Name)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "tree"})
+
+	tests := []struct {
+		name           string
+		mockedClient   *http.Client
+		tree           []interface{}
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "creates tree on base tree",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitTreesByOwnerByRepo,
+					expectRequestBody(t, map[string]any{
+						"base_tree": "base1",
+						"tree": []any{
+							map[string]any{"path": "README.md", "mode": "100644", "type": "blob", "content": "# Hello"},
+							map[string]any{"path": "bin/run", "mode": "100755", "type": "blob", "sha": "blob1"},
+							map[string]any{"path": "vendor/lib", "mode": "160000", "type": "commit", "sha": "c9"},
+							map[string]any{"path": "old.txt", "mode": "100644", "type": "blob", "sha": nil},
+						},
+					}).andThen(
+						mockResponse(t, http.StatusCreated, &github.Tree{SHA: github.Ptr("tree1")}),
+					),
+				),
+			),
+			tree: []interface{}{
+				map[string]interface{}{"path": "README.md", "content": "# Hello"},
+				map[string]interface{}{"path": "bin/run", "mode": "100755", "sha": "blob1"},
+				map[string]interface{}{"path": "vendor/lib", "type": "commit", "sha": "c9"},
+				map[string]interface{}{"path": "old.txt", "delete": true},
+			},
+		},
+		{
+			name:         "entry with both sha and content",
+			mockedClient: mock.NewMockedHTTPClient(),
+			tree: []interface{}{
+				map[string]interface{}{"path": "README.md", "sha": "blob1", "content": "# Hello"},
+			},
+			expectError:    true,
+			expectedErrMsg: "tree entry README.md must have either sha or content, not both",
+		},
+		{
+			name:         "entry without sha, content or delete",
+			mockedClient: mock.NewMockedHTTPClient(),
+			tree: []interface{}{
+				map[string]interface{}{"path": "README.md"},
+			},
+			expectError:    true,
+			expectedErrMsg: "tree entry README.md must have a sha or content, or be deleted",
+		},
+		{
+			name:         "deleted entry with content",
+			mockedClient: mock.NewMockedHTTPClient(),
+			tree: []interface{}{
+				map[string]interface{}{"path": "README.md", "delete": true, "content": "# Hello"},
+			},
+			expectError:    true,
+			expectedErrMsg: "tree entry README.md is deleted, so it takes no sha or content",
+		},
+		{
+			name:         "content for a tree entry",
+			mockedClient: mock.NewMockedHTTPClient(),
+			tree: []interface{}{
+				map[string]interface{}{"path": "docs", "type": "tree", "content": "# Hello"},
+			},
+			expectError:    true,
+			expectedErrMsg: "tree entry docs has content, so its type must be blob",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, handler := CreateGitTree(stubGetClientFn(github.NewClient(tc.mockedClient)), translations.NullTranslationHelper)
+			result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+				"owner":     "owner",
+				"repo":      "repo",
+				"base_tree": "base1",
+				"tree":      tc.tree,
+			}))
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			var response GitObjectResponse
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+			assert.Equal(t, GitObjectResponse{Type: "tree", SHA: "tree1"}, response)
+		})
+	}
+}
+
+func Test_CreateGitCommit(t *testing.T) {
+	tool, _ := CreateGitCommit(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "create_git_commit", tool.Name)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "message", "tree"})
+
+	tests := []struct {
+		name           string
+		mockedClient   *http.Client
+		requestArgs    map[string]interface{}
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "creates merge commit with author",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitCommitsByOwnerByRepo,
+					expectRequestBody(t, map[string]any{
+						"message": "Merge feature",
+						"tree":    "tree1",
+						"parents": []any{"c1", "c2"},
+						"author":  map[string]any{"name": "Octocat", "email": "octocat@github.com"},
+					}).andThen(
+						mockResponse(t, http.StatusCreated, &github.Commit{
+							SHA:     github.Ptr("c3"),
+							Message: github.Ptr("Merge feature"),
+							Tree:    &github.Tree{SHA: github.Ptr("tree1")},
+							Parents: []*github.Commit{{SHA: github.Ptr("c1")}, {SHA: github.Ptr("c2")}},
+						}),
+					),
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":        "owner",
+				"repo":         "repo",
+				"message":      "Merge feature",
+				"tree":         "tree1",
+				"parents":      []interface{}{"c1", "c2"},
+				"author_name":  "Octocat",
+				"author_email": "octocat@github.com",
+			},
+		},
+		{
+			name:         "author name without email",
+			mockedClient: mock.NewMockedHTTPClient(),
+			requestArgs: map[string]interface{}{
+				"owner":       "owner",
+				"repo":        "repo",
+				"message":     "Fix bug",
+				"tree":        "tree1",
+				"author_name": "Octocat",
+			},
+			expectError:    true,
+			expectedErrMsg: "author_name and author_email must be given together",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, handler := CreateGitCommit(stubGetClientFn(github.NewClient(tc.mockedClient)), translations.NullTranslationHelper)
+			result, err := handler(context.Background(), createMCPRequest(tc.requestArgs))
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			var response GitCommitResponse
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+			assert.Equal(t, "c3", response.SHA)
+			assert.Equal(t, []string{"c1", "c2"}, response.Parents)
+		})
+	}
+}
+
+func Test_UpdateGitRef(t *testing.T) {
+	tool, _ := UpdateGitRef(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "update_git_ref", tool.Name)
+	assert.True(t, *tool.Annotations.DestructiveHint)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "ref", "sha"})
+
+	tests := []struct {
+		name           string
+		mockedClient   *http.Client
+		force          bool
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "fast-forwards branch",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PatchReposGitRefsByOwnerByRepoByRef,
+					expect(t, expectations{
+						path:        "/repos/owner/repo/git/refs/heads/main",
+						requestBody: map[string]any{"sha": "c3", "force": false},
+					}).andThen(
+						mockResponse(t, http.StatusOK, &github.Reference{
+							Ref:    github.Ptr("refs/heads/main"),
+							Object: &github.GitObject{Type: github.Ptr("commit"), SHA: github.Ptr("c3")},
+						}),
+					),
+				),
+			),
+		},
+		{
+			name: "force updates branch",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PatchReposGitRefsByOwnerByRepoByRef,
+					expectRequestBody(t, map[string]any{"sha": "c3", "force": true}).andThen(
+						mockResponse(t, http.StatusOK, &github.Reference{
+							Ref:    github.Ptr("refs/heads/main"),
+							Object: &github.GitObject{Type: github.Ptr("commit"), SHA: github.Ptr("c3")},
+						}),
+					),
+				),
+			),
+			force: true,
+		},
+		{
+			name: "update is not a fast-forward",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PatchReposGitRefsByOwnerByRepoByRef,
+					mockResponse(t, http.StatusUnprocessableEntity, map[string]string{"message": "Update is not a fast forward"}),
+				),
+			),
+			expectError:    true,
+			expectedErrMsg: "failed to update reference: refs/heads/main",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, handler := UpdateGitRef(stubGetClientFn(github.NewClient(tc.mockedClient)), translations.NullTranslationHelper)
+			result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+				"owner": "owner",
+				"repo":  "repo",
+				"ref":   "refs/heads/main",
+				"sha":   "c3",
+				"force": tc.force,
+			}))
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			var response GitRefResponse
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+			assert.Equal(t, "c3", response.Object.SHA)
+		})
+	}
+}
+
+func Test_DeleteGitRef(t *testing.T) {
+	tool, _ := DeleteGitRef(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "delete_git_ref", tool.Name)
+	assert.True(t, *tool.Annotations.DestructiveHint)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "ref"})
+
+	mockedClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.DeleteReposGitRefsByOwnerByRepoByRef,
+			expectPath(t, "/repos/owner/repo/git/refs/heads/feature").andThen(
+				mockResponse(t, http.StatusNoContent, nil),
+			),
+		),
+	)
+	_, handler := DeleteGitRef(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+	result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+		"owner": "owner",
+		"repo":  "repo",
+		"ref":   "heads/feature",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "deleted reference heads/feature", getTextResult(t, result).Text)
+}
+
+func Test_CreateGitTag(t *testing.T) {
+	tool, _ := CreateGitTag(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "create_git_tag", tool.Name)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "tag", "message", "object"})
+
+	createdTag := &github.Tag{
+		SHA:     github.Ptr("tag1"),
+		Tag:     github.Ptr("v1.0.0"),
+		Message: github.Ptr("First release"),
+		Object:  &github.GitObject{Type: github.Ptr("commit"), SHA: github.Ptr("c1")},
+	}
+
+	tests := []struct {
+		name           string
+		mockedClient   *http.Client
+		createRef      bool
+		expectError    bool
+		expectedErrMsg string
+		expectedRef    string
+	}{
+		{
+			name: "creates tag and reference",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitTagsByOwnerByRepo,
+					expectRequestBody(t, map[string]any{
+						"tag":     "v1.0.0",
+						"message": "First release",
+						"object":  "c1",
+						"type":    "commit",
+					}).andThen(
+						mockResponse(t, http.StatusCreated, createdTag),
+					),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitRefsByOwnerByRepo,
+					expectRequestBody(t, map[string]any{"ref": "refs/tags/v1.0.0", "sha": "tag1"}).andThen(
+						mockResponse(t, http.StatusCreated, &github.Reference{Ref: github.Ptr("refs/tags/v1.0.0")}),
+					),
+				),
+			),
+			createRef:   true,
+			expectedRef: "refs/tags/v1.0.0",
+		},
+		{
+			name: "creates only tag object",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.PostReposGitTagsByOwnerByRepo, createdTag),
+			),
+		},
+		{
+			name: "tag reference already exists",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(mock.PostReposGitTagsByOwnerByRepo, createdTag),
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitRefsByOwnerByRepo,
+					mockResponse(t, http.StatusUnprocessableEntity, map[string]string{"message": "Reference already exists"}),
+				),
+			),
+			createRef:      true,
+			expectError:    true,
+			expectedErrMsg: "created tag object tag1, but failed to create reference tags/v1.0.0",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, handler := CreateGitTag(stubGetClientFn(github.NewClient(tc.mockedClient)), translations.NullTranslationHelper)
+			result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+				"owner":      "owner",
+				"repo":       "repo",
+				"tag":        "v1.0.0",
+				"message":    "First release",
+				"object":     "c1",
+				"create_ref": tc.createRef,
+			}))
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			var response GitTagResponse
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+			assert.Equal(t, "tag1", response.SHA)
+			assert.Equal(t, tc.expectedRef, response.Ref)
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strconv"
+	"testing"
+
+	"github.com/github/github-mcp-server/internal/githubv4mock"
+	"github.com/github/github-mcp-server/internal/toolsnaps"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetFileBlame(t *testing.T) {
+	// Verify tool definition once
+	tool, _ := GetFileBlame(stubGetGQLClientFn(githubv4.NewClient(nil)), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "get_file_blame", tool.Name)
+	assert.NotEmpty(t, tool.Description)
+	assert.Contains(t, tool.InputSchema.Properties, "ref")
+	assert.Contains(t, tool.InputSchema.Properties, "start_line")
+	assert.Contains(t, tool.InputSchema.Properties, "end_line")
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "path"})
+
+	blameRange := func(start, end int, oid, headline, login string, prs ...map[string]any) map[string]any {
+		author := map[string]any{"name": "Mona", "email": "mona@example.com", "user": nil}
+		if login != "" {
+			author["user"] = map[string]any{"login": login}
+		}
+		return map[string]any{
+			"startingLine": start,
+			"endingLine":   end,
+			"commit": map[string]any{
+				"oid":                    oid,
+				"messageHeadline":        headline,
+				"committedDate":          "2025-03-01T12:00:00+02:00",
+				"url":                    "https://github.com/owner/repo/commit/" + oid,
+				"author":                 author,
+				"associatedPullRequests": map[string]any{"nodes": prs},
+			},
+		}
+	}
+	blameMatcher := func(ref string, oid string, ranges ...map[string]any) githubv4mock.Matcher {
+		return githubv4mock.NewQueryMatcher(
+			blameQuery{},
+			map[string]any{
+				"owner": githubv4.String("owner"),
+				"repo":  githubv4.String("repo"),
+				"ref":   githubv4.String(ref),
+				"path":  githubv4.String("src/main.go"),
+			},
+			githubv4mock.DataResponse(map[string]any{
+				"repository": map[string]any{
+					"object": map[string]any{
+						"oid":   oid,
+						"blame": map[string]any{"ranges": ranges},
+					},
+				},
+			}),
+		)
+	}
+	ranges := []map[string]any{
+		blameRange(1, 4, "aaa111", "Initial commit", "mona"),
+		blameRange(5, 6, "bbb222", "Handle errors", "hubot", map[string]any{"number": 42, "title": "Better errors", "url": "https://github.com/owner/repo/pull/42"}),
+		blameRange(7, 10, "aaa111", "Initial commit", ""),
+	}
+
+	tests := []struct {
+		name           string
+		mockedClient   *http.Client
+		requestArgs    map[string]any
+		expectError    bool
+		expectedErrMsg string
+		expectedHunks  []BlameHunk
+	}{
+		{
+			name:         "blames lines in range",
+			mockedClient: githubv4mock.NewMockedHTTPClient(blameMatcher("main", "head999", ranges...)),
+			requestArgs: map[string]any{
+				"owner":      "owner",
+				"repo":       "repo",
+				"path":       "src/main.go",
+				"ref":        "main",
+				"start_line": float64(3),
+				"end_line":   float64(5),
+			},
+			expectedHunks: []BlameHunk{
+				{StartLine: 3, EndLine: 4, Commit: &BlameCommit{
+					SHA: "aaa111", Message: "Initial commit", AuthorName: "Mona", AuthorEmail: "mona@example.com", AuthorLogin: "mona",
+					Date: "2025-03-01T10:00:00Z", URL: "https://github.com/owner/repo/commit/aaa111",
+				}},
+				{StartLine: 5, EndLine: 5, Commit: &BlameCommit{
+					SHA: "bbb222", Message: "Handle errors", AuthorName: "Mona", AuthorEmail: "mona@example.com", AuthorLogin: "hubot",
+					Date: "2025-03-01T10:00:00Z", URL: "https://github.com/owner/repo/commit/bbb222",
+					PullRequest: &BlamePRInfo{Number: 42, Title: "Better errors", URL: "https://github.com/owner/repo/pull/42"},
+				}},
+			},
+		},
+		{
+			name:         "defaults to HEAD",
+			mockedClient: githubv4mock.NewMockedHTTPClient(blameMatcher("HEAD", "head999", ranges[2])),
+			requestArgs: map[string]any{
+				"owner": "owner",
+				"repo":  "repo",
+				"path":  "src/main.go",
+			},
+			expectedHunks: []BlameHunk{
+				{StartLine: 7, EndLine: 10, Commit: &BlameCommit{
+					SHA: "aaa111", Message: "Initial commit", AuthorName: "Mona", AuthorEmail: "mona@example.com",
+					Date: "2025-03-01T10:00:00Z", URL: "https://github.com/owner/repo/commit/aaa111",
+				}},
+			},
+		},
+		{
+			name:         "lines beyond the file",
+			mockedClient: githubv4mock.NewMockedHTTPClient(blameMatcher("main", "head999", ranges...)),
+			requestArgs: map[string]any{
+				"owner":      "owner",
+				"repo":       "repo",
+				"path":       "src/main.go",
+				"ref":        "main",
+				"start_line": float64(20),
+			},
+			expectError:    true,
+			expectedErrMsg: "src/main.go has no lines in the requested range",
+		},
+		{
+			name:         "unknown ref",
+			mockedClient: githubv4mock.NewMockedHTTPClient(blameMatcher("missing", "")),
+			requestArgs: map[string]any{
+				"owner": "owner",
+				"repo":  "repo",
+				"path":  "src/main.go",
+				"ref":   "missing",
+			},
+			expectError:    true,
+			expectedErrMsg: "ref missing not found in owner/repo",
+		},
+		{
+			name:         "invalid line range",
+			mockedClient: githubv4mock.NewMockedHTTPClient(),
+			requestArgs: map[string]any{
+				"owner":      "owner",
+				"repo":       "repo",
+				"path":       "src/main.go",
+				"start_line": float64(5),
+				"end_line":   float64(2),
+			},
+			expectError:    true,
+			expectedErrMsg: "end_line must not be before start_line",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, handler := GetFileBlame(stubGetGQLClientFn(githubv4.NewClient(tc.mockedClient)), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
+
+			result, err := handler(context.Background(), createMCPRequest(tc.requestArgs))
+			require.NoError(t, err)
+
+			if tc.expectError {
+				assert.Contains(t, getErrorResult(t, result).Text, tc.expectedErrMsg)
+				return
+			}
+
+			var blame struct {
+				BlameResponse
+				Hunks []BlameHunk `json:"hunks"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &blame))
+			assert.Equal(t, "head999", blame.SHA)
+			assert.Equal(t, tc.expectedHunks, blame.Hunks)
+		})
+	}
+}
+
+func Test_GetFileHistory(t *testing.T) {
+	// Verify tool definition once
+	tool, _ := GetFileHistory(stubGetClientFn(github.NewClient(nil)), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "get_file_history", tool.Name)
+	assert.NotEmpty(t, tool.Description)
+	assert.Contains(t, tool.InputSchema.Properties, "path")
+	assert.Contains(t, tool.InputSchema.Properties, "follow_renames")
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "path"})
+
+	commit := func(sha, message, parent string) *github.RepositoryCommit {
+		c := &github.RepositoryCommit{SHA: github.Ptr(sha), Commit: &github.Commit{Message: github.Ptr(message)}}
+		if parent != "" {
+			c.Parents = []*github.Commit{{SHA: github.Ptr(parent)}}
+		}
+		return c
+	}
+	// docs/guide.md was renamed from guide.md in ccc333
+	commitsByPath := map[string][]*github.RepositoryCommit{
+		"docs/guide.md": {commit("eee555", "Fix typo", "ddd444"), commit("ccc333", "Move guide", "bbb222")},
+		"guide.md":      {commit("bbb222", "Expand guide", "aaa111"), commit("aaa111", "Add guide", "")},
+	}
+	listCommits := mock.WithRequestMatchHandler(
+		mock.GetReposCommitsByOwnerByRepo,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			commits := commitsByPath[r.URL.Query().Get("path")]
+			if r.URL.Query().Get("path") == "guide.md" {
+				assert.Equal(t, "bbb222", r.URL.Query().Get("sha"), "history continues from the parent of the rename")
+			}
+			perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+			require.NoError(t, err)
+			mockResponse(t, http.StatusOK, commits[:min(perPage, len(commits))])(w, r)
+		}),
+	)
+	getCommit := mock.WithRequestMatchHandler(
+		mock.GetReposCommitsByOwnerByRepoByRef,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sha := path.Base(r.URL.Path)
+			file := &github.CommitFile{Filename: github.Ptr("guide.md"), Status: github.Ptr("modified")}
+			if sha == "ccc333" {
+				file = &github.CommitFile{Filename: github.Ptr("docs/guide.md"), PreviousFilename: github.Ptr("guide.md"), Status: github.Ptr("renamed")}
+			}
+			mockResponse(t, http.StatusOK, &github.RepositoryCommit{SHA: github.Ptr(sha), Files: []*github.CommitFile{file}})(w, r)
+		}),
+	)
+
+	tests := []struct {
+		name             string
+		requestArgs      map[string]any
+		expectedCommits  []string
+		expectedPaths    []string
+		expectedRenames  []FileRename
+		expectedNextSHA  string
+		expectedNextPath string
+	}{
+		{
+			name:            "follows renames",
+			requestArgs:     map[string]any{"owner": "owner", "repo": "repo", "path": "docs/guide.md"},
+			expectedCommits: []string{"eee555", "ccc333", "bbb222", "aaa111"},
+			expectedPaths:   []string{"docs/guide.md", "docs/guide.md", "guide.md", "guide.md"},
+			expectedRenames: []FileRename{{SHA: "ccc333", From: "guide.md", To: "docs/guide.md"}},
+		},
+		{
+			name:            "without following renames",
+			requestArgs:     map[string]any{"owner": "owner", "repo": "repo", "path": "docs/guide.md", "follow_renames": false},
+			expectedCommits: []string{"eee555", "ccc333"},
+			expectedPaths:   []string{"docs/guide.md", "docs/guide.md"},
+		},
+		{
+			name:             "limited history can be continued",
+			requestArgs:      map[string]any{"owner": "owner", "repo": "repo", "path": "docs/guide.md", "perPage": float64(3)},
+			expectedCommits:  []string{"eee555", "ccc333", "bbb222"},
+			expectedPaths:    []string{"docs/guide.md", "docs/guide.md", "guide.md"},
+			expectedRenames:  []FileRename{{SHA: "ccc333", From: "guide.md", To: "docs/guide.md"}},
+			expectedNextSHA:  "aaa111",
+			expectedNextPath: "guide.md",
+		},
+		{
+			name:             "page ending on the rename continues under the previous path",
+			requestArgs:      map[string]any{"owner": "owner", "repo": "repo", "path": "docs/guide.md", "perPage": float64(2)},
+			expectedCommits:  []string{"eee555", "ccc333"},
+			expectedPaths:    []string{"docs/guide.md", "docs/guide.md"},
+			expectedRenames:  []FileRename{{SHA: "ccc333", From: "guide.md", To: "docs/guide.md"}},
+			expectedNextSHA:  "bbb222",
+			expectedNextPath: "guide.md",
+		},
+		{
+			name:            "continuation after a rename on a page boundary",
+			requestArgs:     map[string]any{"owner": "owner", "repo": "repo", "path": "guide.md", "sha": "bbb222"},
+			expectedCommits: []string{"bbb222", "aaa111"},
+			expectedPaths:   []string{"guide.md", "guide.md"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := github.NewClient(mock.NewMockedHTTPClient(listCommits, getCommit))
+			_, handler := GetFileHistory(stubGetClientFn(client), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
+
+			result, err := handler(context.Background(), createMCPRequest(tc.requestArgs))
+			require.NoError(t, err)
+
+			var history struct {
+				FileHistoryResponse
+				Commits []FileHistoryCommit `json:"commits"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &history))
+			var shas, paths []string
+			for _, c := range history.Commits {
+				shas = append(shas, c.SHA)
+				paths = append(paths, c.Path)
+			}
+			assert.Equal(t, tc.expectedCommits, shas)
+			assert.Equal(t, tc.expectedPaths, paths)
+			assert.Equal(t, tc.expectedRenames, history.Renames)
+			assert.Equal(t, tc.expectedNextSHA, history.NextSHA)
+			assert.Equal(t, tc.expectedNextPath, history.NextPath)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package github
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// graphQLOperationTypes returns the type of every definition in a GraphQL document: query, mutation,
+// subscription or fragment. Anonymous operations written as a bare selection set are queries.
+func graphQLOperationTypes(document string) ([]string, error) {
+	var types []string
+	var brackets []byte
+	expectDefinition := true
+	for i := 0; i < len(document); {
+		c := document[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			end := strings.IndexAny(document[i:], "\r\n")
+			if end < 0 {
+				end = len(document) - i
+			}
+			i += end
+		case strings.HasPrefix(document[i:], `"""`):
+			end := strings.Index(strings.ReplaceAll(document[i+3:], `\"""`, "...."), `"""`)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated block string")
+			}
+			i += end + 6
+		case c == '"':
+			i++
+			for i < len(document) && document[i] != '"' && document[i] != '\n' {
+				if document[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(document) || document[i] != '"' {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+		case c == '{' || c == '(' || c == '[':
+			if len(brackets) == 0 && expectDefinition {
+				if c != '{' {
+					return nil, fmt.Errorf("unexpected %q at the start of a definition", c)
+				}
+				types = append(types, "query")
+				expectDefinition = false
+			}
+			brackets = append(brackets, c)
+			i++
+		case c == '}' || c == ')' || c == ']':
+			if len(brackets) == 0 || strings.IndexByte("{([", brackets[len(brackets)-1]) != strings.IndexByte("})]", c) {
+				return nil, fmt.Errorf("unbalanced %q", c)
+			}
+			brackets = brackets[:len(brackets)-1]
+			// A definition ends with its top-level selection set
+			expectDefinition = len(brackets) == 0 && c == '}'
+			i++
+		case c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+			start := i
+			for i < len(document) && (document[i] == '_' || document[i] >= 'A' && document[i] <= 'Z' || document[i] >= 'a' && document[i] <= 'z' || document[i] >= '0' && document[i] <= '9') {
+				i++
+			}
+			if len(brackets) == 0 && expectDefinition {
+				switch name := document[start:i]; name {
+				case "query", "mutation", "subscription", "fragment":
+					types = append(types, name)
+					expectDefinition = false
+				default:
+					return nil, fmt.Errorf("unexpected %q at the start of a definition", name)
+				}
+			}
+		default:
+			i++
+		}
+	}
+	if len(brackets) > 0 {
+		return nil, fmt.Errorf("unbalanced %q", brackets[len(brackets)-1])
+	}
+	return types, nil
+}
+
+// containsGraphQLMutation reports whether a GraphQL document has a mutation operation. Documents that cannot
+// be parsed are assumed to have one.
+func containsGraphQLMutation(document string) bool {
+	types, err := graphQLOperationTypes(document)
+	if err != nil {
+		return true
+	}
+	return slices.Contains(types, "mutation")
+}
@@ -0,0 +1,71 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GraphQLOperationTypes(t *testing.T) {
+	tests := []struct {
+		name          string
+		document      string
+		expectedTypes []string
+		expectedErr   bool
+	}{
+		{
+			name:          "named query",
+			document:      "query($owner: String!) { repository(owner: $owner, name: \"x\") { stargazerCount } }",
+			expectedTypes: []string{"query"},
+		},
+		{
+			name:          "anonymous query",
+			document:      "{ viewer { login } }",
+			expectedTypes: []string{"query"},
+		},
+		{
+			name:          "mutation after a fragment on the same line",
+			document:      "fragment F on Repository { id } mutation { addStar(input: {starrableId: \"x\"}) { clientMutationId } }",
+			expectedTypes: []string{"fragment", "mutation"},
+		},
+		{
+			name:          "keywords in strings and comments",
+			document:      "# mutation {\nquery { search(query: \"mutation { }\", type: REPOSITORY, first: 1) { repositoryCount } }\n\"\"\"mutation\"\"\"",
+			expectedTypes: []string{"query"},
+		},
+		{
+			name:          "default values and directives",
+			document:      "query Q($filter: IssueFilters = {states: [OPEN]}) @cached { viewer { login } } subscription { x }",
+			expectedTypes: []string{"query", "subscription"},
+		},
+		{
+			name:        "unbalanced braces",
+			document:    "query { viewer { login }",
+			expectedErr: true,
+		},
+		{
+			name:        "unknown definition",
+			document:    "schema { query: Query }",
+			expectedErr: true,
+		},
+		{
+			name:        "unterminated string",
+			document:    "query { search(query: \"x) { id } }",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			types, err := graphQLOperationTypes(tc.document)
+			if tc.expectedErr {
+				require.Error(t, err)
+				assert.True(t, containsGraphQLMutation(tc.document))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTypes, types)
+		})
+	}
+}
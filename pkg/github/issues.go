@@ -325,22 +325,6 @@ func GetIssue(ctx context.Context, client *github.Client, cache *lockdown.RepoAc
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get issue: %s", string(body))), nil
 	}
 
-	if flags.LockdownMode {
-		if cache == nil {
-			return nil, fmt.Errorf("lockdown cache is not configured")
-		}
-		login := issue.GetUser().GetLogin()
-		if login != "" {
-			isSafeContent, err := cache.IsSafeContent(ctx, login, owner, repo)
-			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
-			}
-			if !isSafeContent {
-				return mcp.NewToolResultError("access to issue details is restricted by lockdown mode"), nil
-			}
-		}
-	}
-
 	// Sanitize title/body on response
 	if issue != nil {
 		if issue.Title != nil {
@@ -351,7 +335,15 @@ func GetIssue(ctx context.Context, client *github.Client, cache *lockdown.RepoAc
 		}
 	}
 
-	r, err := json.Marshal(issue)
+	output, err := applyLockdownItem(ctx, cache, flags, issue, contentOrigin{Login: issue.GetUser().GetLogin(), Owner: owner, Repo: repo}, redactIssue)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+	}
+	if output == nil {
+		return mcp.NewToolResultError("access to issue details is restricted by lockdown mode"), nil
+	}
+
+	r, err := json.Marshal(output)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal issue: %w", err)
 	}
@@ -380,32 +372,14 @@ func GetIssueComments(ctx context.Context, client *github.Client, cache *lockdow
 		}
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get issue comments: %s", string(body))), nil
 	}
-	if flags.LockdownMode {
-		if cache == nil {
-			return nil, fmt.Errorf("lockdown cache is not configured")
-		}
-		filteredComments := make([]*github.IssueComment, 0, len(comments))
-		for _, comment := range comments {
-			user := comment.User
-			if user == nil {
-				continue
-			}
-			login := user.GetLogin()
-			if login == "" {
-				continue
-			}
-			isSafeContent, err := cache.IsSafeContent(ctx, login, owner, repo)
-			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
-			}
-			if isSafeContent {
-				filteredComments = append(filteredComments, comment)
-			}
-		}
-		comments = filteredComments
+	output, err := applyLockdown(ctx, cache, flags, comments, func(comment *github.IssueComment) contentOrigin {
+		return contentOrigin{Login: comment.GetUser().GetLogin(), Owner: owner, Repo: repo}
+	}, redactIssueComment)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
 	}
 
-	r, err := json.Marshal(comments)
+	r, err := json.Marshal(output)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal response: %w", err)
 	}
@@ -440,32 +414,14 @@ func GetSubIssues(ctx context.Context, client *github.Client, cache *lockdown.Re
 		return mcp.NewToolResultError(fmt.Sprintf("failed to list sub-issues: %s", string(body))), nil
 	}
 
-	if featureFlags.LockdownMode {
-		if cache == nil {
-			return nil, fmt.Errorf("lockdown cache is not configured")
-		}
-		filteredSubIssues := make([]*github.SubIssue, 0, len(subIssues))
-		for _, subIssue := range subIssues {
-			user := subIssue.User
-			if user == nil {
-				continue
-			}
-			login := user.GetLogin()
-			if login == "" {
-				continue
-			}
-			isSafeContent, err := cache.IsSafeContent(ctx, login, owner, repo)
-			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
-			}
-			if isSafeContent {
-				filteredSubIssues = append(filteredSubIssues, subIssue)
-			}
-		}
-		subIssues = filteredSubIssues
+	output, err := applyLockdown(ctx, cache, featureFlags, subIssues, func(subIssue *github.SubIssue) contentOrigin {
+		return contentOrigin{Login: subIssue.User.GetLogin(), Owner: owner, Repo: repo}
+	}, redactSubIssue)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
 	}
 
-	r, err := json.Marshal(subIssues)
+	r, err := json.Marshal(output)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal response: %w", err)
 	}
@@ -860,7 +816,7 @@ func ReprioritizeSubIssue(ctx context.Context, client *github.Client, owner stri
 }
 
 // SearchIssues creates a tool to search for issues.
-func SearchIssues(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func SearchIssues(getClient GetClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("search_issues",
 			mcp.WithDescription(t("TOOL_SEARCH_ISSUES_DESCRIPTION", "Search for issues in GitHub repositories using issues search syntax already scoped to is:issue")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -900,7 +856,7 @@ func SearchIssues(getClient GetClientFn, t translations.TranslationHelperFunc) (
 			WithPagination(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			return searchHandler(ctx, getClient, request, "issue", "failed to search issues")
+			return searchHandler(ctx, getClient, cache, flags, request, "issue", "failed to search issues")
 		}
 }
 
@@ -1245,7 +1201,7 @@ func UpdateIssue(ctx context.Context, client *github.Client, gqlClient *githubv4
 }
 
 // ListIssues creates a tool to list and filter repository issues
-func ListIssues(getGQLClient GetGQLClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func ListIssues(getGQLClient GetGQLClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("list_issues",
 			mcp.WithDescription(t("TOOL_LIST_ISSUES_DESCRIPTION", "List issues in a GitHub repository. For pagination, use the 'endCursor' from the previous response's 'pageInfo' in the 'after' parameter.")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -1437,9 +1393,16 @@ func ListIssues(getGQLClient GetGQLClientFn, t translations.TranslationHelperFun
 				totalCount = fragment.TotalCount
 			}
 
+			output, err := applyLockdown(ctx, cache, flags, issues, func(issue *github.Issue) contentOrigin {
+				return contentOrigin{Login: issue.GetUser().GetLogin(), Owner: owner, Repo: repo}
+			}, redactIssue)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+			}
+
 			// Create response with issues
 			response := map[string]interface{}{
-				"issues": issues,
+				"issues": output,
 				"pageInfo": map[string]interface{}{
 					"hasNextPage":     pageInfo.HasNextPage,
 					"hasPreviousPage": pageInfo.HasPreviousPage,
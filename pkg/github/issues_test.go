@@ -43,7 +43,9 @@ type repoAccessMockTransport struct {
 func newRepoAccessHTTPClient() *http.Client {
 	responses := map[repoAccessKey]repoAccessValue{
 		{owner: "owner2", repo: "repo2", username: "testuser2"}: {isPrivate: true},
-		{owner: "owner", repo: "repo", username: "testuser"}:    {isPrivate: false, permission: "READ"},
+		// An empty username marks the repository as private for queries without users
+		{owner: "owner2", repo: "repo2", username: ""}:       {isPrivate: true},
+		{owner: "owner", repo: "repo", username: "testuser"}: {isPrivate: false, permission: "READ"},
 	}
 
 	return &http.Client{Transport: &repoAccessMockTransport{responses: responses}}
@@ -66,31 +68,39 @@ func (rt *repoAccessMockTransport) RoundTrip(req *http.Request) (*http.Response,
 
 	owner := toString(payload.Variables["owner"])
 	repo := toString(payload.Variables["name"])
-	username := toString(payload.Variables["username"])
 
-	value, ok := rt.responses[repoAccessKey{owner: owner, repo: repo, username: username}]
-	if !ok {
-		value = repoAccessValue{isPrivate: false, permission: "WRITE"}
-	}
+	// Users are looked up in batches, through the user0, user1, ... variables
+	repository := map[string]any{"isPrivate": rt.responses[repoAccessKey{owner: owner, repo: repo}].isPrivate}
+	for i := 0; ; i++ {
+		alias := fmt.Sprintf("user%d", i)
+		username, ok := payload.Variables[alias]
+		if !ok {
+			break
+		}
 
-	edges := []any{}
-	if value.permission != "" {
-		edges = append(edges, map[string]any{
-			"permission": value.permission,
-			"node": map[string]any{
-				"login": username,
-			},
-		})
+		value, ok := rt.responses[repoAccessKey{owner: owner, repo: repo, username: toString(username)}]
+		if !ok {
+			value = repoAccessValue{isPrivate: false, permission: "WRITE"}
+		}
+		if value.isPrivate {
+			repository["isPrivate"] = true
+		}
+
+		edges := []any{}
+		if value.permission != "" {
+			edges = append(edges, map[string]any{
+				"permission": value.permission,
+				"node": map[string]any{
+					"login": toString(username),
+				},
+			})
+		}
+		repository[alias] = map[string]any{"edges": edges}
 	}
 
 	responseBody, err := json.Marshal(map[string]any{
 		"data": map[string]any{
-			"repository": map[string]any{
-				"isPrivate": value.isPrivate,
-				"collaborators": map[string]any{
-					"edges": edges,
-				},
-			},
+			"repository": repository,
 		},
 	})
 	if err != nil {
@@ -483,7 +493,7 @@ func Test_AddIssueComment(t *testing.T) {
 func Test_SearchIssues(t *testing.T) {
 	// Verify tool definition once
 	mockClient := github.NewClient(nil)
-	tool, _ := SearchIssues(stubGetClientFn(mockClient), translations.NullTranslationHelper)
+	tool, _ := SearchIssues(stubGetClientFn(mockClient), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 	require.NoError(t, toolsnaps.Test(tool.Name, tool))
 
 	assert.Equal(t, "search_issues", tool.Name)
@@ -772,7 +782,7 @@ func Test_SearchIssues(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Setup client with mock
 			client := github.NewClient(tc.mockedClient)
-			_, handler := SearchIssues(stubGetClientFn(client), translations.NullTranslationHelper)
+			_, handler := SearchIssues(stubGetClientFn(client), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 
 			// Create call request
 			request := createMCPRequest(tc.requestArgs)
@@ -975,7 +985,7 @@ func Test_CreateIssue(t *testing.T) {
 func Test_ListIssues(t *testing.T) {
 	// Verify tool definition
 	mockClient := githubv4.NewClient(nil)
-	tool, _ := ListIssues(stubGetGQLClientFn(mockClient), translations.NullTranslationHelper)
+	tool, _ := ListIssues(stubGetGQLClientFn(mockClient), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 	require.NoError(t, toolsnaps.Test(tool.Name, tool))
 
 	assert.Equal(t, "list_issues", tool.Name)
@@ -1236,7 +1246,7 @@ func Test_ListIssues(t *testing.T) {
 			}
 
 			gqlClient := githubv4.NewClient(httpClient)
-			_, handler := ListIssues(stubGetGQLClientFn(gqlClient), translations.NullTranslationHelper)
+			_, handler := ListIssues(stubGetGQLClientFn(gqlClient), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 
 			req := createMCPRequest(tc.reqParams)
 			res, err := handler(context.Background(), req)
@@ -184,8 +184,9 @@ func LabelWrite(getGQLClient GetGQLClientFn, t translations.TranslationHelperFun
 			"label_write",
 			mcp.WithDescription(t("TOOL_LABEL_WRITE_DESCRIPTION", "Perform write operations on repository labels. To set labels on issues, use the 'update_issue' tool.")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
-				Title:        t("TOOL_LABEL_WRITE_TITLE", "Write operations on repository labels."),
-				ReadOnlyHint: ToBoolPtr(false),
+				Title:           t("TOOL_LABEL_WRITE_TITLE", "Write operations on repository labels."),
+				ReadOnlyHint:    ToBoolPtr(false),
+				DestructiveHint: ToBoolPtr(true),
 			}),
 			mcp.WithString("method",
 				mcp.Required(),
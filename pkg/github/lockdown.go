@@ -0,0 +1,236 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/google/go-github/v79/github"
+)
+
+// contentOrigin identifies who wrote a piece of content and in which repository.
+type contentOrigin struct {
+	Login string
+	Owner string
+	Repo  string
+}
+
+// redactFunc replaces the author-written text fields of an item with placeholder.
+type redactFunc[T any] func(item T, placeholder string)
+
+// lockdownTrust determines the trust in the author of a piece of content. In lockdown mode, content without
+// a known author is external, as its origin cannot be verified, and is only safe in a private repository whose
+// content the trust policy does not filter. Content from an unknown repository is never safe.
+func lockdownTrust(ctx context.Context, cache *lockdown.RepoAccessCache, origin contentOrigin) (lockdown.ContentTrust, error) {
+	if cache == nil {
+		return lockdown.ContentTrust{}, fmt.Errorf("lockdown cache is not configured")
+	}
+	if origin.Owner == "" || origin.Repo == "" {
+		return lockdown.ContentTrust{Level: lockdown.TrustLevelExternal}, nil
+	}
+	return cache.GetContentTrust(ctx, origin.Login, origin.Owner, origin.Repo)
+}
+
+// lockdownTrustBatch determines the trust in the authors of many pieces of content, with one cache lookup per
+// repository so that all authors missing from the cache are resolved together.
+func lockdownTrustBatch(ctx context.Context, cache *lockdown.RepoAccessCache, origins []contentOrigin) ([]lockdown.ContentTrust, error) {
+	if cache == nil {
+		return nil, fmt.Errorf("lockdown cache is not configured")
+	}
+
+	type repoKey struct{ owner, repo string }
+	loginsByRepo := map[repoKey][]string{}
+	for _, origin := range origins {
+		// Unknown authors are looked up too, as the repository may be private
+		if origin.Owner == "" || origin.Repo == "" {
+			continue
+		}
+		key := repoKey{strings.ToLower(origin.Owner), strings.ToLower(origin.Repo)}
+		loginsByRepo[key] = append(loginsByRepo[key], origin.Login)
+	}
+
+	trustsByRepo := make(map[repoKey]map[string]lockdown.ContentTrust, len(loginsByRepo))
+	for key, logins := range loginsByRepo {
+		trusts, err := cache.GetContentTrustBatch(ctx, key.owner, key.repo, logins)
+		if err != nil {
+			return nil, err
+		}
+		trustsByRepo[key] = trusts
+	}
+
+	result := make([]lockdown.ContentTrust, len(origins))
+	for i, origin := range origins {
+		trust, ok := trustsByRepo[repoKey{strings.ToLower(origin.Owner), strings.ToLower(origin.Repo)}][strings.ToLower(origin.Login)]
+		if !ok {
+			trust = lockdown.ContentTrust{Level: lockdown.TrustLevelExternal}
+		}
+		result[i] = trust
+	}
+	return result, nil
+}
+
+// applyLockdown prepares items for output. They are returned unchanged when lockdown mode is disabled.
+// In lockdown mode every item is tagged with the trust level of its author. Items that are not safe are
+// dropped, or with FeatureFlags.LockdownRedact, kept with their text replaced by a placeholder so that
+// the structure of a thread is preserved.
+func applyLockdown[T any](ctx context.Context, cache *lockdown.RepoAccessCache, flags FeatureFlags, items []T, origin func(T) contentOrigin, redact redactFunc[T]) ([]any, error) {
+	if items == nil {
+		return nil, nil
+	}
+	result := make([]any, 0, len(items))
+	if !flags.LockdownMode {
+		for _, item := range items {
+			result = append(result, item)
+		}
+		return result, nil
+	}
+
+	origins := make([]contentOrigin, len(items))
+	for i, item := range items {
+		origins[i] = origin(item)
+	}
+	trusts, err := lockdownTrustBatch(ctx, cache, origins)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		out, err := lockdownOutput(flags, item, origins[i], trusts[i], redact)
+		if err != nil {
+			return nil, err
+		}
+		if out != nil {
+			result = append(result, out)
+		}
+	}
+	return result, nil
+}
+
+// applyLockdownItem prepares a single item for output like applyLockdown. It returns nil when the item
+// must be withheld entirely.
+func applyLockdownItem[T any](ctx context.Context, cache *lockdown.RepoAccessCache, flags FeatureFlags, item T, origin contentOrigin, redact redactFunc[T]) (any, error) {
+	if !flags.LockdownMode {
+		return item, nil
+	}
+	trust, err := lockdownTrust(ctx, cache, origin)
+	if err != nil {
+		return nil, err
+	}
+	return lockdownOutput(flags, item, origin, trust, redact)
+}
+
+// lockdownOutput tags item with the trust in its author, redacting or withholding it when it is not safe.
+func lockdownOutput[T any](flags FeatureFlags, item T, origin contentOrigin, trust lockdown.ContentTrust, redact redactFunc[T]) (any, error) {
+	redacted := false
+	if !trust.Safe {
+		if !flags.LockdownRedact {
+			return nil, nil
+		}
+		redact(item, lockdownPlaceholder(origin))
+		redacted = true
+	}
+	return withTrustLevel(item, trust.Level, redacted)
+}
+
+func lockdownPlaceholder(origin contentOrigin) string {
+	if origin.Login == "" {
+		return "[Content withheld by lockdown mode: the author is unknown]"
+	}
+	return fmt.Sprintf("[Content withheld by lockdown mode: %s is not a trusted author in %s/%s]", origin.Login, origin.Owner, origin.Repo)
+}
+
+// withTrustLevel converts item to a JSON object with the trust_level field and, if it was redacted, the redacted field.
+func withTrustLevel(item any, level lockdown.TrustLevel, redacted bool) (map[string]any, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal item: %w", err)
+	}
+	// Decode numbers as json.Number so that large IDs keep their precision
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tagged map[string]any
+	if err := decoder.Decode(&tagged); err != nil {
+		return nil, fmt.Errorf("failed to tag item with trust level: %w", err)
+	}
+	tagged["trust_level"] = level
+	if redacted {
+		tagged["redacted"] = true
+	}
+	return tagged, nil
+}
+
+func redactIssue(issue *github.Issue, placeholder string) {
+	issue.Title = github.Ptr(placeholder)
+	issue.Body = github.Ptr(placeholder)
+}
+
+func redactSubIssue(subIssue *github.SubIssue, placeholder string) {
+	subIssue.Title = github.Ptr(placeholder)
+	subIssue.Body = github.Ptr(placeholder)
+}
+
+func redactIssueComment(comment *github.IssueComment, placeholder string) {
+	comment.Body = github.Ptr(placeholder)
+}
+
+func redactPullRequest(pr *github.PullRequest, placeholder string) {
+	pr.Title = github.Ptr(placeholder)
+	pr.Body = github.Ptr(placeholder)
+}
+
+func redactPullRequestComment(comment *github.PullRequestComment, placeholder string) {
+	comment.Body = github.Ptr(placeholder)
+}
+
+func redactPullRequestReview(review *github.PullRequestReview, placeholder string) {
+	review.Body = github.Ptr(placeholder)
+}
+
+func redactDiscussion(discussion *github.Discussion, placeholder string) {
+	discussion.Title = github.Ptr(placeholder)
+	discussion.Body = github.Ptr(placeholder)
+}
+
+func redactNotification(notification *github.Notification, placeholder string) {
+	if notification.Subject != nil {
+		notification.Subject.Title = github.Ptr(placeholder)
+	}
+}
+
+func redactMinimalCommit(commit MinimalCommit, placeholder string) {
+	if commit.Commit != nil {
+		commit.Commit.Message = placeholder
+	}
+}
+
+func redactBlameHunk(hunk BlameHunk, placeholder string) {
+	hunk.Commit.Message = placeholder
+	if hunk.Commit.PullRequest != nil {
+		hunk.Commit.PullRequest.Title = placeholder
+	}
+}
+
+func redactRelease(release *github.RepositoryRelease, placeholder string) {
+	release.Name = github.Ptr(placeholder)
+	release.Body = github.Ptr(placeholder)
+}
+
+// repoFromAPIURL extracts the owner and name of the repository from a REST API URL such as
+// https://api.github.com/repos/owner/repo/issues/1. Empty strings are returned when the URL does not
+// point into a repository.
+func repoFromAPIURL(apiURL string) (owner, repo string) {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return "", ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+2 < len(parts); i++ {
+		if parts[i] == "repos" {
+			return parts[i+1], parts[i+2]
+		}
+	}
+	return "", ""
+}
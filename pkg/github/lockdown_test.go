@@ -0,0 +1,178 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_repoFromAPIURL(t *testing.T) {
+	tests := []struct {
+		url           string
+		expectedOwner string
+		expectedRepo  string
+	}{
+		{url: "https://api.github.com/repos/owner/repo", expectedOwner: "owner", expectedRepo: "repo"},
+		{url: "https://api.github.com/repos/owner/repo/issues/42", expectedOwner: "owner", expectedRepo: "repo"},
+		{url: "https://ghes.example.com/api/v3/repos/owner/repo/pulls/1", expectedOwner: "owner", expectedRepo: "repo"},
+		{url: "https://api.github.com/users/octocat"},
+		{url: "https://api.github.com/repos/owner"},
+		{url: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.url, func(t *testing.T) {
+			owner, repo := repoFromAPIURL(tc.url)
+			assert.Equal(t, tc.expectedOwner, owner)
+			assert.Equal(t, tc.expectedRepo, repo)
+		})
+	}
+}
+
+func Test_applyLockdown(t *testing.T) {
+	// The mocked repository access allows maintainer everywhere, and denies testuser in owner/repo
+	newComments := func() []*github.IssueComment {
+		return []*github.IssueComment{
+			{ID: github.Ptr(int64(1)), Body: github.Ptr("from maintainer"), User: &github.User{Login: github.Ptr("maintainer")}},
+			{ID: github.Ptr(int64(2)), Body: github.Ptr("from testuser"), User: &github.User{Login: github.Ptr("testuser")}},
+			{ID: github.Ptr(int64(3)), Body: github.Ptr("from unknown author")},
+		}
+	}
+	origin := func(comment *github.IssueComment) contentOrigin {
+		return contentOrigin{Login: comment.GetUser().GetLogin(), Owner: "owner", Repo: "repo"}
+	}
+	ctx := context.Background()
+
+	toJSON := func(t *testing.T, v any) []map[string]any {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		var out []map[string]any
+		require.NoError(t, json.Unmarshal(data, &out))
+		return out
+	}
+
+	t.Run("lockdown disabled returns items unchanged", func(t *testing.T) {
+		comments := newComments()
+		output, err := applyLockdown(ctx, repoAccessCache, stubFeatureFlags(map[string]bool{"lockdown-mode": false}), comments, origin, redactIssueComment)
+		require.NoError(t, err)
+		returned := toJSON(t, output)
+		require.Len(t, returned, 3)
+		for i, comment := range returned {
+			assert.Equal(t, comments[i].GetBody(), comment["body"])
+			assert.NotContains(t, comment, "trust_level")
+		}
+	})
+
+	t.Run("lockdown drops untrusted items", func(t *testing.T) {
+		output, err := applyLockdown(ctx, repoAccessCache, stubFeatureFlags(map[string]bool{"lockdown-mode": true}), newComments(), origin, redactIssueComment)
+		require.NoError(t, err)
+		returned := toJSON(t, output)
+		require.Len(t, returned, 2)
+		assert.Equal(t, "from maintainer", returned[0]["body"])
+		assert.Equal(t, "collaborator", returned[0]["trust_level"])
+		assert.NotContains(t, returned[0], "redacted")
+		// Content without an author, such as that of deleted accounts, is shown
+		assert.Equal(t, "from unknown author", returned[1]["body"])
+		assert.Equal(t, "external", returned[1]["trust_level"])
+	})
+
+	t.Run("lockdown redact keeps metadata and replaces text", func(t *testing.T) {
+		output, err := applyLockdown(ctx, repoAccessCache, stubFeatureFlags(map[string]bool{"lockdown-mode": true, "lockdown-redact": true}), newComments(), origin, redactIssueComment)
+		require.NoError(t, err)
+		returned := toJSON(t, output)
+		require.Len(t, returned, 3)
+
+		assert.Equal(t, "from maintainer", returned[0]["body"])
+		assert.Equal(t, "collaborator", returned[0]["trust_level"])
+
+		assert.Equal(t, float64(2), returned[1]["id"])
+		assert.Equal(t, "testuser", returned[1]["user"].(map[string]any)["login"])
+		assert.Equal(t, "[Content withheld by lockdown mode: testuser is not a trusted author in owner/repo]", returned[1]["body"])
+		assert.Equal(t, "external", returned[1]["trust_level"])
+		assert.Equal(t, true, returned[1]["redacted"])
+
+		assert.Equal(t, "from unknown author", returned[2]["body"])
+		assert.Equal(t, "external", returned[2]["trust_level"])
+		assert.NotContains(t, returned[2], "redacted")
+	})
+
+	t.Run("private repository content is safe", func(t *testing.T) {
+		comment := &github.IssueComment{Body: github.Ptr("private"), User: &github.User{Login: github.Ptr("testuser2")}}
+		output, err := applyLockdownItem(ctx, repoAccessCache, stubFeatureFlags(map[string]bool{"lockdown-mode": true}), comment,
+			contentOrigin{Login: "testuser2", Owner: "owner2", Repo: "repo2"}, redactIssueComment)
+		require.NoError(t, err)
+		tagged := output.(map[string]any)
+		assert.Equal(t, "private", tagged["body"])
+		assert.Equal(t, lockdown.TrustLevelExternal, tagged["trust_level"])
+	})
+
+	t.Run("private repository content of unknown authors is safe", func(t *testing.T) {
+		comment := &github.IssueComment{Body: github.Ptr("private")}
+		output, err := applyLockdownItem(ctx, repoAccessCache, stubFeatureFlags(map[string]bool{"lockdown-mode": true}), comment,
+			contentOrigin{Owner: "owner2", Repo: "repo2"}, redactIssueComment)
+		require.NoError(t, err)
+		tagged := output.(map[string]any)
+		assert.Equal(t, "private", tagged["body"])
+		assert.Equal(t, lockdown.TrustLevelExternal, tagged["trust_level"])
+	})
+
+	t.Run("nil items stay nil", func(t *testing.T) {
+		output, err := applyLockdown(ctx, repoAccessCache, stubFeatureFlags(map[string]bool{"lockdown-mode": true}), []*github.IssueComment(nil), origin, redactIssueComment)
+		require.NoError(t, err)
+		assert.Nil(t, output)
+	})
+
+	t.Run("missing cache is an error", func(t *testing.T) {
+		_, err := applyLockdown(ctx, nil, stubFeatureFlags(map[string]bool{"lockdown-mode": true}), newComments(), origin, redactIssueComment)
+		require.ErrorContains(t, err, "lockdown cache is not configured")
+	})
+}
+
+func Test_SearchIssues_Lockdown(t *testing.T) {
+	mockSearchResult := &github.IssuesSearchResult{
+		Total: github.Ptr(3),
+		Issues: []*github.Issue{
+			{
+				Number:        github.Ptr(1),
+				Title:         github.Ptr("Maintainer issue"),
+				RepositoryURL: github.Ptr("https://api.github.com/repos/owner/repo"),
+				User:          &github.User{Login: github.Ptr("maintainer")},
+			},
+			{
+				Number:        github.Ptr(2),
+				Title:         github.Ptr("External issue"),
+				RepositoryURL: github.Ptr("https://api.github.com/repos/owner/repo"),
+				User:          &github.User{Login: github.Ptr("testuser")},
+			},
+			{
+				Number:        github.Ptr(3),
+				Title:         github.Ptr("Private repository issue"),
+				RepositoryURL: github.Ptr("https://api.github.com/repos/owner2/repo2"),
+				User:          &github.User{Login: github.Ptr("testuser2")},
+			},
+		},
+	}
+	client := github.NewClient(mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetSearchIssues, mockSearchResult),
+	))
+	_, handler := SearchIssues(stubGetClientFn(client), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": true}))
+
+	result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+		"query": "is:open",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var returned github.IssuesSearchResult
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &returned))
+	require.Len(t, returned.Issues, 2)
+	assert.Equal(t, 1, returned.Issues[0].GetNumber())
+	assert.Equal(t, 3, returned.Issues[1].GetNumber())
+}
@@ -86,6 +86,36 @@ type MinimalCommit struct {
 	Files     []MinimalCommitFile `json:"files,omitempty"`
 }
 
+// MinimalComparedFile represents a file changed between two refs.
+type MinimalComparedFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename,omitempty"`
+	Status           string `json:"status,omitempty"`
+	Additions        int    `json:"additions,omitempty"`
+	Deletions        int    `json:"deletions,omitempty"`
+	Changes          int    `json:"changes,omitempty"`
+	Patch            string `json:"patch,omitempty"`
+	// PatchOmitted is set when the patch was requested but left out to stay within the output size budget.
+	PatchOmitted bool `json:"patch_omitted,omitempty"`
+}
+
+// MinimalComparison is the trimmed output type for the comparison of two refs.
+type MinimalComparison struct {
+	Status       string `json:"status"`
+	AheadBy      int    `json:"ahead_by"`
+	BehindBy     int    `json:"behind_by"`
+	TotalCommits int    `json:"total_commits"`
+	MergeBaseSHA string `json:"merge_base_sha,omitempty"`
+	HTMLURL      string `json:"html_url,omitempty"`
+	// Commits are the MinimalCommit values of the page, after lockdown mode is applied.
+	Commits []any                 `json:"commits"`
+	Files   []MinimalComparedFile `json:"files"`
+	// TotalFiles is the number of changed files before path_filter is applied.
+	TotalFiles int `json:"total_files"`
+	// FilesTruncated is set when the API listed only the first of the changed files.
+	FilesTruncated bool `json:"files_truncated,omitempty"`
+}
+
 // MinimalRelease is the trimmed output type for release objects.
 type MinimalRelease struct {
 	ID          int64        `json:"id"`
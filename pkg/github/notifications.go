@@ -10,6 +10,7 @@ import (
 	"time"
 
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/lockdown"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/google/go-github/v79/github"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -217,8 +218,9 @@ func MarkAllNotificationsRead(getClient GetClientFn, t translations.TranslationH
 	return mcp.NewTool("mark_all_notifications_read",
 			mcp.WithDescription(t("TOOL_MARK_ALL_NOTIFICATIONS_READ_DESCRIPTION", "Mark all notifications as read")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
-				Title:        t("TOOL_MARK_ALL_NOTIFICATIONS_READ_USER_TITLE", "Mark all notifications as read"),
-				ReadOnlyHint: ToBoolPtr(false),
+				Title:           t("TOOL_MARK_ALL_NOTIFICATIONS_READ_USER_TITLE", "Mark all notifications as read"),
+				ReadOnlyHint:    ToBoolPtr(false),
+				DestructiveHint: ToBoolPtr(true),
 			}),
 			mcp.WithString("lastReadAt",
 				mcp.Description("Describes the last point that notifications were checked (optional). Default: Now"),
@@ -292,7 +294,7 @@ func MarkAllNotificationsRead(getClient GetClientFn, t translations.TranslationH
 }
 
 // GetNotificationDetails creates a tool to get details for a specific notification.
-func GetNotificationDetails(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func GetNotificationDetails(getClient GetClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_notification_details",
 			mcp.WithDescription(t("TOOL_GET_NOTIFICATION_DETAILS_DESCRIPTION", "Get detailed information for a specific GitHub notification, always call this tool when the user asks for details about a specific notification, if you don't know the ID list notifications first.")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -333,7 +335,22 @@ func GetNotificationDetails(getClient GetClientFn, t translations.TranslationHel
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get notification details: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(thread)
+			var output any = thread
+			if flags.LockdownMode {
+				origin, err := notificationSubjectOrigin(ctx, client, thread)
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+				}
+				output, err = applyLockdownItem(ctx, cache, flags, thread, origin, redactNotification)
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+				}
+				if output == nil {
+					return mcp.NewToolResultError("access to notification details is restricted by lockdown mode"), nil
+				}
+			}
+
+			r, err := json.Marshal(output)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
@@ -342,6 +359,40 @@ func GetNotificationDetails(getClient GetClientFn, t translations.TranslationHel
 		}
 }
 
+// notificationSubjectOrigin fetches the subject of a notification, such as an issue, pull request, commit or
+// release, to find out who wrote it. Subjects without an API URL, such as discussions, have an unknown author.
+func notificationSubjectOrigin(ctx context.Context, client *github.Client, thread *github.Notification) (contentOrigin, error) {
+	origin := contentOrigin{
+		Owner: thread.GetRepository().GetOwner().GetLogin(),
+		Repo:  thread.GetRepository().GetName(),
+	}
+	subjectURL := thread.GetSubject().GetURL()
+	if subjectURL == "" {
+		return origin, nil
+	}
+
+	req, err := client.NewRequest(http.MethodGet, subjectURL, nil)
+	if err != nil {
+		return contentOrigin{}, fmt.Errorf("failed to create request for notification subject: %w", err)
+	}
+	var subject struct {
+		User   *github.User `json:"user"`
+		Author *github.User `json:"author"`
+	}
+	resp, err := client.Do(ctx, req, &subject)
+	if err != nil {
+		return contentOrigin{}, fmt.Errorf("failed to get notification subject: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Issues and pull requests name their author "user", commits and releases "author"
+	origin.Login = subject.User.GetLogin()
+	if origin.Login == "" {
+		origin.Login = subject.Author.GetLogin()
+	}
+	return origin, nil
+}
+
 // Enum values for ManageNotificationSubscription action
 const (
 	NotificationActionIgnore = "ignore"
@@ -688,7 +688,7 @@ func Test_MarkAllNotificationsRead(t *testing.T) {
 func Test_GetNotificationDetails(t *testing.T) {
 	// Verify tool definition and schema
 	mockClient := github.NewClient(nil)
-	tool, _ := GetNotificationDetails(stubGetClientFn(mockClient), translations.NullTranslationHelper)
+	tool, _ := GetNotificationDetails(stubGetClientFn(mockClient), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 	require.NoError(t, toolsnaps.Test(tool.Name, tool))
 
 	assert.Equal(t, "get_notification_details", tool.Name)
@@ -739,7 +739,7 @@ func Test_GetNotificationDetails(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			client := github.NewClient(tc.mockedClient)
-			_, handler := GetNotificationDetails(stubGetClientFn(client), translations.NullTranslationHelper)
+			_, handler := GetNotificationDetails(stubGetClientFn(client), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 			request := createMCPRequest(tc.requestArgs)
 			result, err := handler(context.Background(), request)
 
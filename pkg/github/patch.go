@@ -0,0 +1,611 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	patchStatusAdded    = "added"
+	patchStatusModified = "modified"
+	patchStatusDeleted  = "deleted"
+
+	// maxPatchedFiles is the number of files apply_patch changes in one commit.
+	maxPatchedFiles = 100
+)
+
+// PatchedFile is a file changed by apply_patch.
+type PatchedFile struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+// PatchResult is the result of apply_patch.
+type PatchResult struct {
+	Ref string `json:"ref"`
+	// SHA is the SHA of the commit with the changes.
+	SHA   string        `json:"sha"`
+	Files []PatchedFile `json:"files"`
+}
+
+// patchHunk is a hunk of a unified diff. Its lines keep their newlines, except for a last line that has none.
+type patchHunk struct {
+	header string
+	// oldStart is the line of the file the hunk starts at, counting from 1, or 0 if the header does not say.
+	oldStart int
+	old      []string
+	new      []string
+}
+
+// searchReplace is an edit of apply_patch that replaces text of a file.
+type searchReplace struct {
+	Search     string
+	Replace    string
+	ReplaceAll bool
+}
+
+// filePatch is the change apply_patch makes to a file, as the hunks of a unified diff or as search/replace edits.
+type filePatch struct {
+	Path   string
+	Create bool
+	Delete bool
+	// Mode is the mode of a created file, empty for a regular file.
+	Mode  string
+	Hunks []patchHunk
+	Edits []searchReplace
+}
+
+// ApplyPatch creates a tool to edit files of a branch with a unified diff or search/replace edits, in one commit.
+func ApplyPatch(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("apply_patch",
+			mcp.WithDescription(t("TOOL_APPLY_PATCH_DESCRIPTION", "Edit files of a branch in a single commit, with a unified diff or with search/replace edits, without sending their whole content. The changes are applied to the current files on the branch, and nothing is committed if any of them does not match")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_APPLY_PATCH_USER_TITLE", "Apply patch to files"),
+				ReadOnlyHint: ToBoolPtr(false),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("branch",
+				mcp.Required(),
+				mcp.Description("Branch to commit to"),
+			),
+			mcp.WithString("message",
+				mcp.Required(),
+				mcp.Description("Commit message"),
+			),
+			mcp.WithString("patch",
+				mcp.Description("Unified diff of one or more files, as produced by git diff, with --- a/path and +++ b/path headers and @@ hunks. Use /dev/null as the old path to create a file and as the new path to delete one. Renames and binary files are not supported. Cannot be combined with edits"),
+			),
+			mcp.WithArray("edits",
+				mcp.Items(
+					map[string]interface{}{
+						"type":                 "object",
+						"additionalProperties": false,
+						"required":             []string{"path", "search", "replace"},
+						"properties": map[string]interface{}{
+							"path": map[string]interface{}{
+								"type":        "string",
+								"description": "path to the file",
+							},
+							"search": map[string]interface{}{
+								"type":        "string",
+								"description": "exact text to replace, which must occur once in the file unless replace_all is set. Empty to create a file that does not exist with replace as its content",
+							},
+							"replace": map[string]interface{}{
+								"type":        "string",
+								"description": "text to replace it with",
+							},
+							"replace_all": map[string]interface{}{
+								"type":        "boolean",
+								"description": "replace every occurrence of search",
+							},
+						},
+					}),
+				mcp.Description("Search/replace edits, applied in order. A file may be edited several times. Cannot be combined with patch"),
+			),
+			mcp.WithString("expected_head_sha",
+				mcp.Description("SHA of the commit the changes are based on. If the branch has moved since, the changes are applied to its head when no other commit changed the same files, and a conflict is returned otherwise"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			branch, err := RequiredParam[string](request, "branch")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			message, err := RequiredParam[string](request, "message")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			patch, err := OptionalParam[string](request, "patch")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			expectedHeadSHA, err := OptionalParam[string](request, "expected_head_sha")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			editsObj, _ := request.GetArguments()["edits"].([]interface{})
+			files, err := parseFilePatches(patch, editsObj)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			// Get the reference for the branch
+			ref, resp, err := client.Git.GetRef(ctx, owner, repo, "refs/heads/"+branch)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					"failed to get branch reference",
+					resp,
+					err,
+				), nil
+			}
+			_ = resp.Body.Close()
+
+			// Changes made to the branch since the expected head are kept if they touch other files, which leaves
+			// the files to patch as they were at the expected head
+			headSHA := ref.GetObject().GetSHA()
+			touched := make([]string, 0, len(files))
+			for _, file := range files {
+				touched = append(touched, file.Path)
+			}
+			if expectedHeadSHA != "" && expectedHeadSHA != headSHA {
+				if result := rebaseConflict(ctx, client, owner, repo, branch, expectedHeadSHA, headSHA, touched); result != nil {
+					return result, nil
+				}
+			}
+
+			var patched []PatchedFile
+			updatedRef, errResult := pushCommit(ctx, client, owner, repo, branch, headSHA, message, touched, func(baseTreeSHA string) ([]*github.TreeEntry, *mcp.CallToolResult) {
+				entries, changed, errResult := patchTreeEntries(ctx, client, owner, repo, baseTreeSHA, files)
+				patched = changed
+				return entries, errResult
+			})
+			if errResult != nil {
+				return errResult, nil
+			}
+
+			return MarshalledTextResult(PatchResult{
+				Ref:   updatedRef.GetRef(),
+				SHA:   updatedRef.GetObject().GetSHA(),
+				Files: patched,
+			}), nil
+		}
+}
+
+// patchTreeEntries reads the files to patch from the base tree and creates the tree entries of their new
+// content. Files that the patch leaves unchanged get no entry. On failure, it returns the result to report.
+func patchTreeEntries(ctx context.Context, client *github.Client, owner, repo, baseTreeSHA string, files []filePatch) ([]*github.TreeEntry, []PatchedFile, *mcp.CallToolResult) {
+	tree, resp, err := client.Git.GetTree(ctx, owner, repo, baseTreeSHA, true)
+	if err != nil {
+		return nil, nil, ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to get base tree", resp, err)
+	}
+	_ = resp.Body.Close()
+	baseEntries := make(map[string]*github.TreeEntry, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		baseEntries[entry.GetPath()] = entry
+	}
+
+	var entries []*github.TreeEntry
+	var patched []PatchedFile
+	for _, file := range files {
+		var current *string
+		baseEntry, exists := baseEntries[file.Path]
+		switch {
+		case exists && baseEntry.GetType() != "blob":
+			return nil, nil, mcp.NewToolResultError(fmt.Sprintf("cannot patch %s: it is not a file", file.Path))
+		case exists:
+			content, resp, err := client.Git.GetBlobRaw(ctx, owner, repo, baseEntry.GetSHA())
+			if err != nil {
+				return nil, nil, ghErrors.NewGitHubAPIErrorResponse(ctx, fmt.Sprintf("failed to get content of %s", file.Path), resp, err)
+			}
+			_ = resp.Body.Close()
+			if !utf8.Valid(content) || bytes.IndexByte(content, 0) >= 0 {
+				return nil, nil, mcp.NewToolResultError(fmt.Sprintf("cannot patch %s: it is not a UTF-8 text file", file.Path))
+			}
+			text := string(content)
+			current = &text
+		case tree.GetTruncated() && !file.Create:
+			return nil, nil, mcp.NewToolResultError(fmt.Sprintf("cannot patch %s: the repository has too many files to look it up", file.Path))
+		}
+
+		content, err := patchContent(file, current)
+		if err != nil {
+			return nil, nil, mcp.NewToolResultError(err.Error())
+		}
+
+		entry := &github.TreeEntry{
+			Path: github.Ptr(file.Path),
+			Mode: github.Ptr(defaultFileMode),
+			Type: github.Ptr("blob"),
+		}
+		switch {
+		case content == nil:
+			// An entry with neither content nor SHA deletes the file
+			entry.Mode = baseEntry.Mode
+			patched = append(patched, PatchedFile{Path: file.Path, Status: patchStatusDeleted})
+		case current == nil:
+			if file.Mode != "" {
+				entry.Mode = github.Ptr(file.Mode)
+			}
+			entry.Content = content
+			patched = append(patched, PatchedFile{Path: file.Path, Status: patchStatusAdded})
+		case *content == *current:
+			continue
+		default:
+			entry.Mode = baseEntry.Mode
+			entry.Content = content
+			patched = append(patched, PatchedFile{Path: file.Path, Status: patchStatusModified})
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil, nil, mcp.NewToolResultError("nothing was committed: the changes leave every file as it is")
+	}
+	return entries, patched, nil
+}
+
+// patchContent applies the changes of file to current, the content of the file or nil if there is no such file.
+// It returns the new content, or nil if the file is deleted.
+func patchContent(file filePatch, current *string) (*string, error) {
+	switch {
+	case current == nil && !file.Create:
+		return nil, fmt.Errorf("cannot patch %s: no such file on the branch", file.Path)
+	case current != nil && file.Create:
+		return nil, fmt.Errorf("cannot create %s: the file already exists on the branch", file.Path)
+	}
+
+	var content string
+	if current != nil {
+		content = *current
+	}
+	var err error
+	if file.Edits != nil {
+		content, err = applySearchReplace(file.Path, content, file.Edits)
+	} else {
+		content, err = applyPatchHunks(file.Path, content, file.Hunks)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if file.Delete {
+		if content != "" {
+			return nil, fmt.Errorf("cannot delete %s: the patch does not remove all of its lines, so the file differs from the one the patch was made from", file.Path)
+		}
+		return nil, nil
+	}
+	return &content, nil
+}
+
+// parseFilePatches validates the patch and edits parameters of apply_patch, of which exactly one must be given.
+func parseFilePatches(patch string, editsObj []interface{}) ([]filePatch, error) {
+	var files []filePatch
+	var err error
+	switch {
+	case patch != "" && len(editsObj) > 0:
+		return nil, fmt.Errorf("patch and edits cannot be combined")
+	case patch != "":
+		files, err = parseUnifiedDiff(patch)
+	case len(editsObj) > 0:
+		files, err = parseSearchReplaceEdits(editsObj)
+	default:
+		return nil, fmt.Errorf("either patch or edits is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > maxPatchedFiles {
+		return nil, fmt.Errorf("cannot change more than %d files in one commit", maxPatchedFiles)
+	}
+	return files, nil
+}
+
+// parseSearchReplaceEdits validates the edits parameter of apply_patch, and groups the edits by file.
+func parseSearchReplaceEdits(editsObj []interface{}) ([]filePatch, error) {
+	var files []filePatch
+	index := make(map[string]int)
+	for i, edit := range editsObj {
+		editMap, ok := edit.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("each edit must be an object with path, search and replace")
+		}
+		path, ok := editMap["path"].(string)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("edit %d must have a path", i+1)
+		}
+		search, ok := editMap["search"].(string)
+		if !ok {
+			return nil, fmt.Errorf("edit %d of %s must have search text", i+1, path)
+		}
+		replace, ok := editMap["replace"].(string)
+		if !ok {
+			return nil, fmt.Errorf("edit %d of %s must have replace text", i+1, path)
+		}
+		replaceAll, _ := editMap["replace_all"].(bool)
+
+		n, ok := index[path]
+		if !ok {
+			// An empty search text creates the file, and can only come first
+			n = len(files)
+			index[path] = n
+			files = append(files, filePatch{Path: path, Create: search == ""})
+		} else if search == "" {
+			return nil, fmt.Errorf("edit %d of %s has empty search text, which only the first edit of a file may have to create it", i+1, path)
+		}
+		files[n].Edits = append(files[n].Edits, searchReplace{Search: search, Replace: replace, ReplaceAll: replaceAll})
+	}
+	return files, nil
+}
+
+// applySearchReplace applies edits to the content of the file at path, in order.
+func applySearchReplace(path, content string, edits []searchReplace) (string, error) {
+	for i, edit := range edits {
+		if edit.Search == "" {
+			// The file is created with the replace text
+			content = edit.Replace
+			continue
+		}
+		switch count := strings.Count(content, edit.Search); {
+		case count == 0:
+			return "", fmt.Errorf("edit %d of %s does not apply: the search text is not in the file. Read the file again and copy the text to replace exactly, including whitespace", i+1, path)
+		case count > 1 && !edit.ReplaceAll:
+			return "", fmt.Errorf("edit %d of %s does not apply: the search text occurs %d times in the file. Add surrounding lines to make it unique, or set replace_all", i+1, path, count)
+		}
+		if edit.ReplaceAll {
+			content = strings.ReplaceAll(content, edit.Search, edit.Replace)
+		} else {
+			content = strings.Replace(content, edit.Search, edit.Replace, 1)
+		}
+	}
+	return content, nil
+}
+
+// parseUnifiedDiff parses a unified diff of one or more files. Text outside of the file headers and hunks, such
+// as git's diff and index lines, is ignored. The line counts of hunk headers are not checked, as hunks end at the
+// next header.
+func parseUnifiedDiff(patch string) ([]filePatch, error) {
+	var files []filePatch
+	seen := make(map[string]bool)
+	var file *filePatch
+	var hunk *patchHunk
+	// mode is the mode of a new file given before its file header
+	mode := ""
+	// blankLines counts the empty lines read in a hunk, which are blank context lines whose space was stripped, or
+	// trailing empty lines of the patch if the hunk ends after them
+	blankLines := 0
+	var lastKind byte
+
+	endHunk := func() error {
+		if hunk == nil {
+			return nil
+		}
+		if len(hunk.old) == 0 && len(hunk.new) == 0 {
+			return fmt.Errorf("hunk %q of %s is empty", hunk.header, file.Path)
+		}
+		file.Hunks = append(file.Hunks, *hunk)
+		hunk = nil
+		blankLines = 0
+		return nil
+	}
+
+	lines := textLines(patch)
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			if err := endHunk(); err != nil {
+				return nil, err
+			}
+			oldPath := patchPath(line[len("--- "):], "a/")
+			newPath := patchPath(lines[i+1][len("+++ "):], "b/")
+			i++
+
+			f := filePatch{Path: newPath, Mode: mode}
+			mode = ""
+			switch {
+			case oldPath == "/dev/null" && newPath == "/dev/null":
+				return nil, fmt.Errorf("line %d of the patch: a file header must name a file", i)
+			case oldPath == "/dev/null":
+				f.Create = true
+			case newPath == "/dev/null":
+				f.Path = oldPath
+				f.Delete = true
+			case oldPath != newPath:
+				return nil, fmt.Errorf("line %d of the patch: renaming %s to %s is not supported, use push_files to rename files", i, oldPath, newPath)
+			}
+			if seen[f.Path] {
+				return nil, fmt.Errorf("the patch changes %s more than once", f.Path)
+			}
+			seen[f.Path] = true
+			files = append(files, f)
+			file = &files[len(files)-1]
+		case strings.HasPrefix(line, "@@"):
+			if file == nil {
+				return nil, fmt.Errorf("line %d of the patch: hunk %q comes before a --- and +++ file header", i+1, strings.TrimSpace(line))
+			}
+			if err := endHunk(); err != nil {
+				return nil, err
+			}
+			hunk = &patchHunk{header: strings.TrimSpace(line)}
+			lastKind = 0
+			if _, err := fmt.Sscanf(line, "@@ -%d", &hunk.oldStart); err != nil {
+				hunk.oldStart = 0
+			}
+		case strings.HasPrefix(line, "diff "):
+			// The header of the next file, whose hunks must not be added to this one
+			if err := endHunk(); err != nil {
+				return nil, err
+			}
+			file = nil
+		case strings.HasPrefix(line, "new file mode "):
+			if err := endHunk(); err != nil {
+				return nil, err
+			}
+			file = nil
+			mode = strings.TrimSpace(line[len("new file mode "):])
+			switch mode {
+			case "100644", "100755", "120000":
+			default:
+				return nil, fmt.Errorf("line %d of the patch: unsupported file mode %s", i+1, mode)
+			}
+		case strings.HasPrefix(line, "rename from ") || strings.HasPrefix(line, "copy from "):
+			return nil, fmt.Errorf("line %d of the patch: renaming and copying files is not supported, use push_files instead", i+1)
+		case strings.HasPrefix(line, "Binary files ") || strings.HasPrefix(line, "GIT binary patch"):
+			return nil, fmt.Errorf("line %d of the patch: binary files are not supported, use push_files instead", i+1)
+		case hunk == nil:
+			// Text outside of hunks, such as a diff or index line
+		case strings.HasPrefix(line, `\`):
+			// The previous line has no newline at the end of the file
+			if lastKind == 0 {
+				return nil, fmt.Errorf("line %d of the patch: %q must follow a hunk line", i+1, strings.TrimSuffix(line, "\n"))
+			}
+			if lastKind != '+' {
+				hunk.old[len(hunk.old)-1] = strings.TrimSuffix(hunk.old[len(hunk.old)-1], "\n")
+			}
+			if lastKind != '-' {
+				hunk.new[len(hunk.new)-1] = strings.TrimSuffix(hunk.new[len(hunk.new)-1], "\n")
+			}
+		case line == "\n":
+			blankLines++
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			for ; blankLines > 0; blankLines-- {
+				hunk.old = append(hunk.old, "\n")
+				hunk.new = append(hunk.new, "\n")
+			}
+			text := line[1:]
+			if !strings.HasSuffix(text, "\n") {
+				// The last line of the patch
+				text += "\n"
+			}
+			lastKind = line[0]
+			switch lastKind {
+			case ' ':
+				hunk.old = append(hunk.old, text)
+				hunk.new = append(hunk.new, text)
+			case '-':
+				hunk.old = append(hunk.old, text)
+			case '+':
+				hunk.new = append(hunk.new, text)
+			}
+		default:
+			return nil, fmt.Errorf("line %d of the patch: %q is not a hunk line, which must start with a space, - or +", i+1, strings.TrimSuffix(line, "\n"))
+		}
+	}
+	if err := endHunk(); err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		if f.Hunks == nil {
+			return nil, fmt.Errorf("the patch has no hunks for %s", f.Path)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("the patch has no --- and +++ file headers")
+	}
+	return files, nil
+}
+
+// patchPath returns the path of a --- or +++ file header, without its git prefix and any timestamp.
+func patchPath(header, prefix string) string {
+	path := strings.TrimSuffix(header, "\n")
+	if tab := strings.IndexByte(path, '\t'); tab >= 0 {
+		path = path[:tab]
+	}
+	path = strings.TrimSpace(path)
+	if path == "/dev/null" {
+		return path
+	}
+	return strings.TrimPrefix(path, prefix)
+}
+
+// applyPatchHunks applies hunks to the content of the file at path. A hunk applies where its context and removed
+// lines occur in the file, nearest to the line its header gives, as long as hunks stay in order.
+func applyPatchHunks(path, content string, hunks []patchHunk) (string, error) {
+	lines := textLines(content)
+	var result []string
+	next := 0
+	for i, hunk := range hunks {
+		at, matches := findHunk(lines, next, hunk)
+		switch {
+		case matches == 0:
+			return "", hunkMismatch(path, lines, i, hunk)
+		case matches > 1 && hunk.oldStart == 0:
+			return "", fmt.Errorf("hunk %d of %s (%s) does not apply: its context and removed lines occur %d times in the file. Give the line numbers in the hunk header, or add context lines", i+1, path, hunk.header, matches)
+		}
+		result = append(result, lines[next:at]...)
+		result = append(result, hunk.new...)
+		next = at + len(hunk.old)
+	}
+	result = append(result, lines[next:]...)
+	return strings.Join(result, ""), nil
+}
+
+// findHunk returns the index of the lines, from next on, where hunk applies nearest to the line of its header,
+// and the number of places where it applies.
+func findHunk(lines []string, next int, hunk patchHunk) (int, int) {
+	if len(hunk.old) == 0 {
+		// A hunk that only adds lines goes after the line of its header
+		return min(max(hunk.oldStart, next), len(lines)), 1
+	}
+
+	expected := hunk.oldStart - 1
+	found, matches := -1, 0
+	for at := next; at+len(hunk.old) <= len(lines); at++ {
+		if !slices.Equal(lines[at:at+len(hunk.old)], hunk.old) {
+			continue
+		}
+		matches++
+		if found < 0 || abs(at-expected) < abs(found-expected) {
+			found = at
+		}
+	}
+	return found, matches
+}
+
+// hunkMismatch describes why hunk n of the patch of path does not apply to lines.
+func hunkMismatch(path string, lines []string, n int, hunk patchHunk) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "hunk %d of %s (%s) does not apply: its context and removed lines are not in the file. Read the file again and make the patch against its current content. The hunk expects:\n%s", n+1, path, hunk.header, strings.Join(hunk.old, ""))
+	if hunk.oldStart > 0 && hunk.oldStart <= len(lines) {
+		end := min(hunk.oldStart-1+len(hunk.old), len(lines))
+		fmt.Fprintf(&msg, "\nbut lines %d-%d of the file are:\n%s", hunk.oldStart, end, strings.Join(lines[hunk.oldStart-1:end], ""))
+	}
+	return errors.New(msg.String())
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
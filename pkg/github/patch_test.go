@@ -0,0 +1,454 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/github/github-mcp-server/internal/toolsnaps"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name           string
+		patch          string
+		expectedFiles  []filePatch
+		expectedErrMsg string
+	}{
+		{
+			name: "git diff of several files",
+			patch: `diff --git a/a.txt b/a.txt
+index 1234567..89abcde 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@ func main() {
+ hello
+
+-world
++there
+@@ -10,2 +10,2 @@
+ ten
+-eleven
++ELEVEN
+diff --git a/new.sh b/new.sh
+new file mode 100755
+index 0000000..1234567
+--- /dev/null
++++ b/new.sh
+@@ -0,0 +1 @@
++echo hi
+\ No newline at end of file
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-old
+`,
+			expectedFiles: []filePatch{
+				{
+					Path: "a.txt",
+					Hunks: []patchHunk{
+						{header: "@@ -1,3 +1,3 @@ func main() {", oldStart: 1, old: []string{"hello\n", "\n", "world\n"}, new: []string{"hello\n", "\n", "there\n"}},
+						{header: "@@ -10,2 +10,2 @@", oldStart: 10, old: []string{"ten\n", "eleven\n"}, new: []string{"ten\n", "ELEVEN\n"}},
+					},
+				},
+				{
+					Path:   "new.sh",
+					Create: true,
+					Mode:   "100755",
+					Hunks:  []patchHunk{{header: "@@ -0,0 +1 @@", new: []string{"echo hi"}}},
+				},
+				{
+					Path:   "old.txt",
+					Delete: true,
+					Hunks:  []patchHunk{{header: "@@ -1 +0,0 @@", oldStart: 1, old: []string{"old\n"}}},
+				},
+			},
+		},
+		{
+			name:  "trailing empty lines and hunk without line numbers",
+			patch: "--- a.txt\n+++ a.txt\n@@ @@\n-one\n+1\n\n\n",
+			expectedFiles: []filePatch{
+				{
+					Path:  "a.txt",
+					Hunks: []patchHunk{{header: "@@ @@", old: []string{"one\n"}, new: []string{"1\n"}}},
+				},
+			},
+		},
+		{
+			name:           "rename",
+			patch:          "--- a/a.txt\n+++ b/b.txt\n@@ -1 +1 @@\n-one\n+1\n",
+			expectedErrMsg: "renaming a.txt to b.txt is not supported",
+		},
+		{
+			name:           "binary file",
+			patch:          "diff --git a/logo.png b/logo.png\nBinary files a/logo.png and b/logo.png differ\n",
+			expectedErrMsg: "binary files are not supported",
+		},
+		{
+			name:           "hunk without file header",
+			patch:          "@@ -1 +1 @@\n-one\n+1\n",
+			expectedErrMsg: "comes before a --- and +++ file header",
+		},
+		{
+			name:           "invalid hunk line",
+			patch:          "--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-one\n*1\n",
+			expectedErrMsg: `line 5 of the patch: "*1" is not a hunk line`,
+		},
+		{
+			name:           "file changed twice",
+			patch:          "--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-one\n+1\n--- a/a.txt\n+++ b/a.txt\n@@ -2 +2 @@\n-two\n+2\n",
+			expectedErrMsg: "the patch changes a.txt more than once",
+		},
+		{
+			name:           "no file headers",
+			patch:          "just some text\n",
+			expectedErrMsg: "the patch has no --- and +++ file headers",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := parseUnifiedDiff(tc.patch)
+			if tc.expectedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedFiles, files)
+		})
+	}
+}
+
+func Test_applyPatchHunks(t *testing.T) {
+	content := "one\ntwo\nthree\nfour\nfive\nsix\ntwo\nthree\n"
+
+	tests := []struct {
+		name            string
+		patch           string
+		content         string
+		expectedContent string
+		expectedErrMsg  string
+	}{
+		{
+			name:            "hunks at their lines",
+			patch:           "--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n-one\n+ONE\n two\n@@ -7,2 +7,3 @@\n two\n+two and a half\n three\n",
+			expectedContent: "ONE\ntwo\nthree\nfour\nfive\nsix\ntwo\ntwo and a half\nthree\n",
+		},
+		{
+			name:            "hunk with wrong line number applies nearest to it",
+			patch:           "--- a/f\n+++ b/f\n@@ -6,2 +6,2 @@\n two\n-three\n+THREE\n",
+			expectedContent: "one\ntwo\nthree\nfour\nfive\nsix\ntwo\nTHREE\n",
+		},
+		{
+			name:            "hunk that only adds lines",
+			patch:           "--- a/f\n+++ b/f\n@@ -3,0 +4 @@\n+three and a half\n",
+			expectedContent: "one\ntwo\nthree\nthree and a half\nfour\nfive\nsix\ntwo\nthree\n",
+		},
+		{
+			name:            "missing final newline",
+			patch:           "--- a/f\n+++ b/f\n@@ -2 +2 @@\n-last\n\\ No newline at end of file\n+LAST\n",
+			content:         "first\nlast",
+			expectedContent: "first\nLAST\n",
+		},
+		{
+			name:           "context mismatch",
+			patch:          "--- a/f\n+++ b/f\n@@ -2,2 +2,2 @@\n two\n-tree\n+THREE\n",
+			expectedErrMsg: "hunk 1 of f (@@ -2,2 +2,2 @@) does not apply: its context and removed lines are not in the file. Read the file again and make the patch against its current content. The hunk expects:\ntwo\ntree\n\nbut lines 2-3 of the file are:\ntwo\nthree\n",
+		},
+		{
+			name:           "ambiguous hunk without line numbers",
+			patch:          "--- a/f\n+++ b/f\n@@ @@\n two\n-three\n+THREE\n",
+			expectedErrMsg: "hunk 1 of f (@@ @@) does not apply: its context and removed lines occur 2 times in the file",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := parseUnifiedDiff(tc.patch)
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+			if tc.content == "" {
+				tc.content = content
+			}
+			patched, err := applyPatchHunks(files[0].Path, tc.content, files[0].Hunks)
+			if tc.expectedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedContent, patched)
+		})
+	}
+}
+
+func Test_applySearchReplace(t *testing.T) {
+	content := "a := 1\nb := 1\nc := 2\n"
+
+	tests := []struct {
+		name            string
+		edits           []searchReplace
+		expectedContent string
+		expectedErrMsg  string
+	}{
+		{
+			name: "edits applied in order",
+			edits: []searchReplace{
+				{Search: "a := 1", Replace: "a := 10"},
+				{Search: "a := 10\nb", Replace: "a := 10\nbb"},
+			},
+			expectedContent: "a := 10\nbb := 1\nc := 2\n",
+		},
+		{
+			name:            "replace all",
+			edits:           []searchReplace{{Search: ":= 1", Replace: "= 1", ReplaceAll: true}},
+			expectedContent: "a = 1\nb = 1\nc := 2\n",
+		},
+		{
+			name:           "search text not found",
+			edits:          []searchReplace{{Search: "d := 3", Replace: "d := 4"}},
+			expectedErrMsg: "edit 1 of main.go does not apply: the search text is not in the file",
+		},
+		{
+			name:           "search text not unique",
+			edits:          []searchReplace{{Search: ":= 1", Replace: "= 1"}},
+			expectedErrMsg: "edit 1 of main.go does not apply: the search text occurs 2 times in the file",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			edited, err := applySearchReplace("main.go", content, tc.edits)
+			if tc.expectedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedContent, edited)
+		})
+	}
+}
+
+func Test_ApplyPatch(t *testing.T) {
+	tool, _ := ApplyPatch(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "apply_patch", tool.Name)
+	assert.False(t, *tool.Annotations.ReadOnlyHint)
+	assert.Contains(t, tool.InputSchema.Properties, "patch")
+	assert.Contains(t, tool.InputSchema.Properties, "edits")
+	assert.Contains(t, tool.InputSchema.Properties, "expected_head_sha")
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "branch", "message"})
+
+	repo := gitObjectsRepo{
+		commits: map[string]*github.Commit{
+			"h1": {SHA: github.Ptr("h1"), Tree: &github.Tree{SHA: github.Ptr("th")}},
+		},
+		trees: map[string]map[string]string{
+			"th": {"a.txt": "a0", "old.txt": "o0", "logo.png": "p0"},
+		},
+		blobs: map[string]string{
+			"a0": "hello\nworld\n",
+			"o0": "old\n",
+			"p0": "\x89PNG\x00",
+		},
+	}
+	headRef := mock.WithRequestMatchHandler(
+		mock.GetReposGitRefByOwnerByRepoByRef,
+		expectPath(t, "/repos/owner/repo/git/ref/heads/main").andThen(
+			mockResponse(t, http.StatusOK, &github.Reference{
+				Ref:    github.Ptr("refs/heads/main"),
+				Object: &github.GitObject{SHA: github.Ptr("h1")},
+			}),
+		),
+	)
+	commitAndUpdate := []mock.MockBackendOption{
+		mock.WithRequestMatchHandler(
+			mock.PostReposGitCommitsByOwnerByRepo,
+			expectRequestBody(t, map[string]any{
+				"message": "Update greeting",
+				"tree":    "t2",
+				"parents": []any{"h1"},
+			}).andThen(
+				mockResponse(t, http.StatusCreated, &github.Commit{SHA: github.Ptr("c2")}),
+			),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PatchReposGitRefsByOwnerByRepoByRef,
+			expectRequestBody(t, map[string]any{
+				"sha":   "c2",
+				"force": false,
+			}).andThen(
+				mockResponse(t, http.StatusOK, &github.Reference{
+					Ref:    github.Ptr("refs/heads/main"),
+					Object: &github.GitObject{SHA: github.Ptr("c2")},
+				}),
+			),
+		),
+	}
+	createTree := func(entries []any) mock.MockBackendOption {
+		return mock.WithRequestMatchHandler(
+			mock.PostReposGitTreesByOwnerByRepo,
+			expectRequestBody(t, map[string]any{
+				"base_tree": "th",
+				"tree":      entries,
+			}).andThen(
+				mockResponse(t, http.StatusCreated, &github.Tree{SHA: github.Ptr("t2")}),
+			),
+		)
+	}
+
+	tests := []struct {
+		name           string
+		mocks          []mock.MockBackendOption
+		requestArgs    map[string]interface{}
+		expectError    bool
+		expectedErrMsg string
+		expectedResult PatchResult
+	}{
+		{
+			name: "unified diff of several files",
+			mocks: append([]mock.MockBackendOption{
+				headRef,
+				createTree([]any{
+					map[string]any{"path": "a.txt", "mode": "100644", "type": "blob", "content": "hello\nthere\n"},
+					map[string]any{"path": "new.txt", "mode": "100644", "type": "blob", "content": "new\n"},
+					map[string]any{"path": "old.txt", "mode": "100644", "type": "blob", "sha": nil},
+				}),
+			}, commitAndUpdate...),
+			requestArgs: map[string]interface{}{
+				"patch": "--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n hello\n-world\n+there\n" +
+					"--- /dev/null\n+++ b/new.txt\n@@ -0,0 +1 @@\n+new\n" +
+					"--- a/old.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-old\n",
+			},
+			expectedResult: PatchResult{
+				Ref: "refs/heads/main",
+				SHA: "c2",
+				Files: []PatchedFile{
+					{Path: "a.txt", Status: patchStatusModified},
+					{Path: "new.txt", Status: patchStatusAdded},
+					{Path: "old.txt", Status: patchStatusDeleted},
+				},
+			},
+		},
+		{
+			name: "search/replace edits",
+			mocks: append([]mock.MockBackendOption{
+				headRef,
+				createTree([]any{
+					map[string]any{"path": "a.txt", "mode": "100644", "type": "blob", "content": "hi\nthere\n"},
+				}),
+			}, commitAndUpdate...),
+			requestArgs: map[string]interface{}{
+				"edits": []any{
+					map[string]any{"path": "a.txt", "search": "hello", "replace": "hi"},
+					map[string]any{"path": "a.txt", "search": "world", "replace": "there"},
+				},
+			},
+			expectedResult: PatchResult{
+				Ref:   "refs/heads/main",
+				SHA:   "c2",
+				Files: []PatchedFile{{Path: "a.txt", Status: patchStatusModified}},
+			},
+		},
+		{
+			name:  "context mismatch commits nothing",
+			mocks: []mock.MockBackendOption{headRef},
+			requestArgs: map[string]interface{}{
+				"patch": "--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n hello\n-word\n+there\n",
+			},
+			expectError:    true,
+			expectedErrMsg: "hunk 1 of a.txt (@@ -1,2 +1,2 @@) does not apply",
+		},
+		{
+			name:  "deleted file differs from the patch",
+			mocks: []mock.MockBackendOption{headRef},
+			requestArgs: map[string]interface{}{
+				"patch": "--- a/a.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-hello\n",
+			},
+			expectError:    true,
+			expectedErrMsg: "cannot delete a.txt: the patch does not remove all of its lines",
+		},
+		{
+			name:  "file to patch does not exist",
+			mocks: []mock.MockBackendOption{headRef},
+			requestArgs: map[string]interface{}{
+				"edits": []any{map[string]any{"path": "missing.txt", "search": "a", "replace": "b"}},
+			},
+			expectError:    true,
+			expectedErrMsg: "cannot patch missing.txt: no such file on the branch",
+		},
+		{
+			name:  "binary file",
+			mocks: []mock.MockBackendOption{headRef},
+			requestArgs: map[string]interface{}{
+				"edits": []any{map[string]any{"path": "logo.png", "search": "PNG", "replace": "GIF"}},
+			},
+			expectError:    true,
+			expectedErrMsg: "cannot patch logo.png: it is not a UTF-8 text file",
+		},
+		{
+			name:  "edits that change nothing",
+			mocks: []mock.MockBackendOption{headRef},
+			requestArgs: map[string]interface{}{
+				"edits": []any{map[string]any{"path": "a.txt", "search": "hello", "replace": "hello"}},
+			},
+			expectError:    true,
+			expectedErrMsg: "nothing was committed: the changes leave every file as it is",
+		},
+		{
+			name: "patch and edits",
+			requestArgs: map[string]interface{}{
+				"patch": "--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-hello\n+hi\n",
+				"edits": []any{map[string]any{"path": "a.txt", "search": "hello", "replace": "hi"}},
+			},
+			expectError:    true,
+			expectedErrMsg: "patch and edits cannot be combined",
+		},
+		{
+			name:           "neither patch nor edits",
+			requestArgs:    map[string]interface{}{},
+			expectError:    true,
+			expectedErrMsg: "either patch or edits is required",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := github.NewClient(mock.NewMockedHTTPClient(append(repo.mockOptions(t), tc.mocks...)...))
+			_, handler := ApplyPatch(stubGetClientFn(client), translations.NullTranslationHelper)
+
+			args := map[string]interface{}{
+				"owner":   "owner",
+				"repo":    "repo",
+				"branch":  "main",
+				"message": "Update greeting",
+			}
+			for k, v := range tc.requestArgs {
+				args[k] = v
+			}
+			result, err := handler(context.Background(), createMCPRequest(args))
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			require.False(t, result.IsError)
+			textContent := getTextResult(t, result)
+			var patchResult PatchResult
+			require.NoError(t, json.Unmarshal([]byte(textContent.Text), &patchResult))
+			assert.Equal(t, tc.expectedResult, patchResult)
+		})
+	}
+}
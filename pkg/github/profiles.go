@@ -0,0 +1,163 @@
+package github
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/toolsets"
+)
+
+// ToolsetProfile is a named bundle of toolsets and tool-level adjustments tailored to a common agent role.
+type ToolsetProfile struct {
+	ID          string   `mapstructure:"id"`
+	Description string   `mapstructure:"description"`
+	Toolsets    []string `mapstructure:"toolsets"`
+	// Tools are individual tools enabled in addition to the tools of Toolsets
+	Tools []string `mapstructure:"tools"`
+	// ExcludeTools are tools that stay disabled even though their toolset is enabled
+	ExcludeTools []string `mapstructure:"exclude_tools"`
+	// ReadOnly restricts the profile's toolsets and tools to read-only tools. Toolsets enabled alongside the
+	// profile are not restricted
+	ReadOnly bool `mapstructure:"read_only"`
+}
+
+var (
+	ToolsetProfileReviewer = ToolsetProfile{
+		ID:          "reviewer",
+		Description: "Read pull requests and write reviews",
+		Toolsets:    []string{ToolsetMetadataContext.ID, ToolsetMetadataPullRequests.ID},
+		Tools:       []string{"get_file_contents", "get_commit", "compare_refs"},
+		ExcludeTools: []string{
+			"create_pull_request",
+			"update_pull_request",
+			"merge_pull_request",
+			"update_pull_request_branch",
+		},
+	}
+	ToolsetProfileTriager = ToolsetProfile{
+		ID:          "triager",
+		Description: "Triage issues with labels and projects",
+		Toolsets:    []string{ToolsetMetadataContext.ID, ToolsetMetadataIssues.ID, ToolsetLabels.ID, ToolsetMetadataProjects.ID},
+	}
+	ToolsetProfileCIDoctor = ToolsetProfile{
+		ID:          "ci-doctor",
+		Description: "Investigate GitHub Actions failures and rerun workflows",
+		Toolsets:    []string{ToolsetMetadataContext.ID, ToolsetMetadataActions.ID},
+		Tools:       []string{"get_file_contents", "get_commit"},
+		ExcludeTools: []string{
+			"run_workflow",
+			"cancel_workflow_run",
+			"delete_workflow_run_logs",
+		},
+	}
+	ToolsetProfileSecurity = ToolsetProfile{
+		ID:          "security",
+		Description: "Review code scanning, secret scanning, Dependabot alerts and security advisories",
+		Toolsets: []string{
+			ToolsetMetadataContext.ID,
+			ToolsetMetadataCodeSecurity.ID,
+			ToolsetMetadataSecretProtection.ID,
+			ToolsetMetadataDependabot.ID,
+			ToolsetMetadataSecurityAdvisories.ID,
+		},
+		ReadOnly: true,
+	}
+)
+
+// AvailableProfiles returns the built-in toolset profiles.
+func AvailableProfiles() []ToolsetProfile {
+	return []ToolsetProfile{
+		ToolsetProfileReviewer,
+		ToolsetProfileTriager,
+		ToolsetProfileCIDoctor,
+		ToolsetProfileSecurity,
+	}
+}
+
+// MergeProfiles combines the built-in profiles with profiles from configuration, keyed by profile ID.
+// A configured profile replaces a built-in profile with the same ID.
+func MergeProfiles(overrides map[string]ToolsetProfile) map[string]ToolsetProfile {
+	profiles := make(map[string]ToolsetProfile)
+	for _, p := range AvailableProfiles() {
+		profiles[p.ID] = p
+	}
+	for id, p := range overrides {
+		p.ID = id
+		profiles[id] = p
+	}
+	return profiles
+}
+
+// ResolveProfile looks up a profile by ID among the built-in and configured profiles,
+// and validates the toolsets it references.
+func ResolveProfile(id string, overrides map[string]ToolsetProfile) (ToolsetProfile, error) {
+	profiles := MergeProfiles(overrides)
+	profile, ok := profiles[id]
+	if !ok {
+		ids := make([]string, 0, len(profiles))
+		for pid := range profiles {
+			ids = append(ids, pid)
+		}
+		sort.Strings(ids)
+		return ToolsetProfile{}, fmt.Errorf("profile %s does not exist, available profiles: %s", id, strings.Join(ids, ", "))
+	}
+
+	if _, invalid := CleanToolsets(profile.Toolsets); len(invalid) > 0 {
+		return ToolsetProfile{}, fmt.Errorf("profile %s references invalid toolsets: %s", id, strings.Join(invalid, ", "))
+	}
+	return profile, nil
+}
+
+// ApplyProfileTools enables and disables the individual tools named by the profile, and applies its read-only
+// restriction. It must be called after the profile's toolsets have been enabled.
+func ApplyProfileTools(tsg *toolsets.ToolsetGroup, profile ToolsetProfile) error {
+	ApplyProfileReadOnly(tsg, profile)
+	if profile.ReadOnly {
+		for _, name := range profile.Tools {
+			if isWriteTool(tsg, name) {
+				return fmt.Errorf("profile %s is read-only but enables write tool %s", profile.ID, name)
+			}
+		}
+	}
+	if err := tsg.EnableTools(profile.Tools); err != nil {
+		return fmt.Errorf("profile %s: %w", profile.ID, err)
+	}
+	if err := tsg.DisableTools(profile.ExcludeTools); err != nil {
+		return fmt.Errorf("profile %s: %w", profile.ID, err)
+	}
+	return nil
+}
+
+// ApplyProfileReadOnly restricts the toolsets of a read-only profile to their read-only tools, and returns their
+// IDs. Other toolsets, such as those enabled alongside the profile, keep their write tools.
+func ApplyProfileReadOnly(tsg *toolsets.ToolsetGroup, profile ToolsetProfile) []string {
+	if !profile.ReadOnly {
+		return nil
+	}
+	ids := AddDefaultToolset(profile.Toolsets)
+	if ContainsToolset(ids, ToolsetMetadataAll.ID) {
+		ids = slices.Sorted(maps.Keys(tsg.Toolsets))
+	}
+	var restricted []string
+	for _, id := range ids {
+		if toolset, ok := tsg.Toolsets[id]; ok {
+			toolset.SetReadOnly()
+			restricted = append(restricted, id)
+		}
+	}
+	return restricted
+}
+
+func isWriteTool(tsg *toolsets.ToolsetGroup, name string) bool {
+	for _, toolset := range tsg.Toolsets {
+		for _, tool := range toolset.GetAvailableTools() {
+			if tool.Tool.Name == name {
+				return tool.Tool.Annotations.ReadOnlyHint == nil || !*tool.Tool.Annotations.ReadOnlyHint
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAvailableProfilesReferenceExistingTools(t *testing.T) {
+	validIDs := GetValidToolsetIDs()
+	for _, profile := range AvailableProfiles() {
+		t.Run(profile.ID, func(t *testing.T) {
+			for _, toolset := range profile.Toolsets {
+				assert.True(t, validIDs[toolset], "unknown toolset %s", toolset)
+			}
+
+			tsg := DefaultToolsetGroup(profile.ReadOnly, stubGetClientFn(nil), stubGetGQLClientFn(nil), stubGetRawClientFn(nil), translations.NullTranslationHelper, 5000, FeatureFlags{}, nil)
+			require.NoError(t, tsg.EnableToolsets(profile.Toolsets, nil))
+			require.NoError(t, ApplyProfileTools(tsg, profile))
+		})
+	}
+}
+
+func TestApplyProfileToolsReadOnly(t *testing.T) {
+	profile := ToolsetProfile{ID: "reader", Toolsets: []string{"issues"}, ReadOnly: true}
+	tsg := DefaultToolsetGroup(false, stubGetClientFn(nil), stubGetGQLClientFn(nil), stubGetRawClientFn(nil), translations.NullTranslationHelper, 5000, FeatureFlags{}, nil)
+	require.NoError(t, tsg.EnableToolsets([]string{"issues", "gists"}, nil))
+	require.NoError(t, ApplyProfileTools(tsg, profile))
+
+	assert.NotContains(t, activeToolNames(tsg, "issues"), "issue_write", "the profile's toolsets are read-only")
+	assert.Contains(t, activeToolNames(tsg, "issues"), "issue_read")
+	assert.Contains(t, activeToolNames(tsg, "gists"), "create_gist", "toolsets enabled alongside the profile keep their write tools")
+
+	profile.Tools = []string{"create_gist"}
+	err := ApplyProfileTools(tsg, profile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "profile reader is read-only but enables write tool create_gist")
+}
+
+func TestResolveProfile(t *testing.T) {
+	overrides := map[string]ToolsetProfile{
+		"reviewer": {
+			Description: "Review only",
+			Toolsets:    []string{"pull_requests"},
+			ReadOnly:    true,
+		},
+		"docs-writer": {
+			Toolsets: []string{"repos"},
+		},
+		"broken": {
+			Toolsets: []string{"not_a_toolset"},
+		},
+	}
+
+	profile, err := ResolveProfile("triager", overrides)
+	require.NoError(t, err)
+	assert.Equal(t, ToolsetProfileTriager, profile)
+
+	profile, err = ResolveProfile("reviewer", overrides)
+	require.NoError(t, err)
+	assert.Equal(t, "reviewer", profile.ID)
+	assert.True(t, profile.ReadOnly)
+	assert.Empty(t, profile.ExcludeTools)
+
+	profile, err = ResolveProfile("docs-writer", overrides)
+	require.NoError(t, err)
+	assert.Equal(t, "docs-writer", profile.ID)
+
+	_, err = ResolveProfile("broken", overrides)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid toolsets: not_a_toolset")
+
+	_, err = ResolveProfile("missing", overrides)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "available profiles: broken, ci-doctor, docs-writer, reviewer, security, triager")
+}
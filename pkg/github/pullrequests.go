@@ -135,24 +135,15 @@ func GetPullRequest(ctx context.Context, client *github.Client, cache *lockdown.
 		}
 	}
 
-	if ff.LockdownMode {
-		if cache == nil {
-			return nil, fmt.Errorf("lockdown cache is not configured")
-		}
-		login := pr.GetUser().GetLogin()
-		if login != "" {
-			isSafeContent, err := cache.IsSafeContent(ctx, login, owner, repo)
-			if err != nil {
-				return nil, fmt.Errorf("failed to check content removal: %w", err)
-			}
-
-			if !isSafeContent {
-				return mcp.NewToolResultError("access to pull request is restricted by lockdown mode"), nil
-			}
-		}
+	output, err := applyLockdownItem(ctx, cache, ff, pr, contentOrigin{Login: pr.GetUser().GetLogin(), Owner: owner, Repo: repo}, redactPullRequest)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+	}
+	if output == nil {
+		return mcp.NewToolResultError("access to pull request is restricted by lockdown mode"), nil
 	}
 
-	r, err := json.Marshal(pr)
+	r, err := json.Marshal(output)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal response: %w", err)
 	}
@@ -293,28 +284,14 @@ func GetPullRequestReviewComments(ctx context.Context, client *github.Client, ca
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get pull request review comments: %s", string(body))), nil
 	}
 
-	if ff.LockdownMode {
-		if cache == nil {
-			return nil, fmt.Errorf("lockdown cache is not configured")
-		}
-		filteredComments := make([]*github.PullRequestComment, 0, len(comments))
-		for _, comment := range comments {
-			user := comment.GetUser()
-			if user == nil {
-				continue
-			}
-			isSafeContent, err := cache.IsSafeContent(ctx, user.GetLogin(), owner, repo)
-			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
-			}
-			if isSafeContent {
-				filteredComments = append(filteredComments, comment)
-			}
-		}
-		comments = filteredComments
+	output, err := applyLockdown(ctx, cache, ff, comments, func(comment *github.PullRequestComment) contentOrigin {
+		return contentOrigin{Login: comment.GetUser().GetLogin(), Owner: owner, Repo: repo}
+	}, redactPullRequestComment)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
 	}
 
-	r, err := json.Marshal(comments)
+	r, err := json.Marshal(output)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal response: %w", err)
 	}
@@ -341,27 +318,14 @@ func GetPullRequestReviews(ctx context.Context, client *github.Client, cache *lo
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get pull request reviews: %s", string(body))), nil
 	}
 
-	if ff.LockdownMode {
-		if cache == nil {
-			return nil, fmt.Errorf("lockdown cache is not configured")
-		}
-		filteredReviews := make([]*github.PullRequestReview, 0, len(reviews))
-		for _, review := range reviews {
-			login := review.GetUser().GetLogin()
-			if login != "" {
-				isSafeContent, err := cache.IsSafeContent(ctx, login, owner, repo)
-				if err != nil {
-					return nil, fmt.Errorf("failed to check lockdown mode: %w", err)
-				}
-				if isSafeContent {
-					filteredReviews = append(filteredReviews, review)
-				}
-				reviews = filteredReviews
-			}
-		}
+	output, err := applyLockdown(ctx, cache, ff, reviews, func(review *github.PullRequestReview) contentOrigin {
+		return contentOrigin{Login: review.GetUser().GetLogin(), Owner: owner, Repo: repo}
+	}, redactPullRequestReview)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
 	}
 
-	r, err := json.Marshal(reviews)
+	r, err := json.Marshal(output)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal response: %w", err)
 	}
@@ -901,8 +865,9 @@ func MergePullRequest(getClient GetClientFn, t translations.TranslationHelperFun
 	return mcp.NewTool("merge_pull_request",
 			mcp.WithDescription(t("TOOL_MERGE_PULL_REQUEST_DESCRIPTION", "Merge a pull request in a GitHub repository.")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
-				Title:        t("TOOL_MERGE_PULL_REQUEST_USER_TITLE", "Merge pull request"),
-				ReadOnlyHint: ToBoolPtr(false),
+				Title:           t("TOOL_MERGE_PULL_REQUEST_USER_TITLE", "Merge pull request"),
+				ReadOnlyHint:    ToBoolPtr(false),
+				DestructiveHint: ToBoolPtr(true),
 			}),
 			mcp.WithString("owner",
 				mcp.Required(),
@@ -990,7 +955,7 @@ func MergePullRequest(getClient GetClientFn, t translations.TranslationHelperFun
 }
 
 // SearchPullRequests creates a tool to search for pull requests.
-func SearchPullRequests(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func SearchPullRequests(getClient GetClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("search_pull_requests",
 			mcp.WithDescription(t("TOOL_SEARCH_PULL_REQUESTS_DESCRIPTION", "Search for pull requests in GitHub repositories using issues search syntax already scoped to is:pr")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -1030,7 +995,7 @@ func SearchPullRequests(getClient GetClientFn, t translations.TranslationHelperF
 			WithPagination(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			return searchHandler(ctx, getClient, request, "pr", "failed to search pull requests")
+			return searchHandler(ctx, getClient, cache, flags, request, "pr", "failed to search pull requests")
 		}
 }
 
@@ -0,0 +1,657 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+
+	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxReleaseAssetDownloadBytes is the size of the largest release asset returned by release_asset_read.
+const maxReleaseAssetDownloadBytes = 1 << 20
+
+// withReleaseFields adds the parameters shared by create_release and update_release.
+func withReleaseFields() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString("target_commitish",
+			mcp.Description("Branch or commit SHA the tag is created from, if it does not exist yet. Defaults to the default branch"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Release title"),
+		),
+		mcp.WithString("body",
+			mcp.Description("Release notes in Markdown"),
+		),
+		mcp.WithBoolean("draft",
+			mcp.Description("Whether the release is a draft, visible only to collaborators until it is published"),
+		),
+		mcp.WithBoolean("prerelease",
+			mcp.Description("Whether the release is a prerelease"),
+		),
+		mcp.WithString("make_latest",
+			mcp.Description("Whether the release becomes the latest release. 'legacy' picks the latest release by creation date and version"),
+			mcp.Enum("true", "false", "legacy"),
+		),
+	}
+}
+
+// releaseFromRequest reads the parameters added by withReleaseFields. Only the parameters that are present are
+// set, so that update_release leaves the other fields unchanged.
+func releaseFromRequest(request mcp.CallToolRequest) (*github.RepositoryRelease, error) {
+	release := &github.RepositoryRelease{}
+	for name, field := range map[string]**string{
+		"tag_name":         &release.TagName,
+		"target_commitish": &release.TargetCommitish,
+		"name":             &release.Name,
+		"body":             &release.Body,
+		"make_latest":      &release.MakeLatest,
+	} {
+		value, ok, err := OptionalParamOK[string](request, name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			*field = github.Ptr(value)
+		}
+	}
+	for name, field := range map[string]**bool{
+		"draft":      &release.Draft,
+		"prerelease": &release.Prerelease,
+	} {
+		value, ok, err := OptionalParamOK[bool](request, name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			*field = github.Ptr(value)
+		}
+	}
+	return release, nil
+}
+
+// CreateRelease creates a tool to create a release in a GitHub repository.
+func CreateRelease(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	options := []mcp.ToolOption{
+		mcp.WithDescription(t("TOOL_CREATE_RELEASE_DESCRIPTION", "Create a release in a GitHub repository. The tag is created if it does not exist. Create a draft to review the release before publishing it.")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        t("TOOL_CREATE_RELEASE_USER_TITLE", "Create release"),
+			ReadOnlyHint: ToBoolPtr(false),
+		}),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("Repository owner"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("Repository name"),
+		),
+		mcp.WithString("tag_name",
+			mcp.Required(),
+			mcp.Description("Tag of the release (e.g., 'v1.0.0')"),
+		),
+		mcp.WithBoolean("generate_release_notes",
+			mcp.Description("Whether to generate the name and notes of the release from the pull requests merged since the previous release. A given body is prepended to the generated notes"),
+		),
+	}
+	return mcp.NewTool("create_release", append(options, withReleaseFields()...)...),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if _, err := RequiredParam[string](request, "tag_name"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			release, err := releaseFromRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			generateNotes, err := OptionalParam[bool](request, "generate_release_notes")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if generateNotes {
+				release.GenerateReleaseNotes = github.Ptr(true)
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+			created, resp, err := client.Repositories.CreateRelease(ctx, owner, repo, release)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					fmt.Sprintf("failed to create release: %s", release.GetTagName()),
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			return releaseResponse(created)
+		}
+}
+
+// UpdateRelease creates a tool to update a release in a GitHub repository.
+func UpdateRelease(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	options := []mcp.ToolOption{
+		mcp.WithDescription(t("TOOL_UPDATE_RELEASE_DESCRIPTION", "Update a release in a GitHub repository. Only the given fields are changed. Set draft to false to publish a draft release.")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        t("TOOL_UPDATE_RELEASE_USER_TITLE", "Update release"),
+			ReadOnlyHint: ToBoolPtr(false),
+		}),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("Repository owner"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("Repository name"),
+		),
+		mcp.WithNumber("release_id",
+			mcp.Required(),
+			mcp.Description("ID of the release"),
+		),
+		mcp.WithString("tag_name",
+			mcp.Description("New tag of the release"),
+		),
+	}
+	return mcp.NewTool("update_release", append(options, withReleaseFields()...)...),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			releaseID, err := RequiredBigInt(request, "release_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			release, err := releaseFromRequest(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+			updated, resp, err := client.Repositories.EditRelease(ctx, owner, repo, releaseID, release)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					fmt.Sprintf("failed to update release: %d", releaseID),
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			return releaseResponse(updated)
+		}
+}
+
+func releaseResponse(release *github.RepositoryRelease) (*mcp.CallToolResult, error) {
+	minimalResponse := MinimalResponse{
+		ID:  fmt.Sprintf("%d", release.GetID()),
+		URL: release.GetHTMLURL(),
+	}
+
+	r, err := json.Marshal(minimalResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return mcp.NewToolResultText(string(r)), nil
+}
+
+// DeleteRelease creates a tool to delete a release from a GitHub repository.
+func DeleteRelease(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("delete_release",
+			mcp.WithDescription(t("TOOL_DELETE_RELEASE_DESCRIPTION", "Delete a release and its assets from a GitHub repository. The tag of the release is kept.")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:           t("TOOL_DELETE_RELEASE_USER_TITLE", "Delete release"),
+				ReadOnlyHint:    ToBoolPtr(false),
+				DestructiveHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithNumber("release_id",
+				mcp.Required(),
+				mcp.Description("ID of the release"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			releaseID, err := RequiredBigInt(request, "release_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+			resp, err := client.Repositories.DeleteRelease(ctx, owner, repo, releaseID)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					fmt.Sprintf("failed to delete release: %d", releaseID),
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			return mcp.NewToolResultText(fmt.Sprintf("deleted release %d", releaseID)), nil
+		}
+}
+
+// GenerateReleaseNotes creates a tool to generate the notes of a release without creating it.
+func GenerateReleaseNotes(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("generate_release_notes",
+			mcp.WithDescription(t("TOOL_GENERATE_RELEASE_NOTES_DESCRIPTION", "Generate the name and notes of a release from the pull requests merged between two tags, without creating the release")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_GENERATE_RELEASE_NOTES_USER_TITLE", "Generate release notes"),
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("tag_name",
+				mcp.Required(),
+				mcp.Description("Tag of the release. It does not need to exist yet"),
+			),
+			mcp.WithString("previous_tag_name",
+				mcp.Description("Tag of the previous release to start the notes from. Defaults to the latest release"),
+			),
+			mcp.WithString("target_commitish",
+				mcp.Description("Branch or commit SHA the release is created from, if tag_name does not exist yet"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			tagName, err := RequiredParam[string](request, "tag_name")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			opts := &github.GenerateNotesOptions{TagName: tagName}
+			for name, field := range map[string]**string{
+				"previous_tag_name": &opts.PreviousTagName,
+				"target_commitish":  &opts.TargetCommitish,
+			} {
+				value, err := OptionalParam[string](request, name)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				if value != "" {
+					*field = github.Ptr(value)
+				}
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+			notes, resp, err := client.Repositories.GenerateReleaseNotes(ctx, owner, repo, opts)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					fmt.Sprintf("failed to generate release notes: %s", tagName),
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			r, err := json.Marshal(notes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
+
+// MinimalReleaseAsset is the trimmed output type for release asset objects.
+type MinimalReleaseAsset struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Label         string `json:"label,omitempty"`
+	ContentType   string `json:"content_type"`
+	Size          int    `json:"size"`
+	DownloadCount int    `json:"download_count"`
+	DownloadURL   string `json:"browser_download_url"`
+	UpdatedAt     string `json:"updated_at,omitempty"`
+}
+
+func convertToMinimalReleaseAsset(asset *github.ReleaseAsset) MinimalReleaseAsset {
+	minimalAsset := MinimalReleaseAsset{
+		ID:            asset.GetID(),
+		Name:          asset.GetName(),
+		Label:         asset.GetLabel(),
+		ContentType:   asset.GetContentType(),
+		Size:          asset.GetSize(),
+		DownloadCount: asset.GetDownloadCount(),
+		DownloadURL:   asset.GetBrowserDownloadURL(),
+	}
+	if asset.UpdatedAt != nil {
+		minimalAsset.UpdatedAt = asset.UpdatedAt.Format("2006-01-02T15:04:05Z")
+	}
+	return minimalAsset
+}
+
+// ReleaseAssetRead creates a tool to list the assets of a release, or download one of them.
+func ReleaseAssetRead(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("release_asset_read",
+			mcp.WithDescription(t("TOOL_RELEASE_ASSET_READ_DESCRIPTION", "List the assets of a release in a GitHub repository, or download the content of an asset")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_RELEASE_ASSET_READ_USER_TITLE", "Read release assets"),
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("method",
+				mcp.Required(),
+				mcp.Description(`The read operation to perform:
+1. list - List the assets of the release with release_id.
+2. download - Download the asset with asset_id. Text assets are returned as text, and others base64 encoded.
+`),
+				mcp.Enum("list", "download"),
+			),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithNumber("release_id",
+				mcp.Description("ID of the release, for the list method"),
+			),
+			mcp.WithNumber("asset_id",
+				mcp.Description("ID of the asset, for the download method"),
+			),
+			WithPagination(),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			method, err := RequiredParam[string](request, "method")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			switch method {
+			case "list":
+				releaseID, err := RequiredBigInt(request, "release_id")
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				pagination, err := OptionalPaginationParams(request)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				return listReleaseAssets(ctx, client, owner, repo, releaseID, pagination)
+			case "download":
+				assetID, err := RequiredBigInt(request, "asset_id")
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				return downloadReleaseAsset(ctx, client, owner, repo, assetID)
+			default:
+				return mcp.NewToolResultError(fmt.Sprintf("unknown method: %s", method)), nil
+			}
+		}
+}
+
+func listReleaseAssets(ctx context.Context, client *github.Client, owner, repo string, releaseID int64, pagination PaginationParams) (*mcp.CallToolResult, error) {
+	assets, resp, err := client.Repositories.ListReleaseAssets(ctx, owner, repo, releaseID, &github.ListOptions{
+		Page:    pagination.Page,
+		PerPage: pagination.PerPage,
+	})
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(ctx,
+			fmt.Sprintf("failed to list assets of release: %d", releaseID),
+			resp,
+			err,
+		), nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	minimalAssets := make([]MinimalReleaseAsset, 0, len(assets))
+	for _, asset := range assets {
+		minimalAssets = append(minimalAssets, convertToMinimalReleaseAsset(asset))
+	}
+
+	r, err := json.Marshal(minimalAssets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return mcp.NewToolResultText(string(r)), nil
+}
+
+func downloadReleaseAsset(ctx context.Context, client *github.Client, owner, repo string, assetID int64) (*mcp.CallToolResult, error) {
+	asset, resp, err := client.Repositories.GetReleaseAsset(ctx, owner, repo, assetID)
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(ctx,
+			fmt.Sprintf("failed to get release asset: %d", assetID),
+			resp,
+			err,
+		), nil
+	}
+	_ = resp.Body.Close()
+	if asset.GetSize() > maxReleaseAssetDownloadBytes {
+		return mcp.NewToolResultError(fmt.Sprintf("release asset %s is %d bytes, more than the %d bytes that can be downloaded: download it from %s instead",
+			asset.GetName(), asset.GetSize(), maxReleaseAssetDownloadBytes, asset.GetBrowserDownloadURL())), nil
+	}
+
+	// Assets are served from a storage host after a redirect, which needs no authentication
+	rc, _, err := client.Repositories.DownloadReleaseAsset(ctx, owner, repo, assetID, http.DefaultClient)
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(ctx,
+			fmt.Sprintf("failed to download release asset: %d", assetID),
+			nil,
+			err,
+		), nil
+	}
+	defer func() { _ = rc.Close() }()
+	content, err := io.ReadAll(io.LimitReader(rc, maxReleaseAssetDownloadBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release asset: %w", err)
+	}
+	if len(content) > maxReleaseAssetDownloadBytes {
+		return mcp.NewToolResultError(fmt.Sprintf("release asset %s is more than the %d bytes that can be downloaded: download it from %s instead",
+			asset.GetName(), maxReleaseAssetDownloadBytes, asset.GetBrowserDownloadURL())), nil
+	}
+
+	if isTextContentType(asset.GetContentType()) {
+		return mcp.NewToolResultResource(fmt.Sprintf("successfully downloaded text release asset %s", asset.GetName()), mcp.TextResourceContents{
+			URI:      asset.GetBrowserDownloadURL(),
+			Text:     string(content),
+			MIMEType: asset.GetContentType(),
+		}), nil
+	}
+	return mcp.NewToolResultResource(fmt.Sprintf("successfully downloaded binary release asset %s", asset.GetName()), mcp.BlobResourceContents{
+		URI:      asset.GetBrowserDownloadURL(),
+		Blob:     base64.StdEncoding.EncodeToString(content),
+		MIMEType: asset.GetContentType(),
+	}), nil
+}
+
+// UploadReleaseAsset creates a tool to upload an asset to a release from content.
+func UploadReleaseAsset(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("upload_release_asset",
+			mcp.WithDescription(t("TOOL_UPLOAD_RELEASE_ASSET_DESCRIPTION", "Upload a file as an asset of a release in a GitHub repository")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_UPLOAD_RELEASE_ASSET_USER_TITLE", "Upload release asset"),
+				ReadOnlyHint: ToBoolPtr(false),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithNumber("release_id",
+				mcp.Required(),
+				mcp.Description("ID of the release"),
+			),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("File name of the asset"),
+			),
+			mcp.WithString("content",
+				mcp.Required(),
+				mcp.Description("Content of the asset"),
+			),
+			mcp.WithString("encoding",
+				mcp.Description("Encoding of content: utf-8 (default) or base64 for binary files"),
+				mcp.Enum("utf-8", "base64"),
+			),
+			mcp.WithString("content_type",
+				mcp.Description("Media type of the asset. Defaults to the type of its file extension, or application/octet-stream"),
+			),
+			mcp.WithString("label",
+				mcp.Description("Short description of the asset shown instead of its name"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			releaseID, err := RequiredBigInt(request, "release_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			name, err := RequiredParam[string](request, "name")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			content, err := RequiredParam[string](request, "content")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			encoding, err := OptionalParam[string](request, "encoding")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			contentType, err := OptionalParam[string](request, "content_type")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			label, err := OptionalParam[string](request, "label")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			data := []byte(content)
+			switch encoding {
+			case "", "utf-8":
+			case "base64":
+				if data, err = base64.StdEncoding.DecodeString(content); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("content is not valid base64: %v", err)), nil
+				}
+			default:
+				return mcp.NewToolResultError(fmt.Sprintf("unknown encoding %q: must be utf-8 or base64", encoding)), nil
+			}
+			if contentType == "" {
+				contentType = mime.TypeByExtension(path.Ext(name))
+			}
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+
+			// Uploads go to the upload host of the client, rather than the API host
+			query := url.Values{"name": {name}}
+			if label != "" {
+				query.Set("label", label)
+			}
+			u := fmt.Sprintf("repos/%s/%s/releases/%d/assets?%s", owner, repo, releaseID, query.Encode())
+			req, err := client.NewUploadRequest(u, bytes.NewReader(data), int64(len(data)), contentType)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create upload request: %w", err)
+			}
+			asset := new(github.ReleaseAsset)
+			resp, err := client.Do(ctx, req, asset)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					fmt.Sprintf("failed to upload release asset: %s", name),
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			r, err := json.Marshal(convertToMinimalReleaseAsset(asset))
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
@@ -0,0 +1,495 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/github/github-mcp-server/internal/toolsnaps"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v79/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateRelease(t *testing.T) {
+	tool, _ := CreateRelease(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "create_release", tool.Name)
+	assert.Contains(t, tool.InputSchema.Properties, "draft")
+	assert.Contains(t, tool.InputSchema.Properties, "prerelease")
+	assert.Contains(t, tool.InputSchema.Properties, "generate_release_notes")
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "tag_name"})
+
+	createdRelease := &github.RepositoryRelease{
+		ID:      github.Ptr(int64(42)),
+		HTMLURL: github.Ptr("https://github.com/owner/repo/releases/tag/v1.0.0"),
+	}
+
+	tests := []struct {
+		name           string
+		mockedClient   *http.Client
+		requestArgs    map[string]interface{}
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "creates draft prerelease with generated notes",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PostReposReleasesByOwnerByRepo,
+					expectRequestBody(t, map[string]any{
+						"tag_name":               "v1.0.0",
+						"target_commitish":       "main",
+						"name":                   "v1.0.0",
+						"draft":                  true,
+						"prerelease":             true,
+						"generate_release_notes": true,
+					}).andThen(
+						mockResponse(t, http.StatusCreated, createdRelease),
+					),
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":                  "owner",
+				"repo":                   "repo",
+				"tag_name":               "v1.0.0",
+				"target_commitish":       "main",
+				"name":                   "v1.0.0",
+				"draft":                  true,
+				"prerelease":             true,
+				"generate_release_notes": true,
+			},
+		},
+		{
+			name: "release already exists",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PostReposReleasesByOwnerByRepo,
+					mockResponse(t, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"}),
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":    "owner",
+				"repo":     "repo",
+				"tag_name": "v1.0.0",
+			},
+			expectError:    true,
+			expectedErrMsg: "failed to create release: v1.0.0",
+		},
+		{
+			name:         "missing tag name",
+			mockedClient: mock.NewMockedHTTPClient(),
+			requestArgs: map[string]interface{}{
+				"owner": "owner",
+				"repo":  "repo",
+			},
+			expectError:    true,
+			expectedErrMsg: "missing required parameter: tag_name",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, handler := CreateRelease(stubGetClientFn(github.NewClient(tc.mockedClient)), translations.NullTranslationHelper)
+			result, err := handler(context.Background(), createMCPRequest(tc.requestArgs))
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			textContent := getTextResult(t, result)
+			var response MinimalResponse
+			require.NoError(t, json.Unmarshal([]byte(textContent.Text), &response))
+			assert.Equal(t, "42", response.ID)
+			assert.Equal(t, createdRelease.GetHTMLURL(), response.URL)
+		})
+	}
+}
+
+func Test_UpdateRelease(t *testing.T) {
+	tool, _ := UpdateRelease(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "update_release", tool.Name)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "release_id"})
+
+	// Only the given fields are sent, so that publishing a draft keeps its notes
+	mockedClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.PatchReposReleasesByOwnerByRepoByReleaseId,
+			expect(t, expectations{
+				path:        "/repos/owner/repo/releases/42",
+				requestBody: map[string]any{"draft": false},
+			}).andThen(
+				mockResponse(t, http.StatusOK, &github.RepositoryRelease{
+					ID:      github.Ptr(int64(42)),
+					HTMLURL: github.Ptr("https://github.com/owner/repo/releases/tag/v1.0.0"),
+				}),
+			),
+		),
+	)
+	_, handler := UpdateRelease(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+	result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+		"owner":      "owner",
+		"repo":       "repo",
+		"release_id": float64(42),
+		"draft":      false,
+	}))
+	require.NoError(t, err)
+
+	textContent := getTextResult(t, result)
+	var response MinimalResponse
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &response))
+	assert.Equal(t, "42", response.ID)
+}
+
+func Test_DeleteRelease(t *testing.T) {
+	tool, _ := DeleteRelease(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "delete_release", tool.Name)
+	assert.True(t, *tool.Annotations.DestructiveHint)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "release_id"})
+
+	tests := []struct {
+		name           string
+		mockedClient   *http.Client
+		expectError    bool
+		expectedErrMsg string
+	}{
+		{
+			name: "deletes release",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.DeleteReposReleasesByOwnerByRepoByReleaseId,
+					expectPath(t, "/repos/owner/repo/releases/42").andThen(
+						mockResponse(t, http.StatusNoContent, nil),
+					),
+				),
+			),
+		},
+		{
+			name: "release not found",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.DeleteReposReleasesByOwnerByRepoByReleaseId,
+					mockResponse(t, http.StatusNotFound, map[string]string{"message": "Not Found"}),
+				),
+			),
+			expectError:    true,
+			expectedErrMsg: "failed to delete release: 42",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, handler := DeleteRelease(stubGetClientFn(github.NewClient(tc.mockedClient)), translations.NullTranslationHelper)
+			result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+				"owner":      "owner",
+				"repo":       "repo",
+				"release_id": float64(42),
+			}))
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+			assert.Equal(t, "deleted release 42", getTextResult(t, result).Text)
+		})
+	}
+}
+
+func Test_GenerateReleaseNotes(t *testing.T) {
+	tool, _ := GenerateReleaseNotes(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "generate_release_notes", tool.Name)
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "tag_name"})
+
+	mockedClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.PostReposReleasesGenerateNotesByOwnerByRepo,
+			expectRequestBody(t, map[string]any{
+				"tag_name":          "v1.1.0",
+				"previous_tag_name": "v1.0.0",
+			}).andThen(
+				mockResponse(t, http.StatusOK, &github.RepositoryReleaseNotes{
+					Name: "v1.1.0",
+					Body: "## What's Changed\n* Fix bug by @octocat in #12",
+				}),
+			),
+		),
+	)
+	_, handler := GenerateReleaseNotes(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+	result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+		"owner":             "owner",
+		"repo":              "repo",
+		"tag_name":          "v1.1.0",
+		"previous_tag_name": "v1.0.0",
+	}))
+	require.NoError(t, err)
+
+	textContent := getTextResult(t, result)
+	var notes github.RepositoryReleaseNotes
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &notes))
+	assert.Equal(t, "v1.1.0", notes.Name)
+	assert.Contains(t, notes.Body, "Fix bug")
+}
+
+func Test_ReleaseAssetRead(t *testing.T) {
+	tool, _ := ReleaseAssetRead(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "release_asset_read", tool.Name)
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"method", "owner", "repo"})
+
+	textAsset := &github.ReleaseAsset{
+		ID:                 github.Ptr(int64(7)),
+		Name:               github.Ptr("checksums.txt"),
+		ContentType:        github.Ptr("text/plain"),
+		Size:               github.Ptr(14),
+		BrowserDownloadURL: github.Ptr("https://github.com/owner/repo/releases/download/v1.0.0/checksums.txt"),
+	}
+	binaryAsset := &github.ReleaseAsset{
+		ID:                 github.Ptr(int64(8)),
+		Name:               github.Ptr("tool.tar.gz"),
+		ContentType:        github.Ptr("application/gzip"),
+		Size:               github.Ptr(3),
+		BrowserDownloadURL: github.Ptr("https://github.com/owner/repo/releases/download/v1.0.0/tool.tar.gz"),
+	}
+
+	// Metadata and content of an asset are served from the same path, depending on the Accept header
+	assetHandler := func(asset *github.ReleaseAsset, content string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Accept") == "application/octet-stream" {
+				w.Header().Set("Content-Type", asset.GetContentType())
+				_, _ = w.Write([]byte(content))
+				return
+			}
+			mockResponse(t, http.StatusOK, asset)(w, r)
+		}
+	}
+
+	t.Run("list", func(t *testing.T) {
+		mockedClient := mock.NewMockedHTTPClient(
+			mock.WithRequestMatchHandler(
+				mock.GetReposReleasesAssetsByOwnerByRepoByReleaseId,
+				expect(t, expectations{
+					path:        "/repos/owner/repo/releases/42/assets",
+					queryParams: map[string]string{"page": "1", "per_page": "30"},
+				}).andThen(
+					mockResponse(t, http.StatusOK, []*github.ReleaseAsset{textAsset, binaryAsset}),
+				),
+			),
+		)
+		_, handler := ReleaseAssetRead(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+		result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+			"method":     "list",
+			"owner":      "owner",
+			"repo":       "repo",
+			"release_id": float64(42),
+		}))
+		require.NoError(t, err)
+
+		var assets []MinimalReleaseAsset
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &assets))
+		require.Len(t, assets, 2)
+		assert.Equal(t, "checksums.txt", assets[0].Name)
+		assert.Equal(t, textAsset.GetBrowserDownloadURL(), assets[0].DownloadURL)
+	})
+
+	t.Run("download text asset", func(t *testing.T) {
+		mockedClient := mock.NewMockedHTTPClient(
+			mock.WithRequestMatchHandler(
+				mock.GetReposReleasesAssetsByOwnerByRepoByAssetId,
+				assetHandler(textAsset, "abc  tool.tar.gz"),
+			),
+		)
+		_, handler := ReleaseAssetRead(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+		result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+			"method":   "download",
+			"owner":    "owner",
+			"repo":     "repo",
+			"asset_id": float64(7),
+		}))
+		require.NoError(t, err)
+
+		resource := getTextResourceResult(t, result)
+		assert.Equal(t, "abc  tool.tar.gz", resource.Text)
+		assert.Equal(t, textAsset.GetBrowserDownloadURL(), resource.URI)
+	})
+
+	t.Run("download binary asset", func(t *testing.T) {
+		mockedClient := mock.NewMockedHTTPClient(
+			mock.WithRequestMatchHandler(
+				mock.GetReposReleasesAssetsByOwnerByRepoByAssetId,
+				assetHandler(binaryAsset, "\x1f\x8b\x08"),
+			),
+		)
+		_, handler := ReleaseAssetRead(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+		result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+			"method":   "download",
+			"owner":    "owner",
+			"repo":     "repo",
+			"asset_id": float64(8),
+		}))
+		require.NoError(t, err)
+
+		resource := getBlobResourceResult(t, result)
+		assert.Equal(t, "H4sI", resource.Blob)
+		assert.Equal(t, "application/gzip", resource.MIMEType)
+	})
+
+	t.Run("asset too large to download", func(t *testing.T) {
+		largeAsset := *binaryAsset
+		largeAsset.Size = github.Ptr(maxReleaseAssetDownloadBytes + 1)
+		mockedClient := mock.NewMockedHTTPClient(
+			mock.WithRequestMatch(mock.GetReposReleasesAssetsByOwnerByRepoByAssetId, &largeAsset),
+		)
+		_, handler := ReleaseAssetRead(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+		result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+			"method":   "download",
+			"owner":    "owner",
+			"repo":     "repo",
+			"asset_id": float64(8),
+		}))
+		require.NoError(t, err)
+
+		errorContent := getErrorResult(t, result)
+		assert.Contains(t, errorContent.Text, binaryAsset.GetBrowserDownloadURL())
+	})
+}
+
+func Test_UploadReleaseAsset(t *testing.T) {
+	tool, _ := UploadReleaseAsset(stubGetClientFn(github.NewClient(nil)), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "upload_release_asset", tool.Name)
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "release_id", "name", "content"})
+
+	uploadHandler := func(expectedQuery map[string]string, expectedContentType, expectedContent string) http.HandlerFunc {
+		return expect(t, expectations{
+			path:        "/repos/owner/repo/releases/42/assets",
+			queryParams: expectedQuery,
+		}).andThen(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, expectedContent, string(body))
+			assert.Equal(t, expectedContentType, r.Header.Get("Content-Type"))
+			mockResponse(t, http.StatusCreated, &github.ReleaseAsset{
+				ID:          github.Ptr(int64(9)),
+				Name:        github.Ptr(r.URL.Query().Get("name")),
+				ContentType: github.Ptr(expectedContentType),
+				Size:        github.Ptr(len(body)),
+			})(w, r)
+		})
+	}
+
+	tests := []struct {
+		name           string
+		mockedClient   *http.Client
+		requestArgs    map[string]interface{}
+		expectError    bool
+		expectedErrMsg string
+		expectedAsset  MinimalReleaseAsset
+	}{
+		{
+			name: "uploads text with type from extension",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PostReposReleasesAssetsByOwnerByRepoByReleaseId,
+					uploadHandler(map[string]string{"name": "notes.json", "label": "Notes"}, "application/json", `{"ok":true}`),
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":      "owner",
+				"repo":       "repo",
+				"release_id": float64(42),
+				"name":       "notes.json",
+				"label":      "Notes",
+				"content":    `{"ok":true}`,
+			},
+			expectedAsset: MinimalReleaseAsset{ID: 9, Name: "notes.json", ContentType: "application/json", Size: 11},
+		},
+		{
+			name: "uploads base64 content",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PostReposReleasesAssetsByOwnerByRepoByReleaseId,
+					uploadHandler(map[string]string{"name": "tool"}, "application/octet-stream", "\x1f\x8b\x08"),
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":      "owner",
+				"repo":       "repo",
+				"release_id": float64(42),
+				"name":       "tool",
+				"content":    "H4sI",
+				"encoding":   "base64",
+			},
+			expectedAsset: MinimalReleaseAsset{ID: 9, Name: "tool", ContentType: "application/octet-stream", Size: 3},
+		},
+		{
+			name:         "invalid base64 content",
+			mockedClient: mock.NewMockedHTTPClient(),
+			requestArgs: map[string]interface{}{
+				"owner":      "owner",
+				"repo":       "repo",
+				"release_id": float64(42),
+				"name":       "tool",
+				"content":    "not base64!",
+				"encoding":   "base64",
+			},
+			expectError:    true,
+			expectedErrMsg: "content is not valid base64",
+		},
+		{
+			name: "asset with the same name exists",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.PostReposReleasesAssetsByOwnerByRepoByReleaseId,
+					mockResponse(t, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"}),
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":      "owner",
+				"repo":       "repo",
+				"release_id": float64(42),
+				"name":       "notes.txt",
+				"content":    "notes",
+			},
+			expectError:    true,
+			expectedErrMsg: "failed to upload release asset: notes.txt",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, handler := UploadReleaseAsset(stubGetClientFn(github.NewClient(tc.mockedClient)), translations.NullTranslationHelper)
+			result, err := handler(context.Background(), createMCPRequest(tc.requestArgs))
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			var asset MinimalReleaseAsset
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &asset))
+			assert.Equal(t, tc.expectedAsset, asset)
+		})
+	}
+}
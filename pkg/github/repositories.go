@@ -1,6 +1,7 @@
 package github
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -8,9 +9,15 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"path"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/lockdown"
 	"github.com/github/github-mcp-server/pkg/raw"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/google/go-github/v79/github"
@@ -18,7 +25,7 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
-func GetCommit(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func GetCommit(getClient GetClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_commit",
 			mcp.WithDescription(t("TOOL_GET_COMMITS_DESCRIPTION", "Get details for a commit from a GitHub repository")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -95,7 +102,15 @@ func GetCommit(getClient GetClientFn, t translations.TranslationHelperFunc) (too
 			// Convert to minimal commit
 			minimalCommit := convertToMinimalCommit(commit, includeDiff)
 
-			r, err := json.Marshal(minimalCommit)
+			output, err := applyLockdownItem(ctx, cache, flags, minimalCommit, contentOrigin{Login: commit.GetAuthor().GetLogin(), Owner: owner, Repo: repo}, redactMinimalCommit)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+			}
+			if output == nil {
+				return mcp.NewToolResultError("access to commit is restricted by lockdown mode"), nil
+			}
+
+			r, err := json.Marshal(output)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
@@ -105,7 +120,7 @@ func GetCommit(getClient GetClientFn, t translations.TranslationHelperFunc) (too
 }
 
 // ListCommits creates a tool to get commits of a branch in a repository.
-func ListCommits(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func ListCommits(getClient GetClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("list_commits",
 			mcp.WithDescription(t("TOOL_LIST_COMMITS_DESCRIPTION", "Get list of commits of a branch in a GitHub repository. Returns at least 30 results per page by default, but can return more if specified using the perPage parameter (up to 100).")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -191,7 +206,175 @@ func ListCommits(getClient GetClientFn, t translations.TranslationHelperFunc) (t
 				minimalCommits[i] = convertToMinimalCommit(commit, false)
 			}
 
-			r, err := json.Marshal(minimalCommits)
+			output, err := applyLockdown(ctx, cache, flags, minimalCommits, func(commit MinimalCommit) contentOrigin {
+				origin := contentOrigin{Owner: owner, Repo: repo}
+				if commit.Author != nil {
+					origin.Login = commit.Author.Login
+				}
+				return origin
+			}, redactMinimalCommit)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+			}
+
+			r, err := json.Marshal(output)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
+
+// defaultComparePatchBytes is the default total size of the patches returned by compare_refs.
+const defaultComparePatchBytes = 50000
+
+// CompareRefs creates a tool to compare two refs of a repository, or of forks in its network.
+func CompareRefs(getClient GetClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("compare_refs",
+			mcp.WithDescription(t("TOOL_COMPARE_REFS_DESCRIPTION", "Compare two commits, branches or tags of a GitHub repository, including branches of forks. Returns how far head is ahead of and behind base, the commits in head that are not in base, and the changed files, optionally with their patches.")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_COMPARE_REFS_USER_TITLE", "Compare refs"),
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithString("base",
+				mcp.Required(),
+				mcp.Description("Commit SHA, branch or tag name to compare from. Use owner:branch for a branch of a fork in the same network"),
+			),
+			mcp.WithString("head",
+				mcp.Required(),
+				mcp.Description("Commit SHA, branch or tag name to compare to. Use owner:branch for a branch of a fork in the same network"),
+			),
+			mcp.WithBoolean("include_patch",
+				mcp.Description("Whether to include the patch of each changed file. Default is false."),
+			),
+			mcp.WithString("path_filter",
+				mcp.Description("Only include changed files matching this glob, such as 'docs/*.md', or under this directory, such as 'src/api/'"),
+			),
+			mcp.WithNumber("max_patch_bytes",
+				mcp.Description(fmt.Sprintf("Output size budget: the total size of the returned patches in bytes. Patches beyond it are omitted and marked with patch_omitted. Default is %d.", defaultComparePatchBytes)),
+				mcp.Min(1),
+			),
+			WithPagination(),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			base, err := RequiredParam[string](request, "base")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			head, err := RequiredParam[string](request, "head")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			includePatch, err := OptionalParam[bool](request, "include_patch")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			pathFilter, err := OptionalParam[string](request, "path_filter")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if _, err := path.Match(pathFilter, ""); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid path_filter %q: %v", pathFilter, err)), nil
+			}
+			maxPatchBytes, err := OptionalIntParamWithDefault(request, "max_patch_bytes", defaultComparePatchBytes)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			pagination, err := OptionalPaginationParams(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+			comparison, resp, err := client.Repositories.CompareCommits(ctx, owner, repo, base, head, &github.ListOptions{
+				Page:    pagination.Page,
+				PerPage: pagination.PerPage,
+			})
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					fmt.Sprintf("failed to compare %s...%s", base, head),
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			result := MinimalComparison{
+				Status:         comparison.GetStatus(),
+				AheadBy:        comparison.GetAheadBy(),
+				BehindBy:       comparison.GetBehindBy(),
+				TotalCommits:   comparison.GetTotalCommits(),
+				MergeBaseSHA:   comparison.GetMergeBaseCommit().GetSHA(),
+				HTMLURL:        comparison.GetHTMLURL(),
+				Files:          []MinimalComparedFile{},
+				TotalFiles:     len(comparison.Files),
+				FilesTruncated: len(comparison.Files) >= maxComparedFiles,
+			}
+
+			minimalCommits := make([]MinimalCommit, len(comparison.Commits))
+			for i, commit := range comparison.Commits {
+				minimalCommits[i] = convertToMinimalCommit(commit, false)
+			}
+			result.Commits, err = applyLockdown(ctx, cache, flags, minimalCommits, func(commit MinimalCommit) contentOrigin {
+				origin := contentOrigin{Owner: owner, Repo: repo}
+				if commit.Author != nil {
+					origin.Login = commit.Author.Login
+				}
+				return origin
+			}, redactMinimalCommit)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+			}
+			if result.Commits == nil {
+				result.Commits = []any{}
+			}
+
+			patchBytes := 0
+			for _, file := range comparison.Files {
+				if pathFilter != "" && !matchPathFilter(pathFilter, file.GetFilename()) && !matchPathFilter(pathFilter, file.GetPreviousFilename()) {
+					continue
+				}
+				compared := MinimalComparedFile{
+					Filename:         file.GetFilename(),
+					PreviousFilename: file.GetPreviousFilename(),
+					Status:           file.GetStatus(),
+					Additions:        file.GetAdditions(),
+					Deletions:        file.GetDeletions(),
+					Changes:          file.GetChanges(),
+				}
+				if patch := file.GetPatch(); includePatch && patch != "" {
+					// Smaller patches later in the list may still fit once a large one is omitted
+					if patchBytes+len(patch) <= maxPatchBytes {
+						compared.Patch = patch
+						patchBytes += len(patch)
+					} else {
+						compared.PatchOmitted = true
+					}
+				}
+				result.Files = append(result.Files, compared)
+			}
+
+			r, err := json.Marshal(result)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
@@ -200,6 +383,18 @@ func ListCommits(getClient GetClientFn, t translations.TranslationHelperFunc) (t
 		}
 }
 
+// matchPathFilter reports whether p matches the glob filter, or is under the directory filter.
+func matchPathFilter(filter, p string) bool {
+	if p == "" {
+		return false
+	}
+	if dir := strings.TrimSuffix(filter, "/"); strings.HasPrefix(p, dir+"/") {
+		return true
+	}
+	matched, _ := path.Match(filter, p)
+	return matched
+}
+
 // ListBranches creates a tool to list branches in a GitHub repository.
 func ListBranches(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("list_branches",
@@ -507,6 +702,22 @@ func GetFileContents(getClient GetClientFn, getRawClient raw.GetRawClientFn, t t
 			mcp.WithString("sha",
 				mcp.Description("Accepts optional commit SHA. If specified, it will be used instead of ref"),
 			),
+			mcp.WithNumber("start_line",
+				mcp.Description("First line of the file to return, starting at 1. Only for text files"),
+				mcp.Min(1),
+			),
+			mcp.WithNumber("end_line",
+				mcp.Description("Last line of the file to return, inclusive. Defaults to the end of the file. Only for text files"),
+				mcp.Min(1),
+			),
+			mcp.WithNumber("byte_offset",
+				mcp.Description("Offset of the first byte of the file to return. Cannot be combined with start_line or end_line"),
+				mcp.Min(0),
+			),
+			mcp.WithNumber("byte_length",
+				mcp.Description("Number of bytes of the file to return. Defaults to the end of the file. Cannot be combined with start_line or end_line"),
+				mcp.Min(1),
+			),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -529,6 +740,37 @@ func GetFileContents(getClient GetClientFn, getRawClient raw.GetRawClientFn, t t
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
+			startLine, err := OptionalIntParam(request, "start_line")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			endLine, err := OptionalIntParam(request, "end_line")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			byteOffset, err := OptionalIntParam(request, "byte_offset")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			byteLength, err := OptionalIntParam(request, "byte_length")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			lineRange := startLine != 0 || endLine != 0
+			byteRange := byteOffset != 0 || byteLength != 0
+			switch {
+			case startLine < 0 || endLine < 0 || byteOffset < 0 || byteLength < 0:
+				return mcp.NewToolResultError("start_line, end_line, byte_offset and byte_length must not be negative"), nil
+			case lineRange && byteRange:
+				return mcp.NewToolResultError("a line range cannot be combined with a byte range"), nil
+			case endLine != 0 && endLine < startLine:
+				return mcp.NewToolResultError("end_line must not be before start_line"), nil
+			case (lineRange || byteRange) && (path == "" || strings.HasSuffix(path, "/")):
+				return mcp.NewToolResultError("line and byte ranges can only be used with files"), nil
+			}
+			if startLine == 0 {
+				startLine = 1
+			}
 
 			client, err := getClient(ctx)
 			if err != nil {
@@ -568,7 +810,13 @@ func GetFileContents(getClient GetClientFn, getRawClient raw.GetRawClientFn, t t
 				if err != nil {
 					return mcp.NewToolResultError("failed to get GitHub raw content client"), nil
 				}
-				resp, err := rawClient.GetRawContent(ctx, owner, repo, path, rawOpts)
+				var resp *http.Response
+				if byteRange {
+					// Only fetch the requested bytes if the server honours the range
+					resp, err = rawClient.GetRawContentRange(ctx, owner, repo, path, rawOpts, int64(byteOffset), int64(byteLength))
+				} else {
+					resp, err = rawClient.GetRawContent(ctx, owner, repo, path, rawOpts)
+				}
 				if err != nil {
 					return mcp.NewToolResultError("failed to get raw repository content"), nil
 				}
@@ -576,7 +824,10 @@ func GetFileContents(getClient GetClientFn, getRawClient raw.GetRawClientFn, t t
 					_ = resp.Body.Close()
 				}()
 
-				if resp.StatusCode == http.StatusOK {
+				if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+					return mcp.NewToolResultError(fmt.Sprintf("byte_offset %d is beyond the end of the file (size: %d bytes)", byteOffset, fileContent.GetSize())), nil
+				}
+				if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusPartialContent {
 					// If the raw content is found, return it directly
 					body, err := io.ReadAll(resp.Body)
 					if err != nil {
@@ -584,6 +835,22 @@ func GetFileContents(getClient GetClientFn, getRawClient raw.GetRawClientFn, t t
 					}
 					contentType := resp.Header.Get("Content-Type")
 
+					// Describe the returned part of the file in the result message
+					var part string
+					if byteRange {
+						if resp.StatusCode == http.StatusOK {
+							// The range was ignored, so cut the full content here
+							if byteOffset > 0 && byteOffset >= len(body) {
+								return mcp.NewToolResultError(fmt.Sprintf("byte_offset %d is beyond the end of the file (size: %d bytes)", byteOffset, len(body))), nil
+							}
+							body = body[min(byteOffset, len(body)):]
+							if byteLength > 0 {
+								body = body[:min(byteLength, len(body))]
+							}
+						}
+						part = fmt.Sprintf("bytes %d-%d of ", byteOffset, byteOffset+len(body)-1)
+					}
+
 					var resourceURI string
 					switch {
 					case sha != "":
@@ -604,13 +871,20 @@ func GetFileContents(getClient GetClientFn, getRawClient raw.GetRawClientFn, t t
 					}
 
 					// Determine if content is text or binary
-					isTextContent := strings.HasPrefix(contentType, "text/") ||
-						contentType == "application/json" ||
-						contentType == "application/xml" ||
-						strings.HasSuffix(contentType, "+json") ||
-						strings.HasSuffix(contentType, "+xml")
-
-					if isTextContent {
+					if isTextContentType(contentType) {
+						// Report the total number of lines, which is only known when the whole file was fetched
+						details := fmt.Sprintf("size: %d bytes", fileContent.GetSize())
+						if !byteRange {
+							totalLines := countLines(body)
+							if lineRange {
+								if startLine > totalLines {
+									return mcp.NewToolResultError(fmt.Sprintf("start_line %d is beyond the end of the file (total lines: %d)", startLine, totalLines)), nil
+								}
+								body, endLine = selectLines(body, startLine, endLine)
+								part = fmt.Sprintf("lines %d-%d of ", startLine, endLine)
+							}
+							details = fmt.Sprintf("total lines: %d", totalLines)
+						}
 						result := mcp.TextResourceContents{
 							URI:      resourceURI,
 							Text:     string(body),
@@ -618,9 +892,12 @@ func GetFileContents(getClient GetClientFn, getRawClient raw.GetRawClientFn, t t
 						}
 						// Include SHA in the result metadata
 						if fileSHA != "" {
-							return mcp.NewToolResultResource(fmt.Sprintf("successfully downloaded text file (SHA: %s)", fileSHA), result), nil
+							details = fmt.Sprintf("SHA: %s, %s", fileSHA, details)
 						}
-						return mcp.NewToolResultResource("successfully downloaded text file", result), nil
+						return mcp.NewToolResultResource(fmt.Sprintf("successfully downloaded %stext file (%s)", part, details), result), nil
+					}
+					if lineRange {
+						return mcp.NewToolResultError("start_line and end_line can only be used with text files, use byte_offset and byte_length instead"), nil
 					}
 
 					result := mcp.BlobResourceContents{
@@ -628,11 +905,12 @@ func GetFileContents(getClient GetClientFn, getRawClient raw.GetRawClientFn, t t
 						Blob:     base64.StdEncoding.EncodeToString(body),
 						MIMEType: contentType,
 					}
+					details := fmt.Sprintf("size: %d bytes", fileContent.GetSize())
 					// Include SHA in the result metadata
 					if fileSHA != "" {
-						return mcp.NewToolResultResource(fmt.Sprintf("successfully downloaded binary file (SHA: %s)", fileSHA), result), nil
+						details = fmt.Sprintf("SHA: %s, %s", fileSHA, details)
 					}
-					return mcp.NewToolResultResource("successfully downloaded binary file", result), nil
+					return mcp.NewToolResultResource(fmt.Sprintf("successfully downloaded %sbinary file (%s)", part, details), result), nil
 				}
 				rawAPIResponseCode = resp.StatusCode
 			}
@@ -686,6 +964,294 @@ func GetFileContents(getClient GetClientFn, getRawClient raw.GetRawClientFn, t t
 		}
 }
 
+// countLines returns the number of lines of content. A last line without a trailing newline counts as a line.
+func countLines(content []byte) int {
+	lines := bytes.Count(content, []byte("\n"))
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
+
+// selectLines returns the lines start to end of content, counting from 1 and keeping their newlines. An end of
+// zero or beyond the last line selects up to the last line, which is returned as the new end.
+func selectLines(content []byte, start, end int) ([]byte, int) {
+	line := 1
+	from := 0
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\n' {
+			continue
+		}
+		if line == start-1 {
+			from = i + 1
+		}
+		if line == end {
+			return content[from : i+1], end
+		}
+		line++
+	}
+	return content[from:], countLines(content)
+}
+
+const (
+	// defaultGetFilesBytes is the default total size of the contents returned by get_files.
+	defaultGetFilesBytes = 200000
+	// maxGetFiles is the number of files get_files returns in one call.
+	maxGetFiles = 100
+	// getFilesWorkers is the number of files get_files fetches concurrently.
+	getFilesWorkers = 8
+)
+
+// RetrievedFile is a file returned by get_files.
+type RetrievedFile struct {
+	Path string `json:"path"`
+	SHA  string `json:"sha"`
+	Size int    `json:"size"`
+	// Content is the UTF-8 content of the file, cut at Truncated.
+	Content   string `json:"content,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+	// Binary files are listed without content.
+	Binary bool   `json:"binary,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// FilesResponse is the result of get_files.
+type FilesResponse struct {
+	SHA   string          `json:"sha"`
+	Files []RetrievedFile `json:"files"`
+	// Unmatched are the requested paths and patterns that match no file.
+	Unmatched []string `json:"unmatched,omitempty"`
+	// Omitted is the number of further matching files beyond the file limit.
+	Omitted    int  `json:"omitted,omitempty"`
+	TotalBytes int  `json:"total_bytes"`
+	BudgetHit  bool `json:"budget_exhausted,omitempty"`
+	// TreeTruncated is set when the repository has too many files to list, so patterns may miss some.
+	TreeTruncated bool `json:"tree_truncated,omitempty"`
+}
+
+// textPrefix returns content without an incomplete rune at its end, and whether it is text.
+func textPrefix(content []byte) (string, bool) {
+	for i := 0; i < utf8.UTFMax-1 && len(content) > 0 && !utf8.Valid(content); i++ {
+		content = content[:len(content)-1]
+	}
+	if !utf8.Valid(content) || bytes.IndexByte(content, 0) >= 0 {
+		return "", false
+	}
+	return string(content), true
+}
+
+// GetFiles creates a tool to get the contents of several files of a GitHub repository in one call.
+func GetFiles(getClient GetClientFn, getRawClient raw.GetRawClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("get_files",
+			mcp.WithDescription(t("TOOL_GET_FILES_DESCRIPTION", "Get the contents of several files of a GitHub repository in one call, by path or glob pattern. Contents are cut once a total size budget is spent, and binary files are listed without content")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title:        t("TOOL_GET_FILES_USER_TITLE", "Get files"),
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("owner",
+				mcp.Required(),
+				mcp.Description("Repository owner (username or organization)"),
+			),
+			mcp.WithString("repo",
+				mcp.Required(),
+				mcp.Description("Repository name"),
+			),
+			mcp.WithArray("paths",
+				mcp.Required(),
+				mcp.Description("Paths of files, glob patterns such as 'pkg/*.go', or directories ending with '/' to get every file under them"),
+				mcp.Items(map[string]interface{}{
+					"type": "string",
+				}),
+			),
+			mcp.WithString("ref",
+				mcp.Description("Accepts optional git refs such as `refs/tags/{tag}`, `refs/heads/{branch}` or `refs/pull/{pr_number}/head`"),
+			),
+			mcp.WithString("sha",
+				mcp.Description("Accepts optional commit SHA. If specified, it will be used instead of ref"),
+			),
+			mcp.WithNumber("max_total_bytes",
+				mcp.Description(fmt.Sprintf("Total size of the returned text contents, which binary files do not count towards. Files beyond it are cut or listed without content. Default is %d", defaultGetFilesBytes)),
+				mcp.Min(1),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			owner, err := RequiredParam[string](request, "owner")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			repo, err := RequiredParam[string](request, "repo")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			patterns, err := OptionalStringArrayParam(request, "paths")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if len(patterns) == 0 {
+				return mcp.NewToolResultError("paths must list at least one path or pattern"), nil
+			}
+			ref, err := OptionalParam[string](request, "ref")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			sha, err := OptionalParam[string](request, "sha")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			budget, err := OptionalIntParamWithDefault(request, "max_total_bytes", defaultGetFilesBytes)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+			}
+			rawOpts, err := resolveGitReference(ctx, client, owner, repo, ref, sha)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to resolve git reference: %s", err)), nil
+			}
+
+			tree, resp, err := client.Git.GetTree(ctx, owner, repo, rawOpts.SHA, true)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					"failed to get git tree",
+					resp,
+					err,
+				), nil
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			// Match the patterns against the files of the tree, in tree order and without duplicates
+			response := FilesResponse{SHA: rawOpts.SHA, Files: []RetrievedFile{}, TreeTruncated: tree.GetTruncated()}
+			matchedPatterns := make(map[string]bool, len(patterns))
+			for _, entry := range tree.Entries {
+				if entry.GetType() != "blob" {
+					continue
+				}
+				matched := false
+				for _, pattern := range patterns {
+					if entry.GetPath() == pattern || matchPathFilter(pattern, entry.GetPath()) {
+						matchedPatterns[pattern] = true
+						matched = true
+					}
+				}
+				if !matched {
+					continue
+				}
+				if len(response.Files) == maxGetFiles {
+					response.Omitted++
+					continue
+				}
+				response.Files = append(response.Files, RetrievedFile{Path: entry.GetPath(), SHA: entry.GetSHA(), Size: entry.GetSize()})
+			}
+			for _, pattern := range patterns {
+				if !matchedPatterns[pattern] {
+					response.Unmatched = append(response.Unmatched, pattern)
+				}
+			}
+
+			rawClient, err := getRawClient(ctx)
+			if err != nil {
+				return mcp.NewToolResultError("failed to get GitHub raw content client"), nil
+			}
+			// Reserve the budget in file order before fetching, so that the fetches together read at most the
+			// budget and the result does not depend on which fetch finishes first
+			opts := &raw.ContentOpts{SHA: rawOpts.SHA}
+			limits := reserveFilesBudget(response.Files, budget)
+			all := make([]int, len(response.Files))
+			for i := range all {
+				all[i] = i
+			}
+			fetchFiles(ctx, rawClient, owner, repo, opts, response.Files, limits, all)
+
+			// Binary files return no text, so their share goes to the text files after them. Those are fetched
+			// again with their larger share, which keeps the bytes read within twice the budget
+			var refetch []int
+			for i, limit := range reserveFilesBudget(response.Files, budget) {
+				if file := &response.Files[i]; file.Truncated && limit > limits[i] {
+					*file = RetrievedFile{Path: file.Path, SHA: file.SHA, Size: file.Size}
+					limits[i] = limit
+					refetch = append(refetch, i)
+				}
+			}
+			fetchFiles(ctx, rawClient, owner, repo, opts, response.Files, limits, refetch)
+
+			for _, file := range response.Files {
+				if file.Truncated {
+					response.BudgetHit = true
+				}
+				response.TotalBytes += len(file.Content)
+			}
+			return MarshalledTextResult(response), nil
+		}
+}
+
+// reserveFilesBudget shares budget among the files in order, leaving out those known to be binary.
+func reserveFilesBudget(files []RetrievedFile, budget int) []int {
+	limits := make([]int, len(files))
+	for i, file := range files {
+		if file.Binary {
+			continue
+		}
+		limits[i] = min(file.Size, budget)
+		budget -= limits[i]
+	}
+	return limits
+}
+
+// fetchFiles fetches the files at indices concurrently, reading at most the limit of each.
+func fetchFiles(ctx context.Context, rawClient *raw.Client, owner, repo string, opts *raw.ContentOpts, files []RetrievedFile, limits []int, indices []int) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(getFilesWorkers, len(indices)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fetchFile(ctx, rawClient, owner, repo, opts, &files[i], limits[i])
+			}
+		}()
+	}
+	for _, i := range indices {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// fetchFile sets the content of file, reading at most limit bytes of it.
+func fetchFile(ctx context.Context, rawClient *raw.Client, owner, repo string, opts *raw.ContentOpts, file *RetrievedFile, limit int) {
+	if file.Size > limit {
+		file.Truncated = true
+	}
+	if limit == 0 && file.Size > 0 {
+		return
+	}
+	resp, err := rawClient.GetRawContent(ctx, owner, repo, file.Path, opts)
+	if err != nil {
+		file.Error = fmt.Sprintf("failed to get raw content: %v", err)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		file.Error = fmt.Sprintf("failed to get raw content: %s", resp.Status)
+		return
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, int64(limit)))
+	if err != nil {
+		file.Error = fmt.Sprintf("failed to read raw content: %v", err)
+		return
+	}
+	text, ok := textPrefix(content)
+	if !ok {
+		file.Binary = true
+		file.Truncated = false
+		return
+	}
+	file.Content = text
+}
+
 // ForkRepository creates a tool to fork a repository.
 func ForkRepository(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("fork_repository",
@@ -969,6 +1535,9 @@ func CreateBranch(getClient GetClientFn, t translations.TranslationHelperFunc) (
 			mcp.WithString("from_branch",
 				mcp.Description("Source branch (defaults to repo default)"),
 			),
+			mcp.WithString("expected_head_sha",
+				mcp.Description("SHA the source branch is expected to point to. If it has moved, no branch is created and a conflict is returned"),
+			),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -987,6 +1556,10 @@ func CreateBranch(getClient GetClientFn, t translations.TranslationHelperFunc) (
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
+			expectedHeadSHA, err := OptionalParam[string](request, "expected_head_sha")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
 			client, err := getClient(ctx)
 			if err != nil {
@@ -1022,6 +1595,15 @@ func CreateBranch(getClient GetClientFn, t translations.TranslationHelperFunc) (
 			}
 			defer func() { _ = resp.Body.Close() }()
 
+			if expectedHeadSHA != "" && ref.GetObject().GetSHA() != expectedHeadSHA {
+				return refConflictResult(RefConflict{
+					Ref:             ref.GetRef(),
+					ExpectedHeadSHA: expectedHeadSHA,
+					HeadSHA:         ref.GetObject().GetSHA(),
+					Message:         fmt.Sprintf("No branch was created: %s has moved since %s. Check the commits up to head_sha, and create the branch again with expected_head_sha set to head_sha.", fromBranch, expectedHeadSHA),
+				}), nil
+			}
+
 			// Create new branch
 			newRef := github.CreateRef{
 				Ref: "refs/heads/" + branch,
@@ -1029,6 +1611,17 @@ func CreateBranch(getClient GetClientFn, t translations.TranslationHelperFunc) (
 			}
 
 			createdRef, resp, err := client.Git.CreateRef(ctx, owner, repo, newRef)
+			if err != nil && resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
+				// Refs are never overwritten, so report a branch that already exists as a conflict
+				if existing, existingResp, existingErr := client.Git.GetRef(ctx, owner, repo, newRef.Ref); existingErr == nil {
+					_ = existingResp.Body.Close()
+					return refConflictResult(RefConflict{
+						Ref:     existing.GetRef(),
+						HeadSHA: existing.GetObject().GetSHA(),
+						Message: fmt.Sprintf("No branch was created: %s already exists. Use it, or create a branch with another name.", branch),
+					}), nil
+				}
+			}
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					"failed to create branch",
@@ -1050,7 +1643,7 @@ func CreateBranch(getClient GetClientFn, t translations.TranslationHelperFunc) (
 // PushFiles creates a tool to push multiple files in a single commit to a GitHub repository.
 func PushFiles(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("push_files",
-			mcp.WithDescription(t("TOOL_PUSH_FILES_DESCRIPTION", "Push multiple files to a GitHub repository in a single commit")),
+			mcp.WithDescription(t("TOOL_PUSH_FILES_DESCRIPTION", "Push multiple file changes to a GitHub repository in a single commit. Files can be created, updated, deleted or renamed, and may be binary.")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
 				Title:        t("TOOL_PUSH_FILES_USER_TITLE", "Push files to repository"),
 				ReadOnlyHint: ToBoolPtr(false),
@@ -1073,7 +1666,7 @@ func PushFiles(getClient GetClientFn, t translations.TranslationHelperFunc) (too
 					map[string]interface{}{
 						"type":                 "object",
 						"additionalProperties": false,
-						"required":             []string{"path", "content"},
+						"required":             []string{"path"},
 						"properties": map[string]interface{}{
 							"path": map[string]interface{}{
 								"type":        "string",
@@ -1081,16 +1674,38 @@ func PushFiles(getClient GetClientFn, t translations.TranslationHelperFunc) (too
 							},
 							"content": map[string]interface{}{
 								"type":        "string",
-								"description": "file content",
+								"description": "file content. Required for upsert, optional for rename to also change the content",
+							},
+							"operation": map[string]interface{}{
+								"type":        "string",
+								"description": "upsert (default) creates or updates the file, delete removes it, rename moves it from previous_path",
+								"enum":        []string{pushOperationUpsert, pushOperationDelete, pushOperationRename},
+							},
+							"previous_path": map[string]interface{}{
+								"type":        "string",
+								"description": "path the file is renamed from, for the rename operation",
+							},
+							"mode": map[string]interface{}{
+								"type":        "string",
+								"description": "file mode: 100644 for a regular file (default), 100755 for an executable, or 120000 for a symlink whose content is its target. Renamed files keep their mode by default",
+								"enum":        []string{"100644", "100755", "120000"},
+							},
+							"encoding": map[string]interface{}{
+								"type":        "string",
+								"description": "encoding of content: utf-8 (default) or base64 for binary files",
+								"enum":        []string{"utf-8", "base64"},
 							},
 						},
 					}),
-				mcp.Description("Array of file objects to push, each object with path (string) and content (string)"),
+				mcp.Description("Array of file changes to push, each object with a path and, depending on the operation, content, previous_path, mode and encoding"),
 			),
 			mcp.WithString("message",
 				mcp.Required(),
 				mcp.Description("Commit message"),
 			),
+			mcp.WithString("expected_head_sha",
+				mcp.Description("SHA of the commit the changes are based on. If the branch has moved since, the changes are rebased onto its head when no other commit changed the same files, and a conflict is returned otherwise"),
+			),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -1109,12 +1724,20 @@ func PushFiles(getClient GetClientFn, t translations.TranslationHelperFunc) (too
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
+			expectedHeadSHA, err := OptionalParam[string](request, "expected_head_sha")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
 			// Parse files parameter - this should be an array of objects with path and content
 			filesObj, ok := request.GetArguments()["files"].([]interface{})
 			if !ok {
 				return mcp.NewToolResultError("files parameter must be an array of objects with path and content"), nil
 			}
+			files, err := parsePushFiles(filesObj)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
 			client, err := getClient(ctx)
 			if err != nil {
@@ -1132,94 +1755,381 @@ func PushFiles(getClient GetClientFn, t translations.TranslationHelperFunc) (too
 			}
 			defer func() { _ = resp.Body.Close() }()
 
-			// Get the commit object that the branch points to
-			baseCommit, resp, err := client.Git.GetCommit(ctx, owner, repo, *ref.Object.SHA)
+			// Changes made to the branch since the expected head are kept if they touch other files
+			headSHA := ref.GetObject().GetSHA()
+			touched := pushTouchedPaths(files)
+			if expectedHeadSHA != "" && expectedHeadSHA != headSHA {
+				if result := rebaseConflict(ctx, client, owner, repo, branch, expectedHeadSHA, headSHA, touched); result != nil {
+					return result, nil
+				}
+			}
+
+			updatedRef, errResult := pushCommit(ctx, client, owner, repo, branch, headSHA, message, touched, func(baseTreeSHA string) ([]*github.TreeEntry, *mcp.CallToolResult) {
+				return pushTreeEntries(ctx, client, owner, repo, baseTreeSHA, files)
+			})
+			if errResult != nil {
+				return errResult, nil
+			}
+
+			r, err := json.Marshal(updatedRef)
 			if err != nil {
-				return ghErrors.NewGitHubAPIErrorResponse(ctx,
-					"failed to get base commit",
-					resp,
-					err,
-				), nil
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
-			defer func() { _ = resp.Body.Close() }()
 
-			// Create tree entries for all files
-			var entries []*github.TreeEntry
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
 
-			for _, file := range filesObj {
-				fileMap, ok := file.(map[string]interface{})
-				if !ok {
-					return mcp.NewToolResultError("each file must be an object with path and content"), nil
-				}
+const (
+	pushOperationUpsert = "upsert"
+	pushOperationDelete = "delete"
+	pushOperationRename = "rename"
 
-				path, ok := fileMap["path"].(string)
-				if !ok || path == "" {
-					return mcp.NewToolResultError("each file must have a path"), nil
-				}
+	defaultFileMode = "100644"
+
+	// maxPushAttempts limits how often push_files rebases onto a branch that keeps moving.
+	maxPushAttempts = 3
+	// maxComparedFiles is the number of changed files above which the compare API truncates its file list.
+	maxComparedFiles = 300
+)
+
+// RefConflict is the result of a write to a branch that moved since the caller read it, in a way that
+// conflicts with the write. Nothing has been written.
+type RefConflict struct {
+	Status          string `json:"status"`
+	Ref             string `json:"ref"`
+	ExpectedHeadSHA string `json:"expected_head_sha,omitempty"`
+	HeadSHA         string `json:"head_sha"`
+	// ConflictingPaths are the paths changed both by the write and on the branch.
+	ConflictingPaths []string `json:"conflicting_paths,omitempty"`
+	Message          string   `json:"message"`
+}
+
+func refConflictResult(conflict RefConflict) *mcp.CallToolResult {
+	conflict.Status = "conflict"
+	result := MarshalledTextResult(conflict)
+	result.IsError = true
+	return result
+}
 
-				content, ok := fileMap["content"].(string)
-				if !ok {
-					return mcp.NewToolResultError("each file must have content"), nil
+// pushTouchedPaths returns the paths that files change.
+func pushTouchedPaths(files []pushFile) []string {
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+		if file.PreviousPath != "" {
+			paths = append(paths, file.PreviousPath)
+		}
+	}
+	return paths
+}
+
+// rebaseConflict checks that changes to paths made on top of base can be moved onto head, the current head of
+// branch, because the commits from base to head change none of them. It returns nil if so, and the result to
+// return otherwise.
+func rebaseConflict(ctx context.Context, client *github.Client, owner, repo, branch, base, head string, paths []string) *mcp.CallToolResult {
+	comparison, resp, err := client.Repositories.CompareCommits(ctx, owner, repo, base, head, nil)
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(ctx,
+			fmt.Sprintf("branch %s has moved from %s to %s, and failed to compare them", branch, base, head),
+			resp,
+			err,
+		)
+	}
+	_ = resp.Body.Close()
+
+	conflict := RefConflict{
+		Ref:             "refs/heads/" + branch,
+		ExpectedHeadSHA: base,
+		HeadSHA:         head,
+	}
+	switch comparison.GetStatus() {
+	case "ahead", "identical":
+	default:
+		conflict.Message = fmt.Sprintf("Nothing was pushed: branch %s was rewritten, so %s is no longer in its history. Read the files at head_sha, reapply the changes, and push again with expected_head_sha set to head_sha.", branch, base)
+		return refConflictResult(conflict)
+	}
+	if len(comparison.Files) >= maxComparedFiles {
+		conflict.Message = fmt.Sprintf("Nothing was pushed: branch %s has too many changes since %s to check them for conflicts. Read the files at head_sha, reapply the changes, and push again with expected_head_sha set to head_sha.", branch, base)
+		return refConflictResult(conflict)
+	}
+
+	for _, file := range comparison.Files {
+		for _, changed := range []string{file.GetFilename(), file.GetPreviousFilename()} {
+			if changed == "" {
+				continue
+			}
+			for _, path := range paths {
+				if pathsOverlap(path, changed) && !slices.Contains(conflict.ConflictingPaths, path) {
+					conflict.ConflictingPaths = append(conflict.ConflictingPaths, path)
 				}
+			}
+		}
+	}
+	if len(conflict.ConflictingPaths) == 0 {
+		return nil
+	}
+	sort.Strings(conflict.ConflictingPaths)
+	conflict.Message = fmt.Sprintf("Nothing was pushed: branch %s has changes to the same files since %s. Read the conflicting files at head_sha, reapply the changes, and push again with expected_head_sha set to head_sha.", branch, base)
+	return refConflictResult(conflict)
+}
 
-				// Create a tree entry for the file
-				entries = append(entries, &github.TreeEntry{
-					Path:    github.Ptr(path),
-					Mode:    github.Ptr("100644"), // Regular file mode
-					Type:    github.Ptr("blob"),
-					Content: github.Ptr(content),
-				})
+// pushCommit commits the tree entries returned by entries on top of headSHA, the head of branch, and moves the
+// branch to the commit. If another push moves the branch meanwhile, the commit is rebased onto the new head as
+// long as the new commits change none of the touched paths. entries is called once, with the tree of headSHA. It
+// returns the updated reference, or the result to report on failure.
+func pushCommit(ctx context.Context, client *github.Client, owner, repo, branch, headSHA, message string, touched []string, entries func(baseTreeSHA string) ([]*github.TreeEntry, *mcp.CallToolResult)) (*github.Reference, *mcp.CallToolResult) {
+	var treeEntries []*github.TreeEntry
+	for attempt := 1; ; attempt++ {
+		// Get the commit object that the branch points to
+		baseCommit, resp, err := client.Git.GetCommit(ctx, owner, repo, headSHA)
+		if err != nil {
+			return nil, ghErrors.NewGitHubAPIErrorResponse(ctx,
+				"failed to get base commit",
+				resp,
+				err,
+			)
+		}
+		_ = resp.Body.Close()
+
+		// Create tree entries for all files. A rebase only changes the base tree, and none of the files, so the
+		// entries are reused.
+		if attempt == 1 {
+			var errResult *mcp.CallToolResult
+			treeEntries, errResult = entries(*baseCommit.Tree.SHA)
+			if errResult != nil {
+				return nil, errResult
 			}
+		}
 
-			// Create a new tree with the file entries
-			newTree, resp, err := client.Git.CreateTree(ctx, owner, repo, *baseCommit.Tree.SHA, entries)
-			if err != nil {
-				return ghErrors.NewGitHubAPIErrorResponse(ctx,
-					"failed to create tree",
-					resp,
-					err,
-				), nil
+		// Create a new tree with the file entries
+		newTree, resp, err := client.Git.CreateTree(ctx, owner, repo, *baseCommit.Tree.SHA, treeEntries)
+		if err != nil {
+			return nil, ghErrors.NewGitHubAPIErrorResponse(ctx,
+				"failed to create tree",
+				resp,
+				err,
+			)
+		}
+		_ = resp.Body.Close()
+
+		// Create a new commit
+		commit := github.Commit{
+			Message: github.Ptr(message),
+			Tree:    newTree,
+			Parents: []*github.Commit{{SHA: baseCommit.SHA}},
+		}
+		newCommit, resp, err := client.Git.CreateCommit(ctx, owner, repo, commit, nil)
+		if err != nil {
+			return nil, ghErrors.NewGitHubAPIErrorResponse(ctx,
+				"failed to create commit",
+				resp,
+				err,
+			)
+		}
+		_ = resp.Body.Close()
+
+		// Update the reference to point to the new commit, unless the branch has moved since it was read
+		updatedRef, resp, err := client.Git.UpdateRef(ctx, owner, repo, "refs/heads/"+branch, github.UpdateRef{
+			SHA:   *newCommit.SHA,
+			Force: github.Ptr(false),
+		})
+		if err == nil {
+			_ = resp.Body.Close()
+			return updatedRef, nil
+		}
+		if resp == nil || resp.StatusCode != http.StatusUnprocessableEntity || attempt == maxPushAttempts {
+			return nil, ghErrors.NewGitHubAPIErrorResponse(ctx,
+				"failed to update reference",
+				resp,
+				err,
+			)
+		}
+		_ = resp.Body.Close()
+
+		// The update is not a fast forward, as another push moved the branch. Rebase onto its new head.
+		ref, resp, err := client.Git.GetRef(ctx, owner, repo, "refs/heads/"+branch)
+		if err != nil {
+			return nil, ghErrors.NewGitHubAPIErrorResponse(ctx,
+				"failed to get branch reference",
+				resp,
+				err,
+			)
+		}
+		_ = resp.Body.Close()
+		newHeadSHA := ref.GetObject().GetSHA()
+		if result := rebaseConflict(ctx, client, owner, repo, branch, headSHA, newHeadSHA, touched); result != nil {
+			return nil, result
+		}
+		headSHA = newHeadSHA
+	}
+}
+
+// pathsOverlap reports whether a and b are the same path, or one is a directory containing the other.
+func pathsOverlap(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+// pushFile is a change to a file pushed by push_files.
+type pushFile struct {
+	Operation    string
+	Path         string
+	PreviousPath string
+	// Content is nil for deletions, and for renames that keep the content.
+	Content *string
+	// Mode is empty when not given, to default to the mode of a renamed file or to a regular file.
+	Mode   string
+	Base64 bool
+}
+
+// parsePushFiles validates the files parameter of push_files.
+func parsePushFiles(filesObj []interface{}) ([]pushFile, error) {
+	files := make([]pushFile, 0, len(filesObj))
+	touched := make(map[string]bool)
+	for _, file := range filesObj {
+		fileMap, ok := file.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("each file must be an object with path and content")
+		}
+
+		f := pushFile{Operation: pushOperationUpsert}
+		if operation, ok := fileMap["operation"].(string); ok && operation != "" {
+			f.Operation = operation
+		}
+		path, ok := fileMap["path"].(string)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("each file must have a path")
+		}
+		f.Path = path
+		if content, ok := fileMap["content"].(string); ok {
+			f.Content = &content
+		}
+		f.Mode, _ = fileMap["mode"].(string)
+		switch f.Mode {
+		case "", "100644", "100755", "120000":
+		default:
+			return nil, fmt.Errorf("file %s has invalid mode %q: must be 100644, 100755 or 120000", path, f.Mode)
+		}
+		switch encoding, _ := fileMap["encoding"].(string); encoding {
+		case "", "utf-8":
+		case "base64":
+			f.Base64 = true
+		default:
+			return nil, fmt.Errorf("file %s has invalid encoding %q: must be utf-8 or base64", path, encoding)
+		}
+
+		switch f.Operation {
+		case pushOperationUpsert:
+			if f.Content == nil {
+				return nil, fmt.Errorf("each file must have content")
 			}
-			defer func() { _ = resp.Body.Close() }()
+		case pushOperationDelete:
+			if f.Content != nil || f.Mode != "" || f.Base64 {
+				return nil, fmt.Errorf("file %s is deleted, so it takes no content, mode or encoding", path)
+			}
+		case pushOperationRename:
+			f.PreviousPath, _ = fileMap["previous_path"].(string)
+			if f.PreviousPath == "" || f.PreviousPath == path {
+				return nil, fmt.Errorf("file %s is renamed, so it needs a previous_path other than its path", path)
+			}
+		default:
+			return nil, fmt.Errorf("file %s has unknown operation %q: must be upsert, delete or rename", path, f.Operation)
+		}
+		if f.Base64 && f.Content != nil {
+			if _, err := base64.StdEncoding.DecodeString(*f.Content); err != nil {
+				return nil, fmt.Errorf("content of file %s is not valid base64: %w", path, err)
+			}
+		}
 
-			// Create a new commit
-			commit := github.Commit{
-				Message: github.Ptr(message),
-				Tree:    newTree,
-				Parents: []*github.Commit{{SHA: baseCommit.SHA}},
+		for _, p := range []string{f.Path, f.PreviousPath} {
+			if p == "" {
+				continue
 			}
-			newCommit, resp, err := client.Git.CreateCommit(ctx, owner, repo, commit, nil)
+			if touched[p] {
+				return nil, fmt.Errorf("file %s is changed more than once", p)
+			}
+			touched[p] = true
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// pushTreeEntries creates the tree entries for files on top of the base tree. Binary content is uploaded as
+// blobs first, and renamed files are looked up in the base tree to keep their content and mode. On failure, it
+// returns the result to report.
+func pushTreeEntries(ctx context.Context, client *github.Client, owner, repo, baseTreeSHA string, files []pushFile) ([]*github.TreeEntry, *mcp.CallToolResult) {
+	var baseEntries map[string]*github.TreeEntry
+	previousEntry := func(path string) (*github.TreeEntry, *mcp.CallToolResult) {
+		if baseEntries == nil {
+			tree, resp, err := client.Git.GetTree(ctx, owner, repo, baseTreeSHA, true)
 			if err != nil {
-				return ghErrors.NewGitHubAPIErrorResponse(ctx,
-					"failed to create commit",
-					resp,
-					err,
-				), nil
+				return nil, ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to get base tree", resp, err)
 			}
-			defer func() { _ = resp.Body.Close() }()
+			_ = resp.Body.Close()
+			baseEntries = make(map[string]*github.TreeEntry, len(tree.Entries))
+			for _, entry := range tree.Entries {
+				baseEntries[entry.GetPath()] = entry
+			}
+		}
+		entry, ok := baseEntries[path]
+		if !ok || entry.GetType() != "blob" {
+			return nil, mcp.NewToolResultError(fmt.Sprintf("cannot rename %s: no such file on the branch", path))
+		}
+		return entry, nil
+	}
 
-			// Update the reference to point to the new commit
-			ref.Object.SHA = newCommit.SHA
-			updatedRef, resp, err := client.Git.UpdateRef(ctx, owner, repo, *ref.Ref, github.UpdateRef{
-				SHA:   *newCommit.SHA,
-				Force: github.Ptr(false),
+	var entries []*github.TreeEntry
+	for _, file := range files {
+		entry := &github.TreeEntry{
+			Path: github.Ptr(file.Path),
+			Mode: github.Ptr(defaultFileMode),
+			Type: github.Ptr("blob"),
+		}
+
+		switch file.Operation {
+		case pushOperationDelete:
+			// An entry with neither content nor SHA deletes the file
+			entries = append(entries, entry)
+			continue
+		case pushOperationRename:
+			previous, errResult := previousEntry(file.PreviousPath)
+			if errResult != nil {
+				return nil, errResult
+			}
+			entries = append(entries, &github.TreeEntry{
+				Path: github.Ptr(file.PreviousPath),
+				Mode: previous.Mode,
+				Type: github.Ptr("blob"),
 			})
-			if err != nil {
-				return ghErrors.NewGitHubAPIErrorResponse(ctx,
-					"failed to update reference",
-					resp,
-					err,
-				), nil
+			entry.Mode = previous.Mode
+			if file.Content == nil {
+				entry.SHA = previous.SHA
 			}
-			defer func() { _ = resp.Body.Close() }()
+		}
+		if file.Mode != "" {
+			entry.Mode = github.Ptr(file.Mode)
+		}
 
-			r, err := json.Marshal(updatedRef)
+		switch {
+		case file.Content == nil:
+		case file.Base64:
+			// Tree entries only take text content, so binary content is uploaded as a blob
+			blob, resp, err := client.Git.CreateBlob(ctx, owner, repo, github.Blob{
+				Content:  file.Content,
+				Encoding: github.Ptr("base64"),
+			})
 			if err != nil {
-				return nil, fmt.Errorf("failed to marshal response: %w", err)
+				return nil, ghErrors.NewGitHubAPIErrorResponse(ctx, fmt.Sprintf("failed to create blob for %s", file.Path), resp, err)
 			}
-
-			return mcp.NewToolResultText(string(r)), nil
+			_ = resp.Body.Close()
+			entry.SHA = blob.SHA
+		default:
+			entry.Content = file.Content
 		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
 }
 
 // ListTags creates a tool to list tags in a GitHub repository.
@@ -1379,7 +2289,7 @@ func GetTag(getClient GetClientFn, t translations.TranslationHelperFunc) (tool m
 }
 
 // ListReleases creates a tool to list releases in a GitHub repository.
-func ListReleases(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func ListReleases(getClient GetClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("list_releases",
 			mcp.WithDescription(t("TOOL_LIST_RELEASES_DESCRIPTION", "List releases in a GitHub repository")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -1434,7 +2344,14 @@ func ListReleases(getClient GetClientFn, t translations.TranslationHelperFunc) (
 				return mcp.NewToolResultError(fmt.Sprintf("failed to list releases: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(releases)
+			output, err := applyLockdown(ctx, cache, flags, releases, func(release *github.RepositoryRelease) contentOrigin {
+				return contentOrigin{Login: release.GetAuthor().GetLogin(), Owner: owner, Repo: repo}
+			}, redactRelease)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+			}
+
+			r, err := json.Marshal(output)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
@@ -1444,7 +2361,7 @@ func ListReleases(getClient GetClientFn, t translations.TranslationHelperFunc) (
 }
 
 // GetLatestRelease creates a tool to get the latest release in a GitHub repository.
-func GetLatestRelease(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func GetLatestRelease(getClient GetClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_latest_release",
 			mcp.WithDescription(t("TOOL_GET_LATEST_RELEASE_DESCRIPTION", "Get the latest release in a GitHub repository")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -1489,7 +2406,15 @@ func GetLatestRelease(getClient GetClientFn, t translations.TranslationHelperFun
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get latest release: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(release)
+			output, err := applyLockdownItem(ctx, cache, flags, release, contentOrigin{Login: release.GetAuthor().GetLogin(), Owner: owner, Repo: repo}, redactRelease)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+			}
+			if output == nil {
+				return mcp.NewToolResultError("access to release is restricted by lockdown mode"), nil
+			}
+
+			r, err := json.Marshal(output)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
@@ -1498,7 +2423,7 @@ func GetLatestRelease(getClient GetClientFn, t translations.TranslationHelperFun
 		}
 }
 
-func GetReleaseByTag(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func GetReleaseByTag(getClient GetClientFn, cache *lockdown.RepoAccessCache, t translations.TranslationHelperFunc, flags FeatureFlags) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_release_by_tag",
 			mcp.WithDescription(t("TOOL_GET_RELEASE_BY_TAG_DESCRIPTION", "Get a specific release by its tag name in a GitHub repository")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -1555,7 +2480,15 @@ func GetReleaseByTag(getClient GetClientFn, t translations.TranslationHelperFunc
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get release by tag: %s", string(body))), nil
 			}
 
-			r, err := json.Marshal(release)
+			output, err := applyLockdownItem(ctx, cache, flags, release, contentOrigin{Login: release.GetAuthor().GetLogin(), Owner: owner, Repo: repo}, redactRelease)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
+			}
+			if output == nil {
+				return mcp.NewToolResultError("access to release is restricted by lockdown mode"), nil
+			}
+
+			r, err := json.Marshal(output)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
@@ -1564,6 +2497,15 @@ func GetReleaseByTag(getClient GetClientFn, t translations.TranslationHelperFunc
 		}
 }
 
+// isTextContentType reports whether content of the media type contentType is text.
+func isTextContentType(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/") ||
+		contentType == "application/json" ||
+		contentType == "application/xml" ||
+		strings.HasSuffix(contentType, "+json") ||
+		strings.HasSuffix(contentType, "+xml")
+}
+
 // filterPaths filters the entries in a GitHub tree to find paths that
 // match the given suffix.
 // maxResults limits the number of results returned to first maxResults entries,
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -327,6 +329,563 @@ func Test_GetFileContents(t *testing.T) {
 	}
 }
 
+func Test_GetFileContentsRanges(t *testing.T) {
+	content := "one\ntwo\nthree\nfour"
+
+	// rawHandler serves content, honouring byte ranges if asked to
+	rawHandler := func(contentType string, honourRange bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			rangeHeader := r.Header.Get("Range")
+			if !honourRange || rangeHeader == "" {
+				_, _ = w.Write([]byte(content))
+				return
+			}
+			var from, to int
+			if _, err := fmt.Sscanf(rangeHeader, "bytes=%d-%d", &from, &to); err != nil {
+				to = len(content) - 1
+			}
+			if from >= len(content) {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte(content[from:min(to+1, len(content))]))
+		}
+	}
+	mockedClient := func(contentType string, honourRange bool) *http.Client {
+		return mock.NewMockedHTTPClient(
+			mock.WithRequestMatchHandler(
+				mock.GetReposContentsByOwnerByRepoByPath,
+				mockResponse(t, http.StatusOK, &github.RepositoryContent{
+					Name: github.Ptr("file.txt"),
+					Path: github.Ptr("file.txt"),
+					SHA:  github.Ptr("blobsha"),
+					Size: github.Ptr(len(content)),
+					Type: github.Ptr("file"),
+				}),
+			),
+			mock.WithRequestMatchHandler(
+				raw.GetRawReposContentsByOwnerByRepoBySHAByPath,
+				rawHandler(contentType, honourRange),
+			),
+		)
+	}
+
+	tests := []struct {
+		name            string
+		mockedClient    *http.Client
+		requestArgs     map[string]interface{}
+		expectError     bool
+		expectedText    string
+		expectedBlob    string
+		expectedMessage string
+		expectedErrMsg  string
+	}{
+		{
+			name:            "whole file reports total lines",
+			mockedClient:    mockedClient("text/plain", true),
+			requestArgs:     map[string]interface{}{},
+			expectedText:    content,
+			expectedMessage: "successfully downloaded text file (SHA: blobsha, total lines: 4)",
+		},
+		{
+			name: "file without a SHA",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposContentsByOwnerByRepoByPath,
+					mockResponse(t, http.StatusOK, &github.RepositoryContent{
+						Name: github.Ptr("file.txt"),
+						Path: github.Ptr("file.txt"),
+						SHA:  github.Ptr(""),
+						Size: github.Ptr(len(content)),
+						Type: github.Ptr("file"),
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					raw.GetRawReposContentsByOwnerByRepoBySHAByPath,
+					rawHandler("text/plain", true),
+				),
+			),
+			requestArgs:     map[string]interface{}{},
+			expectedText:    content,
+			expectedMessage: "successfully downloaded text file (total lines: 4)",
+		},
+		{
+			name:         "line range",
+			mockedClient: mockedClient("text/plain", true),
+			requestArgs: map[string]interface{}{
+				"start_line": float64(2),
+				"end_line":   float64(3),
+			},
+			expectedText:    "two\nthree\n",
+			expectedMessage: "successfully downloaded lines 2-3 of text file (SHA: blobsha, total lines: 4)",
+		},
+		{
+			name:         "start line to end of file",
+			mockedClient: mockedClient("text/plain", true),
+			requestArgs: map[string]interface{}{
+				"start_line": float64(3),
+			},
+			expectedText:    "three\nfour",
+			expectedMessage: "successfully downloaded lines 3-4 of text file (SHA: blobsha, total lines: 4)",
+		},
+		{
+			name:         "end line beyond end of file",
+			mockedClient: mockedClient("text/plain", true),
+			requestArgs: map[string]interface{}{
+				"end_line": float64(10),
+			},
+			expectedText:    content,
+			expectedMessage: "successfully downloaded lines 1-4 of text file (SHA: blobsha, total lines: 4)",
+		},
+		{
+			name:         "start line beyond end of file",
+			mockedClient: mockedClient("text/plain", true),
+			requestArgs: map[string]interface{}{
+				"start_line": float64(5),
+			},
+			expectError:    true,
+			expectedErrMsg: "start_line 5 is beyond the end of the file (total lines: 4)",
+		},
+		{
+			name:         "byte range served by the raw endpoint",
+			mockedClient: mockedClient("text/plain", true),
+			requestArgs: map[string]interface{}{
+				"byte_offset": float64(4),
+				"byte_length": float64(3),
+			},
+			expectedText:    "two",
+			expectedMessage: "successfully downloaded bytes 4-6 of text file (SHA: blobsha, size: 18 bytes)",
+		},
+		{
+			name:         "byte range ignored by the raw endpoint",
+			mockedClient: mockedClient("text/plain", false),
+			requestArgs: map[string]interface{}{
+				"byte_offset": float64(14),
+			},
+			expectedText:    "four",
+			expectedMessage: "successfully downloaded bytes 14-17 of text file (SHA: blobsha, size: 18 bytes)",
+		},
+		{
+			name:         "byte range of binary file",
+			mockedClient: mockedClient("application/octet-stream", true),
+			requestArgs: map[string]interface{}{
+				"byte_offset": float64(0),
+				"byte_length": float64(3),
+			},
+			expectedBlob:    base64.StdEncoding.EncodeToString([]byte("one")),
+			expectedMessage: "successfully downloaded bytes 0-2 of binary file (SHA: blobsha, size: 18 bytes)",
+		},
+		{
+			name:         "byte offset beyond end of file",
+			mockedClient: mockedClient("text/plain", true),
+			requestArgs: map[string]interface{}{
+				"byte_offset": float64(100),
+			},
+			expectError:    true,
+			expectedErrMsg: "byte_offset 100 is beyond the end of the file (size: 18 bytes)",
+		},
+		{
+			name:         "line range of binary file",
+			mockedClient: mockedClient("application/octet-stream", true),
+			requestArgs: map[string]interface{}{
+				"start_line": float64(1),
+			},
+			expectError:    true,
+			expectedErrMsg: "start_line and end_line can only be used with text files",
+		},
+		{
+			name:         "line range with byte range",
+			mockedClient: mock.NewMockedHTTPClient(),
+			requestArgs: map[string]interface{}{
+				"start_line":  float64(1),
+				"byte_length": float64(3),
+			},
+			expectError:    true,
+			expectedErrMsg: "a line range cannot be combined with a byte range",
+		},
+		{
+			name:         "end line before start line",
+			mockedClient: mock.NewMockedHTTPClient(),
+			requestArgs: map[string]interface{}{
+				"start_line": float64(3),
+				"end_line":   float64(2),
+			},
+			expectError:    true,
+			expectedErrMsg: "end_line must not be before start_line",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup client with mock
+			client := github.NewClient(tc.mockedClient)
+			mockRawClient := raw.NewClient(client, &url.URL{Scheme: "https", Host: "raw.example.com", Path: "/"})
+			_, handler := GetFileContents(stubGetClientFn(client), stubGetRawClientFn(mockRawClient), translations.NullTranslationHelper)
+
+			// Create call request
+			args := map[string]interface{}{
+				"owner": "owner",
+				"repo":  "repo",
+				"path":  "file.txt",
+				"sha":   "abc123",
+			}
+			for k, v := range tc.requestArgs {
+				args[k] = v
+			}
+			request := createMCPRequest(args)
+
+			// Call handler
+			result, err := handler(context.Background(), request)
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			require.False(t, result.IsError)
+			message, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+			assert.Equal(t, tc.expectedMessage, message.Text)
+			if tc.expectedBlob != "" {
+				assert.Equal(t, tc.expectedBlob, getBlobResourceResult(t, result).Blob)
+				return
+			}
+			assert.Equal(t, tc.expectedText, getTextResourceResult(t, result).Text)
+		})
+	}
+}
+
+func Test_GetFiles(t *testing.T) {
+	// Verify tool definition once
+	mockClient := github.NewClient(nil)
+	mockRawClient := raw.NewClient(mockClient, &url.URL{Scheme: "https", Host: "raw.githubusercontent.com", Path: "/"})
+	tool, _ := GetFiles(stubGetClientFn(mockClient), stubGetRawClientFn(mockRawClient), translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "get_files", tool.Name)
+	assert.NotEmpty(t, tool.Description)
+	assert.Contains(t, tool.InputSchema.Properties, "paths")
+	assert.Contains(t, tool.InputSchema.Properties, "ref")
+	assert.Contains(t, tool.InputSchema.Properties, "sha")
+	assert.Contains(t, tool.InputSchema.Properties, "max_total_bytes")
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "paths"})
+
+	contents := map[string]string{
+		"README.md":      "# Test Repository\n",
+		"pkg/a.go":       "package pkg\n",
+		"pkg/b.go":       "package pkg\n\nfunc B() {}\n",
+		"pkg/b_test.go":  "package pkg\n",
+		"pkg/logo.png":   "\x89PNG\x00\x00",
+		"pkg/sub/c.go":   "package sub\n",
+		"docs/héllo.txt": "héllo",
+	}
+	mockTree := &github.Tree{SHA: github.Ptr("abc123")}
+	for _, p := range []string{"README.md", "docs/héllo.txt", "pkg/a.go", "pkg/b.go", "pkg/b_test.go", "pkg/logo.png", "pkg/sub/c.go"} {
+		mockTree.Entries = append(mockTree.Entries, &github.TreeEntry{
+			Path: github.Ptr(p),
+			Type: github.Ptr("blob"),
+			SHA:  github.Ptr("sha-" + p),
+			Size: github.Ptr(len(contents[p])),
+		})
+	}
+	mockTree.Entries = append(mockTree.Entries, &github.TreeEntry{Path: github.Ptr("pkg"), Type: github.Ptr("tree"), SHA: github.Ptr("sha-pkg")})
+
+	rawContentHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := strings.TrimPrefix(r.URL.Path, "/owner/repo/abc123/")
+		content, ok := contents[p]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(content))
+	})
+
+	tests := []struct {
+		name           string
+		mockedClient   *http.Client
+		requestArgs    map[string]interface{}
+		expectError    bool
+		expectedResult FilesResponse
+		expectedErrMsg string
+	}{
+		{
+			name: "get files by path and pattern",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+					expectQueryParams(t, map[string]string{"recursive": "1"}).andThen(
+						mockResponse(t, http.StatusOK, mockTree),
+					),
+				),
+				mock.WithRequestMatchHandler(
+					raw.GetRawReposContentsByOwnerByRepoBySHAByPath,
+					rawContentHandler,
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner": "owner",
+				"repo":  "repo",
+				"sha":   "abc123",
+				"paths": []interface{}{"README.md", "pkg/*.go", "pkg/a.go", "missing/"},
+			},
+			expectedResult: FilesResponse{
+				SHA: "abc123",
+				Files: []RetrievedFile{
+					{Path: "README.md", SHA: "sha-README.md", Size: 18, Content: "# Test Repository\n"},
+					{Path: "pkg/a.go", SHA: "sha-pkg/a.go", Size: 12, Content: "package pkg\n"},
+					{Path: "pkg/b.go", SHA: "sha-pkg/b.go", Size: 25, Content: "package pkg\n\nfunc B() {}\n"},
+					{Path: "pkg/b_test.go", SHA: "sha-pkg/b_test.go", Size: 12, Content: "package pkg\n"},
+				},
+				Unmatched:  []string{"missing/"},
+				TotalBytes: 67,
+			},
+		},
+		{
+			name: "directory with binary file and size budget",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+					mockResponse(t, http.StatusOK, mockTree),
+				),
+				mock.WithRequestMatchHandler(
+					raw.GetRawReposContentsByOwnerByRepoBySHAByPath,
+					rawContentHandler,
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":           "owner",
+				"repo":            "repo",
+				"sha":             "abc123",
+				"paths":           []interface{}{"pkg/"},
+				"max_total_bytes": float64(30),
+			},
+			expectedResult: FilesResponse{
+				SHA: "abc123",
+				Files: []RetrievedFile{
+					{Path: "pkg/a.go", SHA: "sha-pkg/a.go", Size: 12, Content: "package pkg\n"},
+					{Path: "pkg/b.go", SHA: "sha-pkg/b.go", Size: 25, Content: "package pkg\n\nfunc ", Truncated: true},
+					{Path: "pkg/b_test.go", SHA: "sha-pkg/b_test.go", Size: 12, Truncated: true},
+					// Files beyond the budget are not fetched, so a binary file among them is not recognized
+					{Path: "pkg/logo.png", SHA: "sha-pkg/logo.png", Size: 6, Truncated: true},
+					{Path: "pkg/sub/c.go", SHA: "sha-pkg/sub/c.go", Size: 12, Truncated: true},
+				},
+				TotalBytes: 30,
+				BudgetHit:  true,
+			},
+		},
+		{
+			name: "binary file does not spend the size budget",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+					mockResponse(t, http.StatusOK, mockTree),
+				),
+				mock.WithRequestMatchHandler(
+					raw.GetRawReposContentsByOwnerByRepoBySHAByPath,
+					rawContentHandler,
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":           "owner",
+				"repo":            "repo",
+				"sha":             "abc123",
+				"paths":           []interface{}{"pkg/logo.png", "pkg/sub/c.go"},
+				"max_total_bytes": float64(12),
+			},
+			expectedResult: FilesResponse{
+				SHA: "abc123",
+				Files: []RetrievedFile{
+					{Path: "pkg/logo.png", SHA: "sha-pkg/logo.png", Size: 6, Binary: true},
+					{Path: "pkg/sub/c.go", SHA: "sha-pkg/sub/c.go", Size: 12, Content: "package sub\n"},
+				},
+				TotalBytes: 12,
+			},
+		},
+		{
+			name: "binary file",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+					mockResponse(t, http.StatusOK, mockTree),
+				),
+				mock.WithRequestMatchHandler(
+					raw.GetRawReposContentsByOwnerByRepoBySHAByPath,
+					rawContentHandler,
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":           "owner",
+				"repo":            "repo",
+				"sha":             "abc123",
+				"paths":           []interface{}{"pkg/logo.png", "docs/*"},
+				"max_total_bytes": float64(12),
+			},
+			expectedResult: FilesResponse{
+				SHA: "abc123",
+				Files: []RetrievedFile{
+					{Path: "docs/héllo.txt", SHA: "sha-docs/héllo.txt", Size: 6, Content: "héllo"},
+					{Path: "pkg/logo.png", SHA: "sha-pkg/logo.png", Size: 6, Binary: true},
+				},
+				TotalBytes: 6,
+			},
+		},
+		{
+			name: "cut inside a rune",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+					mockResponse(t, http.StatusOK, mockTree),
+				),
+				mock.WithRequestMatchHandler(
+					raw.GetRawReposContentsByOwnerByRepoBySHAByPath,
+					rawContentHandler,
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":           "owner",
+				"repo":            "repo",
+				"sha":             "abc123",
+				"paths":           []interface{}{"docs/héllo.txt"},
+				"max_total_bytes": float64(2),
+			},
+			expectedResult: FilesResponse{
+				SHA: "abc123",
+				Files: []RetrievedFile{
+					{Path: "docs/héllo.txt", SHA: "sha-docs/héllo.txt", Size: 6, Content: "h", Truncated: true},
+				},
+				TotalBytes: 1,
+				BudgetHit:  true,
+			},
+		},
+		{
+			name: "file missing from raw content",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+					mockResponse(t, http.StatusOK, &github.Tree{
+						SHA:       github.Ptr("abc123"),
+						Truncated: github.Ptr(true),
+						Entries: []*github.TreeEntry{
+							{Path: github.Ptr("gone.txt"), Type: github.Ptr("blob"), SHA: github.Ptr("sha-gone"), Size: github.Ptr(4)},
+						},
+					}),
+				),
+				mock.WithRequestMatchHandler(
+					raw.GetRawReposContentsByOwnerByRepoBySHAByPath,
+					rawContentHandler,
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner": "owner",
+				"repo":  "repo",
+				"sha":   "abc123",
+				"paths": []interface{}{"gone.txt"},
+			},
+			expectedResult: FilesResponse{
+				SHA: "abc123",
+				Files: []RetrievedFile{
+					{Path: "gone.txt", SHA: "sha-gone", Size: 4, Error: "failed to get raw content: 404 Not Found"},
+				},
+				TreeTruncated: true,
+			},
+		},
+		{
+			name:         "no paths",
+			mockedClient: mock.NewMockedHTTPClient(),
+			requestArgs: map[string]interface{}{
+				"owner": "owner",
+				"repo":  "repo",
+				"paths": []interface{}{},
+			},
+			expectError:    true,
+			expectedErrMsg: "paths must list at least one path or pattern",
+		},
+		{
+			name: "tree fetch fails",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+					mockResponse(t, http.StatusNotFound, `{"message": "Not Found"}`),
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner": "owner",
+				"repo":  "repo",
+				"sha":   "abc123",
+				"paths": []interface{}{"README.md"},
+			},
+			expectError:    true,
+			expectedErrMsg: "failed to get git tree",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup client with mock
+			client := github.NewClient(tc.mockedClient)
+			mockRawClient := raw.NewClient(client, &url.URL{Scheme: "https", Host: "raw.example.com", Path: "/"})
+			_, handler := GetFiles(stubGetClientFn(client), stubGetRawClientFn(mockRawClient), translations.NullTranslationHelper)
+
+			// Create call request
+			request := createMCPRequest(tc.requestArgs)
+
+			// Call handler
+			result, err := handler(context.Background(), request)
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			require.False(t, result.IsError)
+			textContent := getTextResult(t, result)
+			var response FilesResponse
+			require.NoError(t, json.Unmarshal([]byte(textContent.Text), &response))
+			assert.Equal(t, tc.expectedResult, response)
+		})
+	}
+
+	t.Run("files beyond the budget are not fetched", func(t *testing.T) {
+		var mu sync.Mutex
+		var fetched []string
+		client := github.NewClient(mock.NewMockedHTTPClient(
+			mock.WithRequestMatchHandler(
+				mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+				mockResponse(t, http.StatusOK, mockTree),
+			),
+			mock.WithRequestMatchHandler(
+				raw.GetRawReposContentsByOwnerByRepoBySHAByPath,
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					mu.Lock()
+					fetched = append(fetched, strings.TrimPrefix(r.URL.Path, "/owner/repo/abc123/"))
+					mu.Unlock()
+					rawContentHandler(w, r)
+				}),
+			),
+		))
+		mockRawClient := raw.NewClient(client, &url.URL{Scheme: "https", Host: "raw.example.com", Path: "/"})
+		_, handler := GetFiles(stubGetClientFn(client), stubGetRawClientFn(mockRawClient), translations.NullTranslationHelper)
+
+		result, err := handler(context.Background(), createMCPRequest(map[string]interface{}{
+			"owner":           "owner",
+			"repo":            "repo",
+			"sha":             "abc123",
+			"paths":           []interface{}{"pkg/"},
+			"max_total_bytes": float64(12),
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		assert.Equal(t, []string{"pkg/a.go"}, fetched)
+	})
+}
+
 func Test_ForkRepository(t *testing.T) {
 	// Verify tool definition once
 	mockClient := github.NewClient(nil)
@@ -561,6 +1120,50 @@ func Test_CreateBranch(t *testing.T) {
 			expectError:    true,
 			expectedErrMsg: "failed to get reference",
 		},
+		{
+			name: "conflict when source branch has moved",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposGitRefByOwnerByRepoByRef,
+					mockSourceRef,
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":             "owner",
+				"repo":              "repo",
+				"branch":            "new-feature",
+				"from_branch":       "main",
+				"expected_head_sha": "old111",
+			},
+			expectError:    true,
+			expectedErrMsg: `"status":"conflict","ref":"refs/heads/main","expected_head_sha":"old111","head_sha":"abc123def456"`,
+		},
+		{
+			name: "conflict when branch already exists",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposGitRefByOwnerByRepoByRef,
+					mockSourceRef,
+					&github.Reference{
+						Ref:    github.Ptr("refs/heads/existing-branch"),
+						Object: &github.GitObject{SHA: github.Ptr("fed987")},
+					},
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitRefsByOwnerByRepo,
+					mockResponse(t, http.StatusUnprocessableEntity, map[string]string{"message": "Reference already exists"}),
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":             "owner",
+				"repo":              "repo",
+				"branch":            "existing-branch",
+				"from_branch":       "main",
+				"expected_head_sha": "abc123def456",
+			},
+			expectError:    true,
+			expectedErrMsg: `"head_sha":"fed987","message":"No branch was created: existing-branch already exists`,
+		},
 		{
 			name: "fail to create branch",
 			mockedClient: mock.NewMockedHTTPClient(
@@ -627,7 +1230,7 @@ func Test_CreateBranch(t *testing.T) {
 func Test_GetCommit(t *testing.T) {
 	// Verify tool definition once
 	mockClient := github.NewClient(nil)
-	tool, _ := GetCommit(stubGetClientFn(mockClient), translations.NullTranslationHelper)
+	tool, _ := GetCommit(stubGetClientFn(mockClient), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 	require.NoError(t, toolsnaps.Test(tool.Name, tool))
 
 	assert.Equal(t, "get_commit", tool.Name)
@@ -717,7 +1320,7 @@ func Test_GetCommit(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Setup client with mock
 			client := github.NewClient(tc.mockedClient)
-			_, handler := GetCommit(stubGetClientFn(client), translations.NullTranslationHelper)
+			_, handler := GetCommit(stubGetClientFn(client), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 
 			// Create call request
 			request := createMCPRequest(tc.requestArgs)
@@ -756,7 +1359,7 @@ func Test_GetCommit(t *testing.T) {
 func Test_ListCommits(t *testing.T) {
 	// Verify tool definition once
 	mockClient := github.NewClient(nil)
-	tool, _ := ListCommits(stubGetClientFn(mockClient), translations.NullTranslationHelper)
+	tool, _ := ListCommits(stubGetClientFn(mockClient), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 	require.NoError(t, toolsnaps.Test(tool.Name, tool))
 
 	assert.Equal(t, "list_commits", tool.Name)
@@ -937,48 +1540,220 @@ func Test_ListCommits(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Setup client with mock
 			client := github.NewClient(tc.mockedClient)
-			_, handler := ListCommits(stubGetClientFn(client), translations.NullTranslationHelper)
+			_, handler := ListCommits(stubGetClientFn(client), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 
 			// Create call request
 			request := createMCPRequest(tc.requestArgs)
 
-			// Call handler
-			result, err := handler(context.Background(), request)
+			// Call handler
+			result, err := handler(context.Background(), request)
+
+			// Verify results
+			if tc.expectError {
+				require.NoError(t, err)
+				require.True(t, result.IsError)
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			require.False(t, result.IsError)
+
+			// Parse the result and get the text content if no error
+			textContent := getTextResult(t, result)
+
+			// Unmarshal and verify the result
+			var returnedCommits []MinimalCommit
+			err = json.Unmarshal([]byte(textContent.Text), &returnedCommits)
+			require.NoError(t, err)
+			assert.Len(t, returnedCommits, len(tc.expectedCommits))
+			for i, commit := range returnedCommits {
+				assert.Equal(t, tc.expectedCommits[i].GetSHA(), commit.SHA)
+				assert.Equal(t, tc.expectedCommits[i].GetHTMLURL(), commit.HTMLURL)
+				if tc.expectedCommits[i].Commit != nil {
+					assert.Equal(t, tc.expectedCommits[i].Commit.GetMessage(), commit.Commit.Message)
+				}
+				if tc.expectedCommits[i].Author != nil {
+					assert.Equal(t, tc.expectedCommits[i].Author.GetLogin(), commit.Author.Login)
+				}
+
+				// Files and stats are never included in list_commits
+				assert.Nil(t, commit.Files)
+				assert.Nil(t, commit.Stats)
+			}
+		})
+	}
+}
+
+func Test_CompareRefs(t *testing.T) {
+	// Verify tool definition once
+	mockClient := github.NewClient(nil)
+	tool, _ := CompareRefs(stubGetClientFn(mockClient), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "compare_refs", tool.Name)
+	assert.NotEmpty(t, tool.Description)
+	assert.Contains(t, tool.InputSchema.Properties, "base")
+	assert.Contains(t, tool.InputSchema.Properties, "head")
+	assert.Contains(t, tool.InputSchema.Properties, "include_patch")
+	assert.Contains(t, tool.InputSchema.Properties, "path_filter")
+	assert.Contains(t, tool.InputSchema.Properties, "max_patch_bytes")
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"owner", "repo", "base", "head"})
+
+	mockComparison := &github.CommitsComparison{
+		Status:          github.Ptr("diverged"),
+		AheadBy:         github.Ptr(2),
+		BehindBy:        github.Ptr(1),
+		TotalCommits:    github.Ptr(2),
+		HTMLURL:         github.Ptr("https://github.com/owner/repo/compare/main...contributor:feature"),
+		MergeBaseCommit: &github.RepositoryCommit{SHA: github.Ptr("base000")},
+		Commits: []*github.RepositoryCommit{
+			{SHA: github.Ptr("abc123"), Commit: &github.Commit{Message: github.Ptr("Add API docs")}},
+			{SHA: github.Ptr("def456"), Commit: &github.Commit{Message: github.Ptr("Fix handler")}},
+		},
+		Files: []*github.CommitFile{
+			{Filename: github.Ptr("docs/api.md"), Status: github.Ptr("added"), Additions: github.Ptr(3), Changes: github.Ptr(3), Patch: github.Ptr("@@ -0,0 +1,3 @@\n+a\n+b\n+c")},
+			{Filename: github.Ptr("src/api/handler.go"), Status: github.Ptr("modified"), Additions: github.Ptr(1), Deletions: github.Ptr(1), Changes: github.Ptr(2), Patch: github.Ptr("@@ -1 +1 @@\n-x\n+y")},
+			{Filename: github.Ptr("docs/guide.md"), PreviousFilename: github.Ptr("guide.md"), Status: github.Ptr("renamed")},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		mockedClient   *http.Client
+		requestArgs    map[string]interface{}
+		expectError    bool
+		expectedErrMsg string
+		expectedFiles  []MinimalComparedFile
+	}{
+		{
+			name: "compares fork branch without patches",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposCompareByOwnerByRepoByBasehead,
+					expectPath(t, "/repos/owner/repo/compare/main...contributor:feature").andThen(
+						mockResponse(t, http.StatusOK, mockComparison),
+					),
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner": "owner",
+				"repo":  "repo",
+				"base":  "main",
+				"head":  "contributor:feature",
+			},
+			expectedFiles: []MinimalComparedFile{
+				{Filename: "docs/api.md", Status: "added", Additions: 3, Changes: 3},
+				{Filename: "src/api/handler.go", Status: "modified", Additions: 1, Deletions: 1, Changes: 2},
+				{Filename: "docs/guide.md", PreviousFilename: "guide.md", Status: "renamed"},
+			},
+		},
+		{
+			name: "filters files and limits patches to the budget",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposCompareByOwnerByRepoByBasehead,
+					mockComparison,
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":           "owner",
+				"repo":            "repo",
+				"base":            "main",
+				"head":            "contributor:feature",
+				"include_patch":   true,
+				"path_filter":     "docs/*.md",
+				"max_patch_bytes": float64(10),
+			},
+			expectedFiles: []MinimalComparedFile{
+				{Filename: "docs/api.md", Status: "added", Additions: 3, Changes: 3, PatchOmitted: true},
+				{Filename: "docs/guide.md", PreviousFilename: "guide.md", Status: "renamed"},
+			},
+		},
+		{
+			name: "filters files by directory",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposCompareByOwnerByRepoByBasehead,
+					mockComparison,
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":         "owner",
+				"repo":          "repo",
+				"base":          "main",
+				"head":          "contributor:feature",
+				"include_patch": true,
+				"path_filter":   "src/",
+			},
+			expectedFiles: []MinimalComparedFile{
+				{Filename: "src/api/handler.go", Status: "modified", Additions: 1, Deletions: 1, Changes: 2, Patch: "@@ -1 +1 @@\n-x\n+y"},
+			},
+		},
+		{
+			name:         "invalid path filter",
+			mockedClient: mock.NewMockedHTTPClient(),
+			requestArgs: map[string]interface{}{
+				"owner":       "owner",
+				"repo":        "repo",
+				"base":        "main",
+				"head":        "feature",
+				"path_filter": "docs/[",
+			},
+			expectError:    true,
+			expectedErrMsg: "invalid path_filter",
+		},
+		{
+			name: "unknown ref",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetReposCompareByOwnerByRepoByBasehead,
+					mockResponse(t, http.StatusNotFound, map[string]string{"message": "Not Found"}),
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner": "owner",
+				"repo":  "repo",
+				"base":  "main",
+				"head":  "missing",
+			},
+			expectError:    true,
+			expectedErrMsg: "failed to compare main...missing",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := github.NewClient(tc.mockedClient)
+			_, handler := CompareRefs(stubGetClientFn(client), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
+
+			result, err := handler(context.Background(), createMCPRequest(tc.requestArgs))
+			require.NoError(t, err)
 
-			// Verify results
 			if tc.expectError {
-				require.NoError(t, err)
 				require.True(t, result.IsError)
 				errorContent := getErrorResult(t, result)
 				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
 				return
 			}
 
-			require.NoError(t, err)
 			require.False(t, result.IsError)
-
-			// Parse the result and get the text content if no error
 			textContent := getTextResult(t, result)
 
-			// Unmarshal and verify the result
-			var returnedCommits []MinimalCommit
-			err = json.Unmarshal([]byte(textContent.Text), &returnedCommits)
-			require.NoError(t, err)
-			assert.Len(t, returnedCommits, len(tc.expectedCommits))
-			for i, commit := range returnedCommits {
-				assert.Equal(t, tc.expectedCommits[i].GetSHA(), commit.SHA)
-				assert.Equal(t, tc.expectedCommits[i].GetHTMLURL(), commit.HTMLURL)
-				if tc.expectedCommits[i].Commit != nil {
-					assert.Equal(t, tc.expectedCommits[i].Commit.GetMessage(), commit.Commit.Message)
-				}
-				if tc.expectedCommits[i].Author != nil {
-					assert.Equal(t, tc.expectedCommits[i].Author.GetLogin(), commit.Author.Login)
-				}
-
-				// Files and stats are never included in list_commits
-				assert.Nil(t, commit.Files)
-				assert.Nil(t, commit.Stats)
+			var comparison struct {
+				MinimalComparison
+				Commits []MinimalCommit `json:"commits"`
 			}
+			require.NoError(t, json.Unmarshal([]byte(textContent.Text), &comparison))
+			assert.Equal(t, "diverged", comparison.Status)
+			assert.Equal(t, 2, comparison.AheadBy)
+			assert.Equal(t, 1, comparison.BehindBy)
+			assert.Equal(t, "base000", comparison.MergeBaseSHA)
+			assert.Equal(t, 3, comparison.TotalFiles)
+			require.Len(t, comparison.Commits, 2)
+			assert.Equal(t, "Add API docs", comparison.Commits[0].Commit.Message)
+			assert.Equal(t, tc.expectedFiles, comparison.Files)
 		})
 	}
 }
@@ -1531,6 +2306,279 @@ func Test_PushFiles(t *testing.T) {
 			expectError:    false, // This returns a tool error, not a Go error
 			expectedErrMsg: "each file must have content",
 		},
+		{
+			name: "successful push of deletions, renames, modes and binary content",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposGitRefByOwnerByRepoByRef,
+					mockRef,
+				),
+				mock.WithRequestMatch(
+					mock.GetReposGitCommitsByOwnerByRepoByCommitSha,
+					mockCommit,
+				),
+				// Look up the renamed file in the base tree
+				mock.WithRequestMatchHandler(
+					mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+					expect(t, expectations{
+						path:        "/repos/owner/repo/git/trees/def456",
+						queryParams: map[string]string{"recursive": "1"},
+					}).andThen(
+						mockResponse(t, http.StatusOK, &github.Tree{
+							SHA: github.Ptr("def456"),
+							Entries: []*github.TreeEntry{
+								{Path: github.Ptr("run.sh"), Mode: github.Ptr("100755"), Type: github.Ptr("blob"), SHA: github.Ptr("blob111")},
+							},
+						}),
+					),
+				),
+				// Upload the binary file
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitBlobsByOwnerByRepo,
+					expectRequestBody(t, map[string]interface{}{
+						"content":  "iVBORw0KGgo=",
+						"encoding": "base64",
+					}).andThen(
+						mockResponse(t, http.StatusCreated, &github.Blob{SHA: github.Ptr("blob222")}),
+					),
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitTreesByOwnerByRepo,
+					expectRequestBody(t, map[string]interface{}{
+						"base_tree": "def456",
+						"tree": []interface{}{
+							map[string]interface{}{"path": "old.txt", "mode": "100644", "type": "blob", "sha": nil},
+							map[string]interface{}{"path": "run.sh", "mode": "100755", "type": "blob", "sha": nil},
+							map[string]interface{}{"path": "scripts/run.sh", "mode": "100755", "type": "blob", "sha": "blob111"},
+							map[string]interface{}{"path": "bin/tool", "mode": "100755", "type": "blob", "content": "#!/bin/sh\necho hi\n"},
+							map[string]interface{}{"path": "logo.png", "mode": "100644", "type": "blob", "sha": "blob222"},
+						},
+					}).andThen(
+						mockResponse(t, http.StatusCreated, mockTree),
+					),
+				),
+				mock.WithRequestMatch(
+					mock.PostReposGitCommitsByOwnerByRepo,
+					mockNewCommit,
+				),
+				mock.WithRequestMatch(
+					mock.PatchReposGitRefsByOwnerByRepoByRef,
+					mockUpdatedRef,
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":  "owner",
+				"repo":   "repo",
+				"branch": "main",
+				"files": []interface{}{
+					map[string]interface{}{"path": "old.txt", "operation": "delete"},
+					map[string]interface{}{"path": "scripts/run.sh", "operation": "rename", "previous_path": "run.sh"},
+					map[string]interface{}{"path": "bin/tool", "content": "#!/bin/sh\necho hi\n", "mode": "100755"},
+					map[string]interface{}{"path": "logo.png", "content": "iVBORw0KGgo=", "encoding": "base64"},
+				},
+				"message": "Reorganize files",
+			},
+			expectError: false,
+			expectedRef: mockUpdatedRef,
+		},
+		{
+			name: "fails to rename file missing from the branch",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposGitRefByOwnerByRepoByRef,
+					mockRef,
+				),
+				mock.WithRequestMatch(
+					mock.GetReposGitCommitsByOwnerByRepoByCommitSha,
+					mockCommit,
+				),
+				mock.WithRequestMatch(
+					mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+					&github.Tree{SHA: github.Ptr("def456")},
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":  "owner",
+				"repo":   "repo",
+				"branch": "main",
+				"files": []interface{}{
+					map[string]interface{}{"path": "b.md", "operation": "rename", "previous_path": "a.md"},
+				},
+				"message": "Rename file",
+			},
+			expectError:    false,
+			expectedErrMsg: "cannot rename a.md: no such file on the branch",
+		},
+		{
+			name:         "fails when deleted file has content",
+			mockedClient: mock.NewMockedHTTPClient(),
+			requestArgs: map[string]interface{}{
+				"owner":  "owner",
+				"repo":   "repo",
+				"branch": "main",
+				"files": []interface{}{
+					map[string]interface{}{"path": "a.md", "operation": "delete", "content": "x"},
+				},
+				"message": "Delete file",
+			},
+			expectError:    false,
+			expectedErrMsg: "file a.md is deleted, so it takes no content, mode or encoding",
+		},
+		{
+			name:         "fails when renamed file has no previous path",
+			mockedClient: mock.NewMockedHTTPClient(),
+			requestArgs: map[string]interface{}{
+				"owner":  "owner",
+				"repo":   "repo",
+				"branch": "main",
+				"files": []interface{}{
+					map[string]interface{}{"path": "b.md", "operation": "rename"},
+				},
+				"message": "Rename file",
+			},
+			expectError:    false,
+			expectedErrMsg: "file b.md is renamed, so it needs a previous_path other than its path",
+		},
+		{
+			name:         "fails when base64 content is invalid",
+			mockedClient: mock.NewMockedHTTPClient(),
+			requestArgs: map[string]interface{}{
+				"owner":  "owner",
+				"repo":   "repo",
+				"branch": "main",
+				"files": []interface{}{
+					map[string]interface{}{"path": "logo.png", "content": "not base64!", "encoding": "base64"},
+				},
+				"message": "Add logo",
+			},
+			expectError:    false,
+			expectedErrMsg: "content of file logo.png is not valid base64",
+		},
+		{
+			name:         "fails when a path is changed twice",
+			mockedClient: mock.NewMockedHTTPClient(),
+			requestArgs: map[string]interface{}{
+				"owner":  "owner",
+				"repo":   "repo",
+				"branch": "main",
+				"files": []interface{}{
+					map[string]interface{}{"path": "b.md", "operation": "rename", "previous_path": "a.md"},
+					map[string]interface{}{"path": "a.md", "content": "new"},
+				},
+				"message": "Update files",
+			},
+			expectError:    false,
+			expectedErrMsg: "file a.md is changed more than once",
+		},
+		{
+			name: "rebases onto moved branch when other files changed",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposGitRefByOwnerByRepoByRef,
+					mockRef,
+				),
+				mock.WithRequestMatchHandler(
+					mock.GetReposCompareByOwnerByRepoByBasehead,
+					expectPath(t, "/repos/owner/repo/compare/old111...abc123").andThen(
+						mockResponse(t, http.StatusOK, &github.CommitsComparison{
+							Status: github.Ptr("ahead"),
+							Files:  []*github.CommitFile{{Filename: github.Ptr("docs/other.md")}},
+						}),
+					),
+				),
+				mock.WithRequestMatch(
+					mock.GetReposGitCommitsByOwnerByRepoByCommitSha,
+					mockCommit,
+				),
+				mock.WithRequestMatch(
+					mock.PostReposGitTreesByOwnerByRepo,
+					mockTree,
+				),
+				mock.WithRequestMatchHandler(
+					mock.PostReposGitCommitsByOwnerByRepo,
+					expectRequestBody(t, map[string]interface{}{
+						"message": "Update README",
+						"tree":    "ghi789",
+						"parents": []interface{}{"abc123"},
+					}).andThen(
+						mockResponse(t, http.StatusCreated, mockNewCommit),
+					),
+				),
+				mock.WithRequestMatch(
+					mock.PatchReposGitRefsByOwnerByRepoByRef,
+					mockUpdatedRef,
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":  "owner",
+				"repo":   "repo",
+				"branch": "main",
+				"files": []interface{}{
+					map[string]interface{}{"path": "README.md", "content": "# README"},
+				},
+				"message":           "Update README",
+				"expected_head_sha": "old111",
+			},
+			expectError: false,
+			expectedRef: mockUpdatedRef,
+		},
+		{
+			name: "conflict when moved branch changed the same files",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposGitRefByOwnerByRepoByRef,
+					mockRef,
+				),
+				mock.WithRequestMatch(
+					mock.GetReposCompareByOwnerByRepoByBasehead,
+					&github.CommitsComparison{
+						Status: github.Ptr("ahead"),
+						Files: []*github.CommitFile{
+							{Filename: github.Ptr("docs/other.md")},
+							{Filename: github.Ptr("docs/guide/intro.md")},
+						},
+					},
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":  "owner",
+				"repo":   "repo",
+				"branch": "main",
+				"files": []interface{}{
+					map[string]interface{}{"path": "README.md", "content": "# README"},
+					map[string]interface{}{"path": "docs/guide", "operation": "delete"},
+				},
+				"message":           "Update docs",
+				"expected_head_sha": "old111",
+			},
+			expectError:    true,
+			expectedErrMsg: `"expected_head_sha":"old111","head_sha":"abc123","conflicting_paths":["docs/guide"]`,
+		},
+		{
+			name: "conflict when branch was rewritten",
+			mockedClient: mock.NewMockedHTTPClient(
+				mock.WithRequestMatch(
+					mock.GetReposGitRefByOwnerByRepoByRef,
+					mockRef,
+				),
+				mock.WithRequestMatch(
+					mock.GetReposCompareByOwnerByRepoByBasehead,
+					&github.CommitsComparison{Status: github.Ptr("diverged")},
+				),
+			),
+			requestArgs: map[string]interface{}{
+				"owner":  "owner",
+				"repo":   "repo",
+				"branch": "main",
+				"files": []interface{}{
+					map[string]interface{}{"path": "README.md", "content": "# README"},
+				},
+				"message":           "Update README",
+				"expected_head_sha": "old111",
+			},
+			expectError:    true,
+			expectedErrMsg: "branch main was rewritten, so old111 is no longer in its history",
+		},
 		{
 			name: "fails to get branch reference",
 			mockedClient: mock.NewMockedHTTPClient(
@@ -1665,6 +2713,96 @@ func Test_PushFiles(t *testing.T) {
 	}
 }
 
+func Test_PushFiles_BranchMovedDuringPush(t *testing.T) {
+	ref := func(sha string) *github.Reference {
+		return &github.Reference{Ref: github.Ptr("refs/heads/main"), Object: &github.GitObject{SHA: github.Ptr(sha)}}
+	}
+	commit := func(sha, tree string) *github.Commit {
+		return &github.Commit{SHA: github.Ptr(sha), Tree: &github.Tree{SHA: github.Ptr(tree)}}
+	}
+
+	tests := []struct {
+		name           string
+		changedFiles   []*github.CommitFile
+		expectedErrMsg string
+	}{
+		{
+			name:         "rebases when other files changed",
+			changedFiles: []*github.CommitFile{{Filename: github.Ptr("src/main.go")}},
+		},
+		{
+			name:           "conflict when a renamed file changed",
+			changedFiles:   []*github.CommitFile{{Filename: github.Ptr("docs/new.md"), PreviousFilename: github.Ptr("docs/old.md")}},
+			expectedErrMsg: `"conflicting_paths":["docs/old.md"]`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var parents, baseTrees []any
+			updates := 0
+			mockedClient := mock.NewMockedHTTPClient(
+				// The branch moves from abc123 to moved1 between reading it and updating it
+				mock.WithRequestMatch(mock.GetReposGitRefByOwnerByRepoByRef, ref("abc123"), ref("moved1")),
+				mock.WithRequestMatch(mock.GetReposGitCommitsByOwnerByRepoByCommitSha, commit("abc123", "tree1"), commit("moved1", "tree2")),
+				mock.WithRequestMatch(mock.GetReposGitTreesByOwnerByRepoByTreeSha, &github.Tree{
+					Entries: []*github.TreeEntry{{Path: github.Ptr("docs/old.md"), Mode: github.Ptr("100644"), Type: github.Ptr("blob"), SHA: github.Ptr("blob1")}},
+				}),
+				mock.WithRequestMatchHandler(mock.PostReposGitTreesByOwnerByRepo, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					var body map[string]any
+					require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+					baseTrees = append(baseTrees, body["base_tree"])
+					mockResponse(t, http.StatusCreated, &github.Tree{SHA: github.Ptr("newtree")})(w, r)
+				})),
+				mock.WithRequestMatchHandler(mock.PostReposGitCommitsByOwnerByRepo, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					var body map[string]any
+					require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+					parents = append(parents, body["parents"])
+					mockResponse(t, http.StatusCreated, &github.Commit{SHA: github.Ptr("newcommit")})(w, r)
+				})),
+				mock.WithRequestMatchHandler(mock.PatchReposGitRefsByOwnerByRepoByRef, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					updates++
+					if updates == 1 {
+						mockResponse(t, http.StatusUnprocessableEntity, map[string]string{"message": "Update is not a fast forward"})(w, r)
+						return
+					}
+					mockResponse(t, http.StatusOK, ref("newcommit"))(w, r)
+				})),
+				mock.WithRequestMatchHandler(
+					mock.GetReposCompareByOwnerByRepoByBasehead,
+					expectPath(t, "/repos/owner/repo/compare/abc123...moved1").andThen(
+						mockResponse(t, http.StatusOK, &github.CommitsComparison{Status: github.Ptr("ahead"), Files: tc.changedFiles}),
+					),
+				),
+			)
+			_, handler := PushFiles(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+
+			result, err := handler(context.Background(), createMCPRequest(map[string]any{
+				"owner":   "owner",
+				"repo":    "repo",
+				"branch":  "main",
+				"message": "Move docs",
+				"files": []any{
+					map[string]any{"path": "docs/moved.md", "operation": "rename", "previous_path": "docs/old.md"},
+				},
+			}))
+			require.NoError(t, err)
+
+			if tc.expectedErrMsg != "" {
+				assert.Contains(t, getErrorResult(t, result).Text, tc.expectedErrMsg)
+				assert.Equal(t, 1, updates)
+				return
+			}
+
+			var returnedRef github.Reference
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &returnedRef))
+			assert.Equal(t, "newcommit", returnedRef.GetObject().GetSHA())
+			assert.Equal(t, []any{"tree1", "tree2"}, baseTrees)
+			assert.Equal(t, []any{[]any{"abc123"}, []any{"moved1"}}, parents)
+		})
+	}
+}
+
 func Test_ListBranches(t *testing.T) {
 	// Verify tool definition once
 	mockClient := github.NewClient(nil)
@@ -2233,7 +3371,7 @@ func Test_GetTag(t *testing.T) {
 
 func Test_ListReleases(t *testing.T) {
 	mockClient := github.NewClient(nil)
-	tool, _ := ListReleases(stubGetClientFn(mockClient), translations.NullTranslationHelper)
+	tool, _ := ListReleases(stubGetClientFn(mockClient), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 
 	assert.Equal(t, "list_releases", tool.Name)
 	assert.NotEmpty(t, tool.Description)
@@ -2300,7 +3438,7 @@ func Test_ListReleases(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			client := github.NewClient(tc.mockedClient)
-			_, handler := ListReleases(stubGetClientFn(client), translations.NullTranslationHelper)
+			_, handler := ListReleases(stubGetClientFn(client), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 			request := createMCPRequest(tc.requestArgs)
 			result, err := handler(context.Background(), request)
 
@@ -2324,7 +3462,7 @@ func Test_ListReleases(t *testing.T) {
 }
 func Test_GetLatestRelease(t *testing.T) {
 	mockClient := github.NewClient(nil)
-	tool, _ := GetLatestRelease(stubGetClientFn(mockClient), translations.NullTranslationHelper)
+	tool, _ := GetLatestRelease(stubGetClientFn(mockClient), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 
 	assert.Equal(t, "get_latest_release", tool.Name)
 	assert.NotEmpty(t, tool.Description)
@@ -2384,7 +3522,7 @@ func Test_GetLatestRelease(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			client := github.NewClient(tc.mockedClient)
-			_, handler := GetLatestRelease(stubGetClientFn(client), translations.NullTranslationHelper)
+			_, handler := GetLatestRelease(stubGetClientFn(client), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 			request := createMCPRequest(tc.requestArgs)
 			result, err := handler(context.Background(), request)
 
@@ -2406,7 +3544,7 @@ func Test_GetLatestRelease(t *testing.T) {
 
 func Test_GetReleaseByTag(t *testing.T) {
 	mockClient := github.NewClient(nil)
-	tool, _ := GetReleaseByTag(stubGetClientFn(mockClient), translations.NullTranslationHelper)
+	tool, _ := GetReleaseByTag(stubGetClientFn(mockClient), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 	require.NoError(t, toolsnaps.Test(tool.Name, tool))
 
 	assert.Equal(t, "get_release_by_tag", tool.Name)
@@ -2526,7 +3664,7 @@ func Test_GetReleaseByTag(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			client := github.NewClient(tc.mockedClient)
-			_, handler := GetReleaseByTag(stubGetClientFn(client), translations.NullTranslationHelper)
+			_, handler := GetReleaseByTag(stubGetClientFn(client), repoAccessCache, translations.NullTranslationHelper, stubFeatureFlags(map[string]bool{"lockdown-mode": false}))
 
 			request := createMCPRequest(tc.requestArgs)
 
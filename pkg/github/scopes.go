@@ -0,0 +1,194 @@
+package github
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/scopes"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// ScopeCheckOff skips the startup scope check.
+	ScopeCheckOff = "off"
+	// ScopeCheckWarn logs the tools that cannot work with the token's scopes.
+	ScopeCheckWarn = "warn"
+	// ScopeCheckHide removes the tools that cannot work with the token's scopes.
+	ScopeCheckHide = "hide"
+	// ScopeCheckAnnotate adds the missing scopes to the descriptions of the tools that cannot work.
+	ScopeCheckAnnotate = "annotate"
+)
+
+var (
+	scopesRepoWrite      = []string{"repo", "public_repo"}
+	scopesNotifications  = []string{"notifications", "repo"}
+	scopesProjectRead    = []string{"read:project", "project"}
+	scopesProjectWrite   = []string{"project"}
+	scopesSecurityEvents = []string{"security_events", "public_repo"}
+	scopesSecretScanning = []string{"security_events", "repo"}
+	scopesRepoAdvisories = []string{"repo", "security_events"}
+	scopesGist           = []string{"gist"}
+	scopesOrgRead        = []string{"read:org"}
+	scopesWorkflow       = []string{"workflow"}
+)
+
+// ToolScopeRequirements maps tool names to the classic OAuth scopes they need. A tool works when the
+// token has any one of the listed scopes, directly or implied by a broader scope. Tools that are not
+// listed need no particular scope, e.g. because they also work on public data without one.
+var ToolScopeRequirements = map[string][]string{
+	// repos
+	"create_or_update_file": scopesRepoWrite,
+	"create_repository":     scopesRepoWrite,
+	"fork_repository":       scopesRepoWrite,
+	"create_branch":         scopesRepoWrite,
+	"push_files":            scopesRepoWrite,
+	"apply_patch":           scopesRepoWrite,
+	"delete_file":           scopesRepoWrite,
+	"create_release":        scopesRepoWrite,
+	"update_release":        scopesRepoWrite,
+	"delete_release":        scopesRepoWrite,
+	"upload_release_asset":  scopesRepoWrite,
+	// git
+	"create_git_blob":     scopesRepoWrite,
+	"create_git_tree":     scopesRepoWrite,
+	"create_git_commit":   scopesRepoWrite,
+	"update_git_ref":      scopesRepoWrite,
+	"delete_git_ref":      scopesRepoWrite,
+	"create_git_tag":      scopesRepoWrite,
+	"cherry_pick_commits": scopesRepoWrite,
+	"revert_commit":       scopesRepoWrite,
+	// issues
+	"issue_write":             scopesRepoWrite,
+	"add_issue_comment":       scopesRepoWrite,
+	"assign_copilot_to_issue": scopesRepoWrite,
+	"sub_issue_write":         scopesRepoWrite,
+	"label_write":             scopesRepoWrite,
+	// pull requests
+	"merge_pull_request":            scopesRepoWrite,
+	"update_pull_request_branch":    scopesRepoWrite,
+	"create_pull_request":           scopesRepoWrite,
+	"update_pull_request":           scopesRepoWrite,
+	"request_copilot_review":        scopesRepoWrite,
+	"pull_request_review_write":     scopesRepoWrite,
+	"add_comment_to_pending_review": scopesRepoWrite,
+	// actions
+	"run_workflow":             scopesWorkflow,
+	"rerun_workflow_run":       scopesWorkflow,
+	"rerun_failed_jobs":        scopesWorkflow,
+	"cancel_workflow_run":      scopesWorkflow,
+	"delete_workflow_run_logs": scopesWorkflow,
+	// security
+	"get_code_scanning_alert":                 scopesSecurityEvents,
+	"list_code_scanning_alerts":               scopesSecurityEvents,
+	"get_dependabot_alert":                    scopesSecurityEvents,
+	"list_dependabot_alerts":                  scopesSecurityEvents,
+	"get_secret_scanning_alert":               scopesSecretScanning,
+	"list_secret_scanning_alerts":             scopesSecretScanning,
+	"list_repository_security_advisories":     scopesRepoAdvisories,
+	"list_org_repository_security_advisories": scopesRepoAdvisories,
+	// notifications
+	"list_notifications":                          scopesNotifications,
+	"get_notification_details":                    scopesNotifications,
+	"dismiss_notification":                        scopesNotifications,
+	"mark_all_notifications_read":                 scopesNotifications,
+	"manage_notification_subscription":            scopesNotifications,
+	"manage_repository_notification_subscription": scopesNotifications,
+	// projects
+	"list_projects":       scopesProjectRead,
+	"get_project":         scopesProjectRead,
+	"list_project_fields": scopesProjectRead,
+	"get_project_field":   scopesProjectRead,
+	"list_project_items":  scopesProjectRead,
+	"get_project_item":    scopesProjectRead,
+	"add_project_item":    scopesProjectWrite,
+	"delete_project_item": scopesProjectWrite,
+	"update_project_item": scopesProjectWrite,
+	// gists
+	"create_gist": scopesGist,
+	"update_gist": scopesGist,
+	// context
+	"get_teams":        scopesOrgRead,
+	"get_team_members": scopesOrgRead,
+	// stargazers
+	"star_repository":   scopesRepoWrite,
+	"unstar_repository": scopesRepoWrite,
+}
+
+// IsValidScopeCheckMode reports whether mode is one of the supported scope check modes.
+func IsValidScopeCheckMode(mode string) bool {
+	switch mode {
+	case ScopeCheckOff, ScopeCheckWarn, ScopeCheckHide, ScopeCheckAnnotate:
+		return true
+	}
+	return false
+}
+
+// ToolScopeStatus describes whether the token's scopes satisfy a tool's requirement.
+type ToolScopeStatus struct {
+	Toolset   string   `json:"toolset"`
+	Tool      string   `json:"tool"`
+	Required  []string `json:"required,omitempty"`
+	Satisfied bool     `json:"satisfied"`
+}
+
+// CheckToolScopes compares the token's scopes to the requirement of every available tool,
+// ordered by toolset and tool name.
+func CheckToolScopes(tsg *toolsets.ToolsetGroup, tokenScopes scopes.TokenScopes) []ToolScopeStatus {
+	var result []ToolScopeStatus
+	for name, toolset := range tsg.Toolsets {
+		for _, tool := range toolset.GetAvailableTools() {
+			required := ToolScopeRequirements[tool.Tool.Name]
+			result = append(result, ToolScopeStatus{
+				Toolset:   name,
+				Tool:      tool.Tool.Name,
+				Required:  required,
+				Satisfied: tokenScopes.HasAny(required),
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Toolset != result[j].Toolset {
+			return result[i].Toolset < result[j].Toolset
+		}
+		return result[i].Tool < result[j].Tool
+	})
+	return result
+}
+
+// ApplyScopeCheck adjusts the tools whose scope requirement the token does not satisfy according to mode,
+// and returns their statuses so that the caller can report them.
+func ApplyScopeCheck(tsg *toolsets.ToolsetGroup, tokenScopes scopes.TokenScopes, mode string) ([]ToolScopeStatus, error) {
+	var unsatisfied []ToolScopeStatus
+	names := make(map[string]bool)
+	for _, status := range CheckToolScopes(tsg, tokenScopes) {
+		if !status.Satisfied {
+			unsatisfied = append(unsatisfied, status)
+			names[status.Tool] = true
+		}
+	}
+
+	switch mode {
+	case ScopeCheckOff, ScopeCheckWarn:
+	case ScopeCheckHide:
+		toHide := make([]string, 0, len(names))
+		for name := range names {
+			toHide = append(toHide, name)
+		}
+		if err := tsg.DisableTools(toHide); err != nil {
+			return nil, err
+		}
+	case ScopeCheckAnnotate:
+		tsg.MapTools(func(st server.ServerTool) server.ServerTool {
+			if names[st.Tool.Name] {
+				st.Tool.Description += fmt.Sprintf(" (Unavailable: the GitHub token is missing one of the required scopes: %s)",
+					strings.Join(ToolScopeRequirements[st.Tool.Name], ", "))
+			}
+			return st
+		})
+	default:
+		return nil, fmt.Errorf("unknown scope check mode %q, expected one of: %s, %s, %s, %s", mode, ScopeCheckOff, ScopeCheckWarn, ScopeCheckHide, ScopeCheckAnnotate)
+	}
+	return unsatisfied, nil
+}
@@ -0,0 +1,101 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/scopes"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolScopeRequirementsReferenceExistingTools(t *testing.T) {
+	tsg := DefaultToolsetGroup(false, stubGetClientFn(nil), stubGetGQLClientFn(nil), stubGetRawClientFn(nil), translations.NullTranslationHelper, 5000, FeatureFlags{}, nil)
+	known := make(map[string]bool)
+	for _, toolset := range tsg.Toolsets {
+		for _, tool := range toolset.GetAvailableTools() {
+			known[tool.Tool.Name] = true
+		}
+	}
+	for name := range ToolScopeRequirements {
+		assert.True(t, known[name], "scope requirement for unknown tool %s", name)
+	}
+}
+
+func TestApplyScopeCheck(t *testing.T) {
+	newGroup := func() *toolsets.ToolsetGroup {
+		tsg := DefaultToolsetGroup(false, stubGetClientFn(nil), stubGetGQLClientFn(nil), stubGetRawClientFn(nil), translations.NullTranslationHelper, 5000, FeatureFlags{}, nil)
+		require.NoError(t, tsg.EnableToolsets([]string{"notifications", "gists"}, nil))
+		return tsg
+	}
+	tokenScopes := scopes.NewTokenScopes([]string{"repo"})
+
+	t.Run("warn leaves tools untouched", func(t *testing.T) {
+		tsg := newGroup()
+		unsatisfied, err := ApplyScopeCheck(tsg, tokenScopes, ScopeCheckWarn)
+		require.NoError(t, err)
+		assert.Contains(t, toolNamesOf(unsatisfied), "create_gist")
+		assert.NotContains(t, toolNamesOf(unsatisfied), "list_notifications", "repo grants notifications access")
+		assert.Contains(t, activeToolNames(tsg, "gists"), "create_gist")
+	})
+
+	t.Run("hide disables tools", func(t *testing.T) {
+		tsg := newGroup()
+		_, err := ApplyScopeCheck(tsg, tokenScopes, ScopeCheckHide)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"list_gists", "get_gist"}, activeToolNames(tsg, "gists"))
+	})
+
+	t.Run("annotate adds the missing scope to descriptions", func(t *testing.T) {
+		tsg := newGroup()
+		_, err := ApplyScopeCheck(tsg, tokenScopes, ScopeCheckAnnotate)
+		require.NoError(t, err)
+		for _, tool := range tsg.Toolsets["gists"].GetActiveTools() {
+			if tool.Tool.Name == "create_gist" || tool.Tool.Name == "update_gist" {
+				assert.Contains(t, tool.Tool.Description, "missing one of the required scopes: gist")
+			} else {
+				assert.NotContains(t, tool.Tool.Description, "required scopes")
+			}
+		}
+	})
+
+	t.Run("actions write tools need the workflow scope", func(t *testing.T) {
+		tsg := DefaultToolsetGroup(false, stubGetClientFn(nil), stubGetGQLClientFn(nil), stubGetRawClientFn(nil), translations.NullTranslationHelper, 5000, FeatureFlags{}, nil)
+		require.NoError(t, tsg.EnableToolsets([]string{"actions"}, nil))
+		unsatisfied, err := ApplyScopeCheck(tsg, tokenScopes, ScopeCheckWarn)
+		require.NoError(t, err)
+		assert.Contains(t, toolNamesOf(unsatisfied), "run_workflow")
+
+		unsatisfied, err = ApplyScopeCheck(tsg, scopes.NewTokenScopes([]string{"repo", "workflow"}), ScopeCheckWarn)
+		require.NoError(t, err)
+		assert.NotContains(t, toolNamesOf(unsatisfied), "run_workflow")
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		_, err := ApplyScopeCheck(newGroup(), tokenScopes, "explode")
+		require.Error(t, err)
+	})
+
+	t.Run("unknown scopes are always satisfied", func(t *testing.T) {
+		unsatisfied, err := ApplyScopeCheck(newGroup(), scopes.TokenScopes{}, ScopeCheckHide)
+		require.NoError(t, err)
+		assert.Empty(t, unsatisfied)
+	})
+}
+
+func toolNamesOf(statuses []ToolScopeStatus) []string {
+	names := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		names = append(names, status.Tool)
+	}
+	return names
+}
+
+func activeToolNames(tsg *toolsets.ToolsetGroup, toolset string) []string {
+	var names []string
+	for _, tool := range tsg.Toolsets[toolset].GetActiveTools() {
+		names = append(names, tool.Tool.Name)
+	}
+	return names
+}
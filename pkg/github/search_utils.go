@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"regexp"
 
+	"github.com/github/github-mcp-server/pkg/lockdown"
 	"github.com/google/go-github/v79/github"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -38,6 +39,8 @@ func hasTypeFilter(query string) bool {
 func searchHandler(
 	ctx context.Context,
 	getClient GetClientFn,
+	cache *lockdown.RepoAccessCache,
+	flags FeatureFlags,
 	request mcp.CallToolRequest,
 	searchType string,
 	errorPrefix string,
@@ -106,7 +109,24 @@ func searchHandler(
 		return mcp.NewToolResultError(fmt.Sprintf("%s: %s", errorPrefix, string(body))), nil
 	}
 
-	r, err := json.Marshal(result)
+	var output any = result
+	if flags.LockdownMode {
+		// Results can come from any repository, so the origin of each one is taken from its repository URL
+		items, err := applyLockdown(ctx, cache, flags, result.Issues, func(issue *github.Issue) contentOrigin {
+			owner, repo := repoFromAPIURL(issue.GetRepositoryURL())
+			return contentOrigin{Login: issue.GetUser().GetLogin(), Owner: owner, Repo: repo}
+		}, redactIssue)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s: failed to check lockdown mode: %v", errorPrefix, err)), nil
+		}
+		output = map[string]any{
+			"total_count":        result.GetTotal(),
+			"incomplete_results": result.GetIncompleteResults(),
+			"items":              items,
+		}
+	}
+
+	r, err := json.Marshal(output)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to marshal response: %w", errorPrefix, err)
 	}
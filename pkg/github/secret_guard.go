@@ -0,0 +1,231 @@
+package github
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/secrets"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// secretScannedArguments are the arguments of write tools whose text is published to GitHub. Tools that take a
+// list of files or edits have their path, content and replace text scanned under files[i] or edits[i]. Content
+// with a base64 encoding is binary and is not scanned, and of a patch only the added lines are scanned. The
+// content of lockfiles is only scanned by the pattern rules, as it is full of checksums.
+var secretScannedArguments = map[string][]string{
+	"create_or_update_file": {"content", "message"},
+	"push_files":            {"files", "message"},
+	"apply_patch":           {"patch", "edits", "message"},
+	"create_gist":           {"content", "description"},
+	"update_gist":           {"content", "description"},
+	"add_issue_comment":     {"body"},
+	"create_pull_request":   {"title", "body"},
+	"create_release":        {"name", "body"},
+	"update_release":        {"name", "body"},
+	"upload_release_asset":  {"content"},
+	"create_git_blob":       {"content"},
+	"create_git_tree":       {"tree"},
+	"create_git_commit":     {"message"},
+	"create_git_tag":        {"message"},
+	"revert_commit":         {"message"},
+}
+
+// SecretFinding is a secret found in an argument of a tool call.
+type SecretFinding struct {
+	secrets.Finding
+	// Argument is the name of the argument, such as content or files[2].content.
+	Argument string `json:"argument"`
+}
+
+// SecretGuard scans the arguments of write tools for secrets before the tools run, and blocks or warns about
+// calls that would publish them.
+type SecretGuard struct {
+	scanner   *secrets.Scanner
+	getClient GetClientFn
+}
+
+// NewSecretGuard creates a SecretGuard. getClient is used to look up the visibility of the target repository or
+// gist when the scanner only blocks writes to public targets.
+func NewSecretGuard(scanner *secrets.Scanner, getClient GetClientFn) *SecretGuard {
+	return &SecretGuard{scanner: scanner, getClient: getClient}
+}
+
+// ToolHandlerMiddleware scans the arguments of write tools. Other tools are passed through unchanged.
+func (g *SecretGuard) ToolHandlerMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		findings := g.Scan(request.Params.Name, request.GetArguments())
+		if len(findings) == 0 || g.scanner.Action() == secrets.ActionOff {
+			return next(ctx, request)
+		}
+
+		if g.scanner.Action() == secrets.ActionBlock && slices.ContainsFunc(findings, func(finding SecretFinding) bool {
+			return g.scanner.Blocks(finding.Finding)
+		}) {
+			public, known := true, true
+			if g.scanner.PublicOnly() {
+				public, known = g.targetIsPublic(ctx, request)
+				if !known {
+					public = g.scanner.UnknownVisibility() == secrets.VisibilityPublic
+				}
+			}
+			if public {
+				note := ""
+				if !known {
+					note = "\nThe visibility of the target could not be determined, so it was treated as public."
+				}
+				return mcp.NewToolResultError(fmt.Sprintf("%s was blocked because its arguments look like they contain secrets:\n%s%s\nRemove the secrets and try again.",
+					request.Params.Name, formatSecretFindings(findings), note)), nil
+			}
+		}
+
+		result, err := next(ctx, request)
+		if err != nil || result == nil {
+			return result, err
+		}
+		outcome := "which have now been written to GitHub:\n%s\nRevoke any real credentials among them."
+		switch {
+		case result.IsError:
+			outcome = "but the call failed:\n%s\nRemove them before trying again."
+		case !resultWrites(result):
+			outcome = "which have not been written yet:\n%s\nRemove them before the call runs for real."
+		}
+		result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf("Warning: the arguments of %s look like they contain secrets, "+outcome,
+			request.Params.Name, formatSecretFindings(findings))))
+		return result, nil
+	}
+}
+
+// resultWrites reports whether a successful result is that of a call that wrote to GitHub, rather than a dry-run
+// plan or a request for confirmation.
+func resultWrites(result *mcp.CallToolResult) bool {
+	if len(result.Content) == 0 {
+		return true
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		return true
+	}
+	var pending struct {
+		DryRun bool   `json:"dry_run"`
+		Status string `json:"status"`
+	}
+	if json.Unmarshal([]byte(text.Text), &pending) != nil {
+		return true
+	}
+	return !pending.DryRun && pending.Status != "confirmation_required"
+}
+
+// Scan returns the secrets found in the arguments of a call to the named tool.
+func (g *SecretGuard) Scan(toolName string, args map[string]any) []SecretFinding {
+	var findings []SecretFinding
+	add := func(argument string, found []secrets.Finding) {
+		for _, finding := range found {
+			findings = append(findings, SecretFinding{Finding: finding, Argument: argument})
+		}
+	}
+	scan := func(argument, path string, value any) {
+		if text, ok := value.(string); ok {
+			add(argument, g.scanner.ScanFile(path, text))
+		}
+	}
+
+	for _, name := range secretScannedArguments[toolName] {
+		if files, ok := args[name].([]any); ok {
+			for i, file := range files {
+				if file, ok := file.(map[string]any); ok {
+					path, _ := file["path"].(string)
+					scan(fmt.Sprintf("%s[%d].path", name, i), "", file["path"])
+					if file["encoding"] != "base64" {
+						scan(fmt.Sprintf("%s[%d].content", name, i), path, file["content"])
+					}
+					scan(fmt.Sprintf("%s[%d].replace", name, i), path, file["replace"])
+				}
+			}
+			continue
+		}
+		if patch, ok := args[name].(string); ok && name == "patch" {
+			found := append(g.scanner.Scan(addedPatchLines(patch, false)), g.scanner.ScanPatterns(addedPatchLines(patch, true))...)
+			slices.SortStableFunc(found, func(a, b secrets.Finding) int {
+				return cmp.Compare(a.Line, b.Line)
+			})
+			add(name, found)
+			continue
+		}
+		if name == "content" && args["encoding"] == "base64" {
+			continue
+		}
+		path, _ := args["path"].(string)
+		scan(name, path, args[name])
+	}
+	return findings
+}
+
+// addedPatchLines returns the lines a unified diff adds to lockfiles, or to other files, without their + prefix,
+// and blank lines in place of the others so that findings keep the line numbers of the patch. Removing a secret
+// does not publish it.
+func addedPatchLines(patch string, lockfiles bool) string {
+	lines := strings.Split(patch, "\n")
+	inLockfile := false
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			path, _, _ := strings.Cut(strings.TrimPrefix(line, "+++ "), "\t")
+			inLockfile = secrets.IsLockfile(strings.TrimPrefix(path, "b/"))
+			lines[i] = ""
+		case strings.HasPrefix(line, "+") && inLockfile == lockfiles:
+			lines[i] = line[1:]
+		default:
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// targetIsPublic reports whether the repository or gist a tool writes to is public, and whether its visibility
+// could be determined at all. The caller decides how to treat unknown targets.
+func (g *SecretGuard) targetIsPublic(ctx context.Context, request mcp.CallToolRequest) (public, known bool) {
+	args := request.GetArguments()
+	switch request.Params.Name {
+	case "create_gist":
+		public, _ := args["public"].(bool)
+		return public, true
+	case "update_gist":
+		gistID, _ := args["gist_id"].(string)
+		client, err := g.getClient(ctx)
+		if err != nil {
+			return false, false
+		}
+		gist, resp, err := client.Gists.Get(ctx, gistID)
+		if err != nil {
+			return false, false
+		}
+		defer func() { _ = resp.Body.Close() }()
+		return gist.GetPublic(), true
+	default:
+		owner, _ := args["owner"].(string)
+		repo, _ := args["repo"].(string)
+		client, err := g.getClient(ctx)
+		if err != nil {
+			return false, false
+		}
+		repository, resp, err := client.Repositories.Get(ctx, owner, repo)
+		if err != nil {
+			return false, false
+		}
+		defer func() { _ = resp.Body.Close() }()
+		return !repository.GetPrivate() && repository.GetVisibility() != "internal", true
+	}
+}
+
+func formatSecretFindings(findings []SecretFinding) string {
+	lines := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		lines = append(lines, fmt.Sprintf("- %s, line %d: %s (%s)", finding.Argument, finding.Line, finding.Rule, finding.Redacted))
+	}
+	return strings.Join(lines, "\n")
+}
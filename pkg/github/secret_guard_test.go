@@ -0,0 +1,250 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/secrets"
+	"github.com/google/go-github/v79/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGitHubToken is assembled at run time, so that this file does not trip secret scanning itself.
+var testGitHubToken = "ghp_" + strings.Repeat("aB3d", 9)
+
+// testRandomString is only reported by the entropy check.
+const testRandomString = "q8ZtV2xKp4LmN7rWc1YbH6sJd9FgA3eU"
+
+func Test_SecretGuard_Scan(t *testing.T) {
+	scanner, err := secrets.NewScanner(secrets.Config{})
+	require.NoError(t, err)
+	guard := NewSecretGuard(scanner, nil)
+
+	findings := guard.Scan("push_files", map[string]any{
+		"owner":   "owner",
+		"repo":    "repo",
+		"message": "Add config",
+		"files": []any{
+			map[string]any{"path": "README.md", "content": "# Hello"},
+			map[string]any{"path": ".env", "content": "DEBUG=1\nGITHUB_TOKEN=" + testGitHubToken},
+		},
+	})
+	require.Len(t, findings, 1)
+	assert.Equal(t, "files[1].content", findings[0].Argument)
+	assert.Equal(t, 2, findings[0].Line)
+	assert.Equal(t, "github-token", findings[0].Rule)
+
+	// Binary content is not scanned
+	assert.Empty(t, guard.Scan("push_files", map[string]any{
+		"files": []any{
+			map[string]any{"path": "logo.png", "content": testGitHubToken, "encoding": "base64"},
+		},
+	}))
+	assert.Empty(t, guard.Scan("upload_release_asset", map[string]any{"content": testGitHubToken, "encoding": "base64"}))
+	assert.Len(t, guard.Scan("upload_release_asset", map[string]any{"content": testGitHubToken}), 1)
+
+	// Only the lines a patch adds are scanned, as removing a secret does not publish it
+	findings = guard.Scan("apply_patch", map[string]any{
+		"patch": "--- a/.env\n+++ b/.env\n@@ -1 +1 @@\n-GITHUB_TOKEN=" + testGitHubToken + "\n+GITHUB_TOKEN=" + testGitHubToken + "\n",
+	})
+	require.Len(t, findings, 1)
+	assert.Equal(t, "patch", findings[0].Argument)
+	assert.Equal(t, 5, findings[0].Line)
+	assert.Empty(t, guard.Scan("apply_patch", map[string]any{
+		"patch": "--- a/.env\n+++ b/.env\n@@ -1 +0,0 @@\n-GITHUB_TOKEN=" + testGitHubToken + "\n",
+	}))
+
+	// Lockfiles are full of checksums, so their content is only scanned by the pattern rules
+	assert.Empty(t, guard.Scan("push_files", map[string]any{
+		"files": []any{
+			map[string]any{"path": "yarn.lock", "content": "resolved " + testRandomString},
+		},
+	}))
+	findings = guard.Scan("apply_patch", map[string]any{
+		"patch": "--- a/yarn.lock\n+++ b/yarn.lock\n@@ -0,0 +1 @@\n+resolved " + testRandomString + "\n" +
+			"--- a/app.js\n+++ b/app.js\n@@ -0,0 +1 @@\n+const key = \"" + testRandomString + "\"\n",
+	})
+	require.Len(t, findings, 1)
+	assert.Equal(t, 8, findings[0].Line)
+	findings = guard.Scan("apply_patch", map[string]any{
+		"edits": []any{
+			map[string]any{"path": ".env", "search": "GITHUB_TOKEN=", "replace": "GITHUB_TOKEN=" + testGitHubToken},
+		},
+	})
+	require.Len(t, findings, 1)
+	assert.Equal(t, "edits[0].replace", findings[0].Argument)
+
+	// Arguments of other tools are not scanned
+	assert.Empty(t, guard.Scan("get_file_contents", map[string]any{"path": testGitHubToken}))
+}
+
+func Test_SecretGuard_ToolHandlerMiddleware(t *testing.T) {
+	publicRepo := mock.WithRequestMatch(mock.GetReposByOwnerByRepo, &github.Repository{Private: github.Ptr(false)})
+	privateRepo := mock.WithRequestMatch(mock.GetReposByOwnerByRepo, &github.Repository{Private: github.Ptr(true)})
+
+	tests := []struct {
+		name           string
+		cfg            secrets.Config
+		mockedClient   *github.Client
+		toolName       string
+		args           map[string]any
+		result         *mcp.CallToolResult
+		expectBlocked  bool
+		expectWarning  bool
+		expectedInText string
+	}{
+		{
+			name:     "clean arguments pass through",
+			toolName: "add_issue_comment",
+			args:     map[string]any{"owner": "owner", "repo": "repo", "body": "Looks good"},
+		},
+		{
+			name:           "secret is warned about by default",
+			toolName:       "add_issue_comment",
+			args:           map[string]any{"owner": "owner", "repo": "repo", "body": "token " + testGitHubToken},
+			expectWarning:  true,
+			expectedInText: "which have now been written to GitHub:\n- body, line 1: github-token (ghp_… (40 characters))",
+		},
+		{
+			name:           "failed call is not reported as written",
+			toolName:       "add_issue_comment",
+			args:           map[string]any{"owner": "owner", "repo": "repo", "body": "token " + testGitHubToken},
+			result:         mcp.NewToolResultError("failed to create comment"),
+			expectWarning:  true,
+			expectedInText: "but the call failed",
+		},
+		{
+			name:           "dry run is not reported as written",
+			toolName:       "add_issue_comment",
+			args:           map[string]any{"owner": "owner", "repo": "repo", "body": "token " + testGitHubToken},
+			result:         MarshalledTextResult(DryRunPlan{DryRun: true, Tool: "add_issue_comment"}),
+			expectWarning:  true,
+			expectedInText: "which have not been written yet",
+		},
+		{
+			name:           "pending confirmation is not reported as written",
+			toolName:       "add_issue_comment",
+			args:           map[string]any{"owner": "owner", "repo": "repo", "body": "token " + testGitHubToken},
+			result:         MarshalledTextResult(ConfirmationRequired{Status: "confirmation_required", Tool: "add_issue_comment"}),
+			expectWarning:  true,
+			expectedInText: "which have not been written yet",
+		},
+		{
+			name:           "secret is blocked",
+			cfg:            secrets.Config{Action: secrets.ActionBlock},
+			toolName:       "add_issue_comment",
+			args:           map[string]any{"owner": "owner", "repo": "repo", "body": "token " + testGitHubToken},
+			expectBlocked:  true,
+			expectedInText: "- body, line 1: github-token (ghp_… (40 characters))",
+		},
+		{
+			name:           "secret is warned about",
+			cfg:            secrets.Config{Action: secrets.ActionWarn},
+			toolName:       "create_pull_request",
+			args:           map[string]any{"owner": "owner", "repo": "repo", "title": "Fix", "body": "a\n" + testGitHubToken},
+			expectWarning:  true,
+			expectedInText: "- body, line 2: github-token",
+		},
+		{
+			name:           "high entropy string is warned about when blocking",
+			cfg:            secrets.Config{Action: secrets.ActionBlock},
+			toolName:       "add_issue_comment",
+			args:           map[string]any{"owner": "owner", "repo": "repo", "body": "key " + testRandomString},
+			expectWarning:  true,
+			expectedInText: "- body, line 1: high-entropy-string",
+		},
+		{
+			name:          "public only blocks public repository",
+			cfg:           secrets.Config{Action: secrets.ActionBlock, PublicOnly: true},
+			mockedClient:  github.NewClient(mock.NewMockedHTTPClient(publicRepo)),
+			toolName:      "create_or_update_file",
+			args:          map[string]any{"owner": "owner", "repo": "repo", "path": "a", "content": testGitHubToken},
+			expectBlocked: true,
+		},
+		{
+			name:          "public only warns on private repository",
+			cfg:           secrets.Config{Action: secrets.ActionBlock, PublicOnly: true},
+			mockedClient:  github.NewClient(mock.NewMockedHTTPClient(privateRepo)),
+			toolName:      "create_or_update_file",
+			args:          map[string]any{"owner": "owner", "repo": "repo", "path": "a", "content": testGitHubToken},
+			expectWarning: true,
+		},
+		{
+			name:           "public only blocks when visibility is unknown",
+			cfg:            secrets.Config{Action: secrets.ActionBlock, PublicOnly: true},
+			mockedClient:   github.NewClient(mock.NewMockedHTTPClient()),
+			toolName:       "create_or_update_file",
+			args:           map[string]any{"owner": "owner", "repo": "repo", "path": "a", "content": testGitHubToken},
+			expectBlocked:  true,
+			expectedInText: "could not be determined, so it was treated as public",
+		},
+		{
+			name:          "public only warns when visibility is unknown and treated as private",
+			cfg:           secrets.Config{Action: secrets.ActionBlock, PublicOnly: true, UnknownVisibility: secrets.VisibilityPrivate},
+			mockedClient:  github.NewClient(mock.NewMockedHTTPClient()),
+			toolName:      "create_or_update_file",
+			args:          map[string]any{"owner": "owner", "repo": "repo", "path": "a", "content": testGitHubToken},
+			expectWarning: true,
+		},
+		{
+			name:          "public only warns on secret gist",
+			cfg:           secrets.Config{Action: secrets.ActionBlock, PublicOnly: true},
+			toolName:      "create_gist",
+			args:          map[string]any{"filename": "a.txt", "content": testGitHubToken, "public": false},
+			expectWarning: true,
+		},
+		{
+			name:          "public only blocks public gist",
+			cfg:           secrets.Config{Action: secrets.ActionBlock, PublicOnly: true},
+			toolName:      "create_gist",
+			args:          map[string]any{"filename": "a.txt", "content": testGitHubToken, "public": true},
+			expectBlocked: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scanner, err := secrets.NewScanner(tc.cfg)
+			require.NoError(t, err)
+			guard := NewSecretGuard(scanner, stubGetClientFn(tc.mockedClient))
+
+			called := false
+			handler := guard.ToolHandlerMiddleware(func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				called = true
+				if tc.result != nil {
+					return tc.result, nil
+				}
+				return mcp.NewToolResultText("ok"), nil
+			})
+			request := createMCPRequest(tc.args)
+			request.Params.Name = tc.toolName
+
+			result, err := handler(context.Background(), request)
+			require.NoError(t, err)
+
+			if tc.expectBlocked {
+				assert.False(t, called)
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.toolName+" was blocked")
+				assert.Contains(t, errorContent.Text, tc.expectedInText)
+				return
+			}
+
+			assert.True(t, called)
+			require.Equal(t, tc.result != nil && tc.result.IsError, result.IsError)
+			if !tc.expectWarning {
+				require.Len(t, result.Content, 1)
+				return
+			}
+			require.Len(t, result.Content, 2)
+			warning, ok := result.Content[1].(mcp.TextContent)
+			require.True(t, ok)
+			assert.Contains(t, warning.Text, "Warning: the arguments of "+tc.toolName)
+			assert.Contains(t, warning.Text, tc.expectedInText)
+		})
+	}
+}
@@ -47,7 +47,8 @@ func stubRepoAccessCache(client *githubv4.Client, ttl time.Duration) *lockdown.R
 
 func stubFeatureFlags(enabledFlags map[string]bool) FeatureFlags {
 	return FeatureFlags{
-		LockdownMode: enabledFlags["lockdown-mode"],
+		LockdownMode:   enabledFlags["lockdown-mode"],
+		LockdownRedact: enabledFlags["lockdown-redact"],
 	}
 }
 
@@ -112,6 +112,10 @@ var (
 		ID:          "labels",
 		Description: "GitHub Labels related tools",
 	}
+	ToolsetMetadataCustom = ToolsetMetadata{
+		ID:          "custom",
+		Description: "Tools declared in the server configuration as GraphQL or REST templates",
+	}
 )
 
 func AvailableTools() []ToolsetMetadata {
@@ -135,6 +139,7 @@ func AvailableTools() []ToolsetMetadata {
 		ToolsetMetadataStargazers,
 		ToolsetMetadataDynamic,
 		ToolsetLabels,
+		ToolsetMetadataCustom,
 	}
 }
 
@@ -169,15 +174,19 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 		AddReadTools(
 			toolsets.NewServerTool(SearchRepositories(getClient, t)),
 			toolsets.NewServerTool(GetFileContents(getClient, getRawClient, t)),
-			toolsets.NewServerTool(ListCommits(getClient, t)),
+			toolsets.NewServerTool(GetFiles(getClient, getRawClient, t)),
+			toolsets.NewServerTool(ListCommits(getClient, cache, t, flags)),
+			toolsets.NewServerTool(CompareRefs(getClient, cache, t, flags)),
 			toolsets.NewServerTool(SearchCode(getClient, t)),
-			toolsets.NewServerTool(GetCommit(getClient, t)),
+			toolsets.NewServerTool(GetCommit(getClient, cache, t, flags)),
 			toolsets.NewServerTool(ListBranches(getClient, t)),
 			toolsets.NewServerTool(ListTags(getClient, t)),
 			toolsets.NewServerTool(GetTag(getClient, t)),
-			toolsets.NewServerTool(ListReleases(getClient, t)),
-			toolsets.NewServerTool(GetLatestRelease(getClient, t)),
-			toolsets.NewServerTool(GetReleaseByTag(getClient, t)),
+			toolsets.NewServerTool(ListReleases(getClient, cache, t, flags)),
+			toolsets.NewServerTool(GetLatestRelease(getClient, cache, t, flags)),
+			toolsets.NewServerTool(GetReleaseByTag(getClient, cache, t, flags)),
+			toolsets.NewServerTool(GenerateReleaseNotes(getClient, t)),
+			toolsets.NewServerTool(ReleaseAssetRead(getClient, t)),
 		).
 		AddWriteTools(
 			toolsets.NewServerTool(CreateOrUpdateFile(getClient, t)),
@@ -185,7 +194,12 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 			toolsets.NewServerTool(ForkRepository(getClient, t)),
 			toolsets.NewServerTool(CreateBranch(getClient, t)),
 			toolsets.NewServerTool(PushFiles(getClient, t)),
+			toolsets.NewServerTool(ApplyPatch(getClient, t)),
 			toolsets.NewServerTool(DeleteFile(getClient, t)),
+			toolsets.NewServerTool(CreateRelease(getClient, t)),
+			toolsets.NewServerTool(UpdateRelease(getClient, t)),
+			toolsets.NewServerTool(DeleteRelease(getClient, t)),
+			toolsets.NewServerTool(UploadReleaseAsset(getClient, t)),
 		).
 		AddResourceTemplates(
 			toolsets.NewServerResourceTemplate(GetRepositoryResourceContent(getClient, getRawClient, t)),
@@ -197,12 +211,29 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 	git := toolsets.NewToolset(ToolsetMetadataGit.ID, ToolsetMetadataGit.Description).
 		AddReadTools(
 			toolsets.NewServerTool(GetRepositoryTree(getClient, t)),
+			toolsets.NewServerTool(GetFileBlame(getGQLClient, cache, t, flags)),
+			toolsets.NewServerTool(GetFileHistory(getClient, cache, t, flags)),
+			toolsets.NewServerTool(GetGitBlob(getClient, t)),
+			toolsets.NewServerTool(GetGitCommit(getClient, t)),
+			toolsets.NewServerTool(GetGitRef(getClient, t)),
+			toolsets.NewServerTool(ListGitRefs(getClient, t)),
+			toolsets.NewServerTool(GetGitTag(getClient, t)),
+		).
+		AddWriteTools(
+			toolsets.NewServerTool(CreateGitBlob(getClient, t)),
+			toolsets.NewServerTool(CreateGitTree(getClient, t)),
+			toolsets.NewServerTool(CreateGitCommit(getClient, t)),
+			toolsets.NewServerTool(UpdateGitRef(getClient, t)),
+			toolsets.NewServerTool(DeleteGitRef(getClient, t)),
+			toolsets.NewServerTool(CreateGitTag(getClient, t)),
+			toolsets.NewServerTool(CherryPickCommits(getClient, t)),
+			toolsets.NewServerTool(RevertCommit(getClient, t)),
 		)
 	issues := toolsets.NewToolset(ToolsetMetadataIssues.ID, ToolsetMetadataIssues.Description).
 		AddReadTools(
 			toolsets.NewServerTool(IssueRead(getClient, getGQLClient, cache, t, flags)),
-			toolsets.NewServerTool(SearchIssues(getClient, t)),
-			toolsets.NewServerTool(ListIssues(getGQLClient, t)),
+			toolsets.NewServerTool(SearchIssues(getClient, cache, t, flags)),
+			toolsets.NewServerTool(ListIssues(getGQLClient, cache, t, flags)),
 			toolsets.NewServerTool(ListIssueTypes(getClient, t)),
 			toolsets.NewServerTool(GetLabel(getGQLClient, t)),
 		).
@@ -211,7 +242,7 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 			toolsets.NewServerTool(AddIssueComment(getClient, t)),
 			toolsets.NewServerTool(AssignCopilotToIssue(getGQLClient, t)),
 			toolsets.NewServerTool(SubIssueWrite(getClient, t)),
-		).AddPrompts(
+		).AddWritePrompts(
 		toolsets.NewServerPrompt(AssignCodingAgentPrompt(t)),
 		toolsets.NewServerPrompt(IssueToFixWorkflowPrompt(t)),
 	)
@@ -227,7 +258,7 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 		AddReadTools(
 			toolsets.NewServerTool(PullRequestRead(getClient, cache, t, flags)),
 			toolsets.NewServerTool(ListPullRequests(getClient, t)),
-			toolsets.NewServerTool(SearchPullRequests(getClient, t)),
+			toolsets.NewServerTool(SearchPullRequests(getClient, cache, t, flags)),
 		).
 		AddWriteTools(
 			toolsets.NewServerTool(MergePullRequest(getClient, t)),
@@ -259,7 +290,7 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 	notifications := toolsets.NewToolset(ToolsetMetadataNotifications.ID, ToolsetMetadataNotifications.Description).
 		AddReadTools(
 			toolsets.NewServerTool(ListNotifications(getClient, t)),
-			toolsets.NewServerTool(GetNotificationDetails(getClient, t)),
+			toolsets.NewServerTool(GetNotificationDetails(getClient, cache, t, flags)),
 		).
 		AddWriteTools(
 			toolsets.NewServerTool(DismissNotification(getClient, t)),
@@ -270,9 +301,9 @@ func DefaultToolsetGroup(readOnly bool, getClient GetClientFn, getGQLClient GetG
 
 	discussions := toolsets.NewToolset(ToolsetMetadataDiscussions.ID, ToolsetMetadataDiscussions.Description).
 		AddReadTools(
-			toolsets.NewServerTool(ListDiscussions(getGQLClient, t)),
-			toolsets.NewServerTool(GetDiscussion(getGQLClient, t)),
-			toolsets.NewServerTool(GetDiscussionComments(getGQLClient, t)),
+			toolsets.NewServerTool(ListDiscussions(getGQLClient, cache, t, flags)),
+			toolsets.NewServerTool(GetDiscussion(getGQLClient, cache, t, flags)),
+			toolsets.NewServerTool(GetDiscussionComments(getGQLClient, cache, t, flags)),
 			toolsets.NewServerTool(ListDiscussionCategories(getGQLClient, t)),
 		)
 
@@ -390,6 +421,7 @@ func InitDynamicToolset(s *server.MCPServer, tsg *toolsets.ToolsetGroup, t trans
 			toolsets.NewServerTool(ListAvailableToolsets(tsg, t)),
 			toolsets.NewServerTool(GetToolsetsTools(tsg, t)),
 			toolsets.NewServerTool(EnableToolset(s, tsg, t)),
+			toolsets.NewServerTool(DisableToolset(s, tsg, t)),
 		)
 
 	dynamicToolSelection.Enabled = true
@@ -438,15 +470,23 @@ func GenerateToolsetsHelp() string {
 
 	availableTools := strings.Join(availableToolsLines, ",\n\t     ")
 
+	var profilesHelp string
+	for _, profile := range AvailableProfiles() {
+		profilesHelp += fmt.Sprintf("  - %s: %s\n\t     toolsets: %s\n", profile.ID, profile.Description, strings.Join(profile.Toolsets, ", "))
+	}
+
 	toolsetsHelp := fmt.Sprintf("Comma-separated list of tool groups to enable (no spaces).\n"+
 		"Available: %s\n", availableTools) +
 		"Special toolset keywords:\n" +
 		"  - all: Enables all available toolsets\n" +
 		fmt.Sprintf("  - default: Enables the default toolset configuration of:\n\t     %s\n", defaultTools) +
+		"Profiles for common agent roles (select with --profile):\n" +
+		profilesHelp +
 		"Examples:\n" +
 		"  - --toolsets=actions,gists,notifications\n" +
 		"  - Default + additional: --toolsets=default,actions,gists\n" +
-		"  - All tools: --toolsets=all"
+		"  - All tools: --toolsets=all\n" +
+		"  - Profile + additional: --profile=reviewer --toolsets=issues"
 
 	return toolsetsHelp
 }
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -14,25 +17,44 @@ import (
 
 // RepoAccessCache caches repository metadata related to lockdown checks so that
 // multiple tools can reuse the same access information safely across goroutines.
+// mu guards the trust policy, while each repository's entry is guarded by its own lock.
 type RepoAccessCache struct {
-	client *githubv4.Client
-	mu     sync.Mutex
-	cache  *cache2go.CacheTable
-	ttl    time.Duration
-	logger *slog.Logger
+	client    *githubv4.Client
+	mu        sync.Mutex
+	cache     *cache2go.CacheTable
+	ttl       time.Duration
+	logger    *slog.Logger
+	policy    TrustPolicy // normalized
+	policyKey string
+	locksMu   sync.Mutex
+	repoLocks map[string]*repoLock // cache key -> lock, while any lookup holds or waits for it
+}
+
+// repoLock guards a cache entry. It is removed from RepoAccessCache.repoLocks once no lookup refers to it, so
+// that the locks of repositories no longer looked up do not accumulate.
+type repoLock struct {
+	mu   sync.Mutex
+	refs int
 }
 
 type repoAccessCacheEntry struct {
 	isPrivate   bool
-	knownUsers  map[string]bool // normalized login -> has push access
+	knownUsers  map[string]userAccess // normalized login -> access of the user
 	viewerLogin string
 }
 
+type userAccess struct {
+	hasPush bool
+	trusted bool
+}
+
 // RepoAccessInfo captures repository metadata needed for lockdown decisions.
 type RepoAccessInfo struct {
 	IsPrivate     bool
 	HasPushAccess bool
-	ViewerLogin   string
+	// IsTrusted is true when the user is trusted by the trust policy of the cache.
+	IsTrusted   bool
+	ViewerLogin string
 }
 
 const (
@@ -63,6 +85,14 @@ func WithLogger(logger *slog.Logger) RepoAccessOption {
 	}
 }
 
+// WithTrustPolicy sets the policy that decides which authors are trusted.
+func WithTrustPolicy(policy TrustPolicy) RepoAccessOption {
+	return func(c *RepoAccessCache) {
+		c.policy = policy.normalized()
+		c.policyKey = policy.Key()
+	}
+}
+
 // WithCacheName overrides the cache table name used for storing entries. This option is intended for tests
 // that need isolated cache instances.
 func WithCacheName(name string) RepoAccessOption {
@@ -81,20 +111,28 @@ func GetInstance(client *githubv4.Client, opts ...RepoAccessOption) *RepoAccessC
 	instanceMu.Lock()
 	defer instanceMu.Unlock()
 	if instance == nil {
-		instance = &RepoAccessCache{
-			client: client,
-			cache:  cache2go.Cache(defaultRepoAccessCacheKey),
-			ttl:    defaultRepoAccessTTL,
-		}
-		for _, opt := range opts {
-			if opt != nil {
-				opt(instance)
-			}
-		}
+		instance = newRepoAccessCache(client, opts...)
 	}
 	return instance
 }
 
+func newRepoAccessCache(client *githubv4.Client, opts ...RepoAccessOption) *RepoAccessCache {
+	c := &RepoAccessCache{
+		client:    client,
+		cache:     cache2go.Cache(defaultRepoAccessCacheKey),
+		ttl:       defaultRepoAccessTTL,
+		policy:    TrustPolicy{}.normalized(),
+		policyKey: TrustPolicy{}.Key(),
+		repoLocks: make(map[string]*repoLock),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	return c
+}
+
 // SetLogger updates the logger used for cache diagnostics.
 func (c *RepoAccessCache) SetLogger(logger *slog.Logger) {
 	c.mu.Lock()
@@ -102,6 +140,23 @@ func (c *RepoAccessCache) SetLogger(logger *slog.Logger) {
 	c.mu.Unlock()
 }
 
+// SetPolicy replaces the trust policy. Cache entries are keyed by policy, so decisions made under the previous
+// policy are not reused.
+func (c *RepoAccessCache) SetPolicy(policy TrustPolicy) {
+	c.mu.Lock()
+	c.policy = policy.normalized()
+	c.policyKey = policy.Key()
+	c.mu.Unlock()
+	c.logDebug("repo access trust policy updated", "policy", policy.Key())
+}
+
+// Policy returns the trust policy in effect, with defaults applied.
+func (c *RepoAccessCache) Policy() TrustPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policy
+}
+
 // CacheStats summarizes cache activity counters.
 type CacheStats struct {
 	Hits      int64
@@ -109,129 +164,363 @@ type CacheStats struct {
 	Evictions int64
 }
 
+// TrustLevel describes how much the author of a piece of content is trusted.
+type TrustLevel string
+
+const (
+	// TrustLevelViewer is the user the server is authenticated as.
+	TrustLevelViewer TrustLevel = "viewer"
+	// TrustLevelCollaborator is a user trusted by the trust policy, by default one with push access to the repository.
+	TrustLevelCollaborator TrustLevel = "collaborator"
+	// TrustLevelExternal is any other user, whose content may contain untrusted input.
+	TrustLevelExternal TrustLevel = "external"
+)
+
+// ContentTrust describes the trust in the author of a piece of content within a repository.
+type ContentTrust struct {
+	Level TrustLevel
+	// Safe is true when the content may be shown in lockdown mode, which unless the trust policy filters
+	// private repositories includes any of their content.
+	Safe bool
+}
+
+// IsSafeContent reports whether content written by username may be shown in lockdown mode.
 func (c *RepoAccessCache) IsSafeContent(ctx context.Context, username, owner, repo string) (bool, error) {
-	repoInfo, err := c.getRepoAccessInfo(ctx, username, owner, repo)
+	trust, err := c.GetContentTrust(ctx, username, owner, repo)
 	if err != nil {
-		c.logDebug("error checking repo access info for content filtering", "owner", owner, "repo", repo, "user", username, "error", err)
 		return false, err
 	}
-	if repoInfo.IsPrivate || repoInfo.ViewerLogin == username {
-		return true, nil
+	return trust.Safe, nil
+}
+
+// GetContentTrust determines the trust level of username in the repository.
+func (c *RepoAccessCache) GetContentTrust(ctx context.Context, username, owner, repo string) (ContentTrust, error) {
+	trusts, err := c.GetContentTrustBatch(ctx, owner, repo, []string{username})
+	if err != nil {
+		return ContentTrust{}, err
+	}
+	return trusts[strings.ToLower(username)], nil
+}
+
+// GetContentTrustBatch determines the trust levels of several users in the repository, keyed by lowercased
+// login. Users missing from the cache are resolved together, in a single query for up to maxUsersPerQuery users.
+// An empty login stands for content whose author is unknown, which is external, but still safe in a private
+// repository unless the trust policy filters private repositories.
+func (c *RepoAccessCache) GetContentTrustBatch(ctx context.Context, owner, repo string, usernames []string) (map[string]ContentTrust, error) {
+	infos, err := c.getRepoAccessInfos(ctx, owner, repo, usernames)
+	if err != nil {
+		c.logDebug("error checking repo access info for content filtering", "owner", owner, "repo", repo, "users", usernames, "error", err)
+		return nil, err
+	}
+
+	filterPrivate := c.Policy().FilterPrivateRepos
+	trusts := make(map[string]ContentTrust, len(infos))
+	for userKey, info := range infos {
+		trust := ContentTrust{Level: TrustLevelExternal}
+		switch {
+		case userKey == "":
+			// The author is unknown
+		case strings.EqualFold(info.ViewerLogin, userKey):
+			trust.Level = TrustLevelViewer
+		case info.IsTrusted:
+			trust.Level = TrustLevelCollaborator
+		}
+		privateBypass := info.IsPrivate && !filterPrivate
+		// Content without an author, such as that of deleted accounts, has no one to check and is shown
+		trust.Safe = privateBypass || trust.Level != TrustLevelExternal || userKey == ""
+		trusts[userKey] = trust
 	}
-	return repoInfo.HasPushAccess, nil
+	return trusts, nil
 }
 
 func (c *RepoAccessCache) getRepoAccessInfo(ctx context.Context, username, owner, repo string) (RepoAccessInfo, error) {
+	infos, err := c.getRepoAccessInfos(ctx, owner, repo, []string{username})
+	if err != nil {
+		return RepoAccessInfo{}, err
+	}
+	return infos[strings.ToLower(username)], nil
+}
+
+// getRepoAccessInfos returns the access information of usernames in the repository, keyed by lowercased login.
+// Only the repository is locked while missing users are queried, so lookups in other repositories proceed
+// concurrently, while concurrent lookups in the same repository wait and then reuse the result.
+func (c *RepoAccessCache) getRepoAccessInfos(ctx context.Context, owner, repo string, usernames []string) (map[string]RepoAccessInfo, error) {
 	if c == nil {
-		return RepoAccessInfo{}, fmt.Errorf("nil repo access cache")
+		return nil, fmt.Errorf("nil repo access cache")
 	}
 
-	key := cacheKey(owner, repo)
-	userKey := strings.ToLower(username)
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	policy, policyKey := c.policy, c.policyKey
+	c.mu.Unlock()
+	key := cacheKey(owner, repo, policyKey)
+
+	unlock := c.lockRepo(key)
+	defer unlock()
 
 	// Try to get entry from cache - this will keep the item alive if it exists
-	cacheItem, err := c.cache.Value(key)
-	if err == nil {
-		entry := cacheItem.Data().(*repoAccessCacheEntry)
-		if cachedHasPush, known := entry.knownUsers[userKey]; known {
-			c.logDebug("repo access cache hit", "owner", owner, "repo", repo, "user", username)
-			return RepoAccessInfo{
-				IsPrivate:     entry.isPrivate,
-				HasPushAccess: cachedHasPush,
-				ViewerLogin:   entry.viewerLogin,
-			}, nil
-		}
-		c.logDebug("known users cache miss", "owner", owner, "repo", repo, "user", username)
-		info, queryErr := c.queryRepoAccessInfo(ctx, username, owner, repo)
-		if queryErr != nil {
-			return RepoAccessInfo{}, queryErr
-		}
-		entry.knownUsers[userKey] = info.HasPushAccess
-		entry.viewerLogin = info.ViewerLogin
-		entry.isPrivate = info.IsPrivate
+	var entry *repoAccessCacheEntry
+	if cacheItem, err := c.cache.Value(key); err == nil {
+		entry = cacheItem.Data().(*repoAccessCacheEntry)
+	}
+
+	var missing []string
+	for _, username := range usernames {
+		userKey := strings.ToLower(username)
+		// Unknown authors are never looked up, but still need the repository to be known
+		if userKey == "" {
+			continue
+		}
+		if entry != nil {
+			if _, known := entry.knownUsers[userKey]; known {
+				continue
+			}
+		}
+		if !slices.Contains(missing, userKey) {
+			missing = append(missing, userKey)
+		}
+	}
+
+	switch {
+	case len(missing) == 0 && entry != nil:
+		c.logDebug("repo access cache hit", "owner", owner, "repo", repo, "users", usernames)
+	case entry == nil:
+		c.logDebug("repo access cache miss", "owner", owner, "repo", repo, "users", missing)
+	default:
+		c.logDebug("known users cache miss", "owner", owner, "repo", repo, "users", missing)
+	}
+
+	if len(missing) > 0 || entry == nil {
+		result, err := c.queryRepoAccessInfos(ctx, owner, repo, missing, policy)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			entry = &repoAccessCacheEntry{knownUsers: make(map[string]userAccess, len(missing))}
+		}
+		entry.isPrivate = result.isPrivate
+		entry.viewerLogin = result.viewerLogin
+		maps.Copy(entry.knownUsers, result.users)
 		c.cache.Add(key, c.ttl, entry)
-		return RepoAccessInfo{
-			IsPrivate:     entry.isPrivate,
-			HasPushAccess: entry.knownUsers[userKey],
-			ViewerLogin:   entry.viewerLogin,
-		}, nil
 	}
 
-	c.logDebug("repo access cache miss", "owner", owner, "repo", repo, "user", username)
+	infos := make(map[string]RepoAccessInfo, len(usernames))
+	for _, username := range usernames {
+		userKey := strings.ToLower(username)
+		infos[userKey] = entry.info(entry.knownUsers[userKey])
+	}
+	return infos, nil
+}
 
-	info, queryErr := c.queryRepoAccessInfo(ctx, username, owner, repo)
-	if queryErr != nil {
-		return RepoAccessInfo{}, queryErr
+// lockRepo locks the cache entry stored under key and returns the function unlocking it.
+func (c *RepoAccessCache) lockRepo(key string) (unlock func()) {
+	c.locksMu.Lock()
+	lock, ok := c.repoLocks[key]
+	if !ok {
+		lock = &repoLock{}
+		c.repoLocks[key] = lock
 	}
+	lock.refs++
+	c.locksMu.Unlock()
 
-	// Create new entry
-	entry := &repoAccessCacheEntry{
-		knownUsers:  map[string]bool{userKey: info.HasPushAccess},
-		isPrivate:   info.IsPrivate,
-		viewerLogin: info.ViewerLogin,
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		c.locksMu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(c.repoLocks, key)
+		}
+		c.locksMu.Unlock()
 	}
-	c.cache.Add(key, c.ttl, entry)
+}
 
+func (e *repoAccessCacheEntry) info(access userAccess) RepoAccessInfo {
 	return RepoAccessInfo{
-		IsPrivate:     entry.isPrivate,
-		HasPushAccess: entry.knownUsers[userKey],
-		ViewerLogin:   entry.viewerLogin,
-	}, nil
+		IsPrivate:     e.isPrivate,
+		HasPushAccess: access.hasPush,
+		IsTrusted:     access.trusted,
+		ViewerLogin:   e.viewerLogin,
+	}
 }
 
-func (c *RepoAccessCache) queryRepoAccessInfo(ctx context.Context, username, owner, repo string) (RepoAccessInfo, error) {
-	if c.client == nil {
-		return RepoAccessInfo{}, fmt.Errorf("nil GraphQL client")
-	}
+// maxUsersPerQuery bounds the number of users resolved by a single repository access query. When the policy
+// trusts memberships, fewer users are resolved per query, as each of them is looked up several times.
+const maxUsersPerQuery = 100
+
+type repoAccessResult struct {
+	isPrivate   bool
+	viewerLogin string
+	users       map[string]userAccess // normalized login -> access of the user
+}
 
-	var query struct {
-		Viewer struct {
+type viewerFields struct {
+	Login githubv4.String
+}
+
+type collaboratorEdges struct {
+	Edges []struct {
+		Permission githubv4.String
+		Node       struct {
 			Login githubv4.String
 		}
-		Repository struct {
-			IsPrivate     githubv4.Boolean
-			Collaborators struct {
-				Edges []struct {
-					Permission githubv4.String
-					Node       struct {
-						Login githubv4.String
-					}
-				}
-			} `graphql:"collaborators(query: $username, first: 1)"`
-		} `graphql:"repository(owner: $owner, name: $name)"`
 	}
+}
 
-	variables := map[string]interface{}{
-		"owner":    githubv4.String(owner),
-		"name":     githubv4.String(repo),
-		"username": githubv4.String(username),
+type orgMembership struct {
+	Organization *struct {
+		Login githubv4.String
+	} `graphql:"organization(login: $owner)"`
+}
+
+type teamMemberNodes struct {
+	Nodes []struct {
+		Login githubv4.String
 	}
+}
 
-	if err := c.client.Query(ctx, &query, variables); err != nil {
-		return RepoAccessInfo{}, fmt.Errorf("failed to query repository access info: %w", err)
+// repoAccessQueryType builds the type of a query for the permissions of n users in a repository. Each user is
+// looked up through an aliased collaborators connection, user0 to userN, so that all of them are resolved in
+// one round trip. The users for which members is true are also looked up in the memberships the policy
+// trusts: in the organization owning the repository as memberN, and in each trusted team as teamMUserN.
+func repoAccessQueryType(n int, members []bool, policy TrustPolicy) reflect.Type {
+	repositoryFields := make([]reflect.StructField, 0, n+1)
+	repositoryFields = append(repositoryFields, reflect.StructField{Name: "IsPrivate", Type: reflect.TypeOf(githubv4.Boolean(false))})
+	for i := range n {
+		repositoryFields = append(repositoryFields, reflect.StructField{
+			Name: fmt.Sprintf("User%d", i),
+			Type: reflect.TypeOf(collaboratorEdges{}),
+			Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"user%d: collaborators(query: $user%d, first: 1)"`, i, i)),
+		})
+	}
+	fields := []reflect.StructField{
+		{Name: "Viewer", Type: reflect.TypeOf(viewerFields{})},
+		{Name: "Repository", Type: reflect.StructOf(repositoryFields), Tag: `graphql:"repository(owner: $owner, name: $name)"`},
 	}
 
-	hasPush := false
-	for _, edge := range query.Repository.Collaborators.Edges {
-		login := string(edge.Node.Login)
-		if strings.EqualFold(login, username) {
-			permission := string(edge.Permission)
-			hasPush = permission == "WRITE" || permission == "ADMIN" || permission == "MAINTAIN"
-			break
+	for i, member := range members {
+		if !member {
+			continue
+		}
+		if policy.TrustOrgMembers {
+			fields = append(fields, reflect.StructField{
+				Name: fmt.Sprintf("Member%d", i),
+				Type: reflect.TypeOf(orgMembership{}),
+				Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"member%d: user(login: $user%d)"`, i, i)),
+			})
+		}
+		for j := range policy.TrustedTeams {
+			team := reflect.StructOf([]reflect.StructField{{
+				Name: "Members",
+				Type: reflect.TypeOf(teamMemberNodes{}),
+				Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"members(query: $user%d, first: 1)"`, i)),
+			}})
+			fields = append(fields, reflect.StructField{
+				Name: fmt.Sprintf("Team%dUser%d", j, i),
+				Type: reflect.StructOf([]reflect.StructField{{
+					Name: "Team",
+					Type: reflect.PointerTo(team),
+					Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"team(slug: $team%dSlug)"`, j)),
+				}}),
+				Tag: reflect.StructTag(fmt.Sprintf(`graphql:"team%dUser%d: organization(login: $team%dOrg)"`, j, i, j)),
+			})
 		}
 	}
+	return reflect.StructOf(fields)
+}
 
-	return RepoAccessInfo{
-		IsPrivate:     bool(query.Repository.IsPrivate),
-		HasPushAccess: hasPush,
-		ViewerLogin:   string(query.Viewer.Login),
-	}, nil
+// needsMembership reports whether username must be looked up in the memberships the policy trusts. Users the
+// policy allowlists need no lookup, and bots cannot be members of organizations or teams.
+func needsMembership(username string, policy TrustPolicy) bool {
+	if !policy.TrustOrgMembers && len(policy.TrustedTeams) == 0 {
+		return false
+	}
+	return !policy.allowlisted(username) && !strings.HasSuffix(strings.ToLower(username), "[bot]")
+}
+
+func (c *RepoAccessCache) queryRepoAccessInfos(ctx context.Context, owner, repo string, usernames []string, policy TrustPolicy) (repoAccessResult, error) {
+	if c.client == nil {
+		return repoAccessResult{}, fmt.Errorf("nil GraphQL client")
+	}
+
+	lookups := 1 + len(policy.TrustedTeams)
+	if policy.TrustOrgMembers {
+		lookups++
+	}
+	batches := slices.Collect(slices.Chunk(usernames, max(1, maxUsersPerQuery/lookups)))
+	if len(batches) == 0 {
+		// Without users, the query still looks up the repository and the viewer
+		batches = [][]string{nil}
+	}
+
+	result := repoAccessResult{users: make(map[string]userAccess, len(usernames))}
+	for _, batch := range batches {
+		members := make([]bool, len(batch))
+		variables := map[string]interface{}{
+			"owner": githubv4.String(owner),
+			"name":  githubv4.String(repo),
+		}
+		for i, username := range batch {
+			variables[fmt.Sprintf("user%d", i)] = githubv4.String(username)
+			members[i] = needsMembership(username, policy)
+		}
+		// Team variables are only declared when used, as GitHub rejects unused variables
+		if slices.Contains(members, true) {
+			for j, team := range policy.TrustedTeams {
+				org, slug, _ := splitTeam(team)
+				variables[fmt.Sprintf("team%dOrg", j)] = githubv4.String(org)
+				variables[fmt.Sprintf("team%dSlug", j)] = githubv4.String(slug)
+			}
+		}
+
+		query := reflect.New(repoAccessQueryType(len(batch), members, policy)).Elem()
+		if err := c.client.Query(ctx, query.Addr().Interface(), variables); err != nil {
+			return repoAccessResult{}, fmt.Errorf("failed to query repository access info: %w", err)
+		}
+
+		result.viewerLogin = string(query.FieldByName("Viewer").Interface().(viewerFields).Login)
+		repository := query.FieldByName("Repository")
+		result.isPrivate = bool(repository.FieldByName("IsPrivate").Interface().(githubv4.Boolean))
+		for i, username := range batch {
+			permission := ""
+			for _, edge := range repository.Field(i + 1).Interface().(collaboratorEdges).Edges {
+				if strings.EqualFold(string(edge.Node.Login), username) {
+					permission = string(edge.Permission)
+					break
+				}
+			}
+			result.users[username] = userAccess{
+				hasPush: permissionRanks[permission] >= permissionRanks["WRITE"],
+				// Users without a trusted permission may still be trusted through the memberships the policy trusts
+				trusted: policy.allowlisted(username) || policy.permissionTrusted(permission) ||
+					(members[i] && memberOfTrusted(query, i, username, policy)),
+			}
+		}
+	}
+	return result, nil
+}
+
+// memberOfTrusted reports whether the query found user i to be a member of the organization or one of the teams
+// the policy trusts.
+func memberOfTrusted(query reflect.Value, i int, username string, policy TrustPolicy) bool {
+	if policy.TrustOrgMembers && query.FieldByName(fmt.Sprintf("Member%d", i)).Interface().(orgMembership).Organization != nil {
+		return true
+	}
+	for j := range policy.TrustedTeams {
+		team := query.FieldByName(fmt.Sprintf("Team%dUser%d", j, i)).Field(0)
+		if team.IsNil() {
+			continue
+		}
+		for _, member := range team.Elem().Field(0).Interface().(teamMemberNodes).Nodes {
+			if strings.EqualFold(string(member.Login), username) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
-func cacheKey(owner, repo string) string {
-	return fmt.Sprintf("%s/%s", strings.ToLower(owner), strings.ToLower(repo))
+func cacheKey(owner, repo, policyKey string) string {
+	return fmt.Sprintf("%s/%s@%s", strings.ToLower(owner), strings.ToLower(repo), policyKey)
 }
 
 func (c *RepoAccessCache) logDebug(msg string, args ...any) {
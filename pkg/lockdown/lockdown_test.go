@@ -1,13 +1,16 @@
 package lockdown
 
 import (
+	"fmt"
 	"net/http"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/github/github-mcp-server/internal/githubv4mock"
 	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,21 +20,10 @@ const (
 	testUser  = "octocat"
 )
 
-type repoAccessQuery struct {
-	Viewer struct {
-		Login githubv4.String
-	}
-	Repository struct {
-		IsPrivate     githubv4.Boolean
-		Collaborators struct {
-			Edges []struct {
-				Permission githubv4.String
-				Node       struct {
-					Login githubv4.String
-				}
-			}
-		} `graphql:"collaborators(query: $username, first: 1)"`
-	} `graphql:"repository(owner: $owner, name: $name)"`
+// repoAccessQuery returns a value of the query type used to look up n users without memberships, for matching
+// mocked requests.
+func repoAccessQuery(n int) any {
+	return reflect.New(repoAccessQueryType(n, nil, TrustPolicy{})).Elem().Interface()
 }
 
 type countingTransport struct {
@@ -56,12 +48,10 @@ func (c *countingTransport) CallCount() int {
 func newMockRepoAccessCache(t *testing.T, ttl time.Duration) (*RepoAccessCache, *countingTransport) {
 	t.Helper()
 
-	var query repoAccessQuery
-
 	variables := map[string]any{
-		"owner":    githubv4.String(testOwner),
-		"name":     githubv4.String(testRepo),
-		"username": githubv4.String(testUser),
+		"owner": githubv4.String(testOwner),
+		"name":  githubv4.String(testRepo),
+		"user0": githubv4.String(testUser),
 	}
 
 	response := githubv4mock.DataResponse(map[string]any{
@@ -70,7 +60,7 @@ func newMockRepoAccessCache(t *testing.T, ttl time.Duration) (*RepoAccessCache,
 		},
 		"repository": map[string]any{
 			"isPrivate": false,
-			"collaborators": map[string]any{
+			"user0": map[string]any{
 				"edges": []any{
 					map[string]any{
 						"permission": "WRITE",
@@ -83,7 +73,7 @@ func newMockRepoAccessCache(t *testing.T, ttl time.Duration) (*RepoAccessCache,
 		},
 	})
 
-	httpClient := githubv4mock.NewMockedHTTPClient(githubv4mock.NewQueryMatcher(query, variables, response))
+	httpClient := githubv4mock.NewMockedHTTPClient(githubv4mock.NewQueryMatcher(repoAccessQuery(1), variables, response))
 	counting := &countingTransport{next: httpClient.Transport}
 	httpClient.Transport = counting
 
@@ -110,3 +100,269 @@ func TestRepoAccessCacheEvictsAfterTTL(t *testing.T) {
 	require.True(t, info.HasPushAccess)
 	require.EqualValues(t, 2, transport.CallCount())
 }
+
+func TestRepoAccessCacheReleasesRepoLocks(t *testing.T) {
+	cache, _ := newMockRepoAccessCache(t, time.Minute)
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.getRepoAccessInfo(t.Context(), testUser, testOwner, testRepo)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	cache.locksMu.Lock()
+	defer cache.locksMu.Unlock()
+	assert.Empty(t, cache.repoLocks)
+}
+
+func repoAccessMatcher(permission string, isPrivate bool) githubv4mock.Matcher {
+	edges := []any{}
+	if permission != "" {
+		edges = append(edges, map[string]any{
+			"permission": permission,
+			"node":       map[string]any{"login": testUser},
+		})
+	}
+	return githubv4mock.NewQueryMatcher(repoAccessQuery(1), map[string]any{
+		"owner": githubv4.String(testOwner),
+		"name":  githubv4.String(testRepo),
+		"user0": githubv4.String(testUser),
+	}, githubv4mock.DataResponse(map[string]any{
+		"viewer": map[string]any{"login": "viewer"},
+		"repository": map[string]any{
+			"isPrivate": isPrivate,
+			"user0":     map[string]any{"edges": edges},
+		},
+	}))
+}
+
+func TestRepoAccessCacheTrustPolicy(t *testing.T) {
+	membershipPolicy := TrustPolicy{TrustOrgMembers: true, TrustedTeams: []string{testOwner + "/reviewers", "other-org/security"}}
+	// Memberships are looked up in the same query as the permission
+	membershipMatcher := func(orgMember bool, teamMembers ...string) *githubv4mock.Matcher {
+		var organization any
+		if orgMember {
+			organization = map[string]any{"login": testOwner}
+		}
+		response := map[string]any{
+			"viewer": map[string]any{"login": "viewer"},
+			"repository": map[string]any{
+				"isPrivate": false,
+				"user0":     map[string]any{"edges": []any{}},
+			},
+			"member0": map[string]any{"organization": organization},
+		}
+		for j, member := range teamMembers {
+			nodes := []any{}
+			if member != "" {
+				nodes = append(nodes, map[string]any{"login": member})
+			}
+			response[fmt.Sprintf("team%dUser0", j)] = map[string]any{"team": map[string]any{"members": map[string]any{"nodes": nodes}}}
+		}
+		query := reflect.New(repoAccessQueryType(1, []bool{true}, membershipPolicy.normalized())).Elem().Interface()
+		matcher := githubv4mock.NewQueryMatcher(query, map[string]any{
+			"owner":     githubv4.String(testOwner),
+			"name":      githubv4.String(testRepo),
+			"user0":     githubv4.String(testUser),
+			"team0Org":  githubv4.String(testOwner),
+			"team0Slug": githubv4.String("reviewers"),
+			"team1Org":  githubv4.String("other-org"),
+			"team1Slug": githubv4.String("security"),
+		}, githubv4mock.DataResponse(response))
+		return &matcher
+	}
+
+	tests := []struct {
+		name       string
+		permission string
+		isPrivate  bool
+		policy     TrustPolicy
+		matcher    *githubv4mock.Matcher
+		expected   ContentTrust
+	}{
+		{
+			name:       "push access is trusted by default",
+			permission: "WRITE",
+			expected:   ContentTrust{Level: TrustLevelCollaborator, Safe: true},
+		},
+		{
+			name:       "read access is not trusted by default",
+			permission: "READ",
+			expected:   ContentTrust{Level: TrustLevelExternal},
+		},
+		{
+			name:       "minimum permission lowered to read",
+			permission: "READ",
+			policy:     TrustPolicy{MinPermission: "READ"},
+			expected:   ContentTrust{Level: TrustLevelCollaborator, Safe: true},
+		},
+		{
+			name:       "minimum permission raised to admin",
+			permission: "MAINTAIN",
+			policy:     TrustPolicy{MinPermission: "ADMIN"},
+			expected:   ContentTrust{Level: TrustLevelExternal},
+		},
+		{
+			name:     "allowlisted user",
+			policy:   TrustPolicy{TrustedUsers: []string{"OctoCat"}},
+			expected: ContentTrust{Level: TrustLevelCollaborator, Safe: true},
+		},
+		{
+			name:     "member of trusted team",
+			policy:   membershipPolicy,
+			matcher:  membershipMatcher(false, "", testUser),
+			expected: ContentTrust{Level: TrustLevelCollaborator, Safe: true},
+		},
+		{
+			name:     "member of trusted organization",
+			policy:   membershipPolicy,
+			matcher:  membershipMatcher(true, "", ""),
+			expected: ContentTrust{Level: TrustLevelCollaborator, Safe: true},
+		},
+		{
+			name:     "not a member",
+			policy:   membershipPolicy,
+			matcher:  membershipMatcher(false, "", ""),
+			expected: ContentTrust{Level: TrustLevelExternal},
+		},
+		{
+			name:      "private repositories bypass filtering by default",
+			isPrivate: true,
+			expected:  ContentTrust{Level: TrustLevelExternal, Safe: true},
+		},
+		{
+			name:      "private repositories filtered by policy",
+			isPrivate: true,
+			policy:    TrustPolicy{FilterPrivateRepos: true},
+			expected:  ContentTrust{Level: TrustLevelExternal},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			matcher := repoAccessMatcher(tc.permission, tc.isPrivate)
+			if tc.matcher != nil {
+				matcher = *tc.matcher
+			}
+			client := githubv4.NewClient(githubv4mock.NewMockedHTTPClient(matcher))
+			cache := newRepoAccessCache(client, WithCacheName(t.Name()), WithTrustPolicy(tc.policy))
+
+			trust, err := cache.GetContentTrust(t.Context(), testUser, testOwner, testRepo)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, trust)
+		})
+	}
+}
+
+func TestRepoAccessCacheSetPolicy(t *testing.T) {
+	httpClient := githubv4mock.NewMockedHTTPClient(repoAccessMatcher("READ", false))
+	counting := &countingTransport{next: httpClient.Transport}
+	httpClient.Transport = counting
+	cache := newRepoAccessCache(githubv4.NewClient(httpClient), WithCacheName(t.Name()))
+
+	safe, err := cache.IsSafeContent(t.Context(), testUser, testOwner, testRepo)
+	require.NoError(t, err)
+	require.False(t, safe)
+	require.EqualValues(t, 1, counting.CallCount())
+
+	// A new policy does not reuse the decisions cached under the previous one
+	cache.SetPolicy(TrustPolicy{MinPermission: "READ"})
+	safe, err = cache.IsSafeContent(t.Context(), testUser, testOwner, testRepo)
+	require.NoError(t, err)
+	require.True(t, safe)
+	require.EqualValues(t, 2, counting.CallCount())
+
+	safe, err = cache.IsSafeContent(t.Context(), testUser, testOwner, testRepo)
+	require.NoError(t, err)
+	require.True(t, safe)
+	require.EqualValues(t, 2, counting.CallCount())
+}
+
+func TestRepoAccessCacheBatchesUsers(t *testing.T) {
+	collaborator := func(login, permission string) map[string]any {
+		return map[string]any{"edges": []any{map[string]any{
+			"permission": permission,
+			"node":       map[string]any{"login": login},
+		}}}
+	}
+	httpClient := githubv4mock.NewMockedHTTPClient(githubv4mock.NewQueryMatcher(repoAccessQuery(3), map[string]any{
+		"owner": githubv4.String(testOwner),
+		"name":  githubv4.String(testRepo),
+		"user0": githubv4.String("alice"),
+		"user1": githubv4.String("bob"),
+		"user2": githubv4.String(testUser),
+	}, githubv4mock.DataResponse(map[string]any{
+		"viewer": map[string]any{"login": testUser},
+		"repository": map[string]any{
+			"isPrivate": false,
+			"user0":     collaborator("alice", "ADMIN"),
+			"user1":     map[string]any{"edges": []any{}},
+			"user2":     collaborator(testUser, "READ"),
+		},
+	})))
+	counting := &countingTransport{next: httpClient.Transport}
+	httpClient.Transport = counting
+	cache := newRepoAccessCache(githubv4.NewClient(httpClient), WithCacheName(t.Name()))
+
+	// Repeated and differently cased logins are looked up once
+	trusts, err := cache.GetContentTrustBatch(t.Context(), testOwner, testRepo, []string{"alice", "Bob", testUser, "ALICE"})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, counting.CallCount())
+	assert.Equal(t, map[string]ContentTrust{
+		"alice":  {Level: TrustLevelCollaborator, Safe: true},
+		"bob":    {Level: TrustLevelExternal},
+		testUser: {Level: TrustLevelViewer, Safe: true},
+	}, trusts)
+
+	// Known users are served from the cache
+	trust, err := cache.GetContentTrust(t.Context(), "bob", testOwner, testRepo)
+	require.NoError(t, err)
+	assert.Equal(t, ContentTrust{Level: TrustLevelExternal}, trust)
+	require.EqualValues(t, 1, counting.CallCount())
+}
+
+func TestRepoAccessCacheUnknownAuthor(t *testing.T) {
+	newCache := func(t *testing.T, isPrivate bool, policy TrustPolicy) (*RepoAccessCache, *countingTransport) {
+		httpClient := githubv4mock.NewMockedHTTPClient(githubv4mock.NewQueryMatcher(repoAccessQuery(0), map[string]any{
+			"owner": githubv4.String(testOwner),
+			"name":  githubv4.String(testRepo),
+		}, githubv4mock.DataResponse(map[string]any{
+			"viewer":     map[string]any{"login": testUser},
+			"repository": map[string]any{"isPrivate": isPrivate},
+		})))
+		counting := &countingTransport{next: httpClient.Transport}
+		httpClient.Transport = counting
+		return newRepoAccessCache(githubv4.NewClient(httpClient), WithCacheName(t.Name()), WithTrustPolicy(policy)), counting
+	}
+
+	t.Run("public repository", func(t *testing.T) {
+		cache, _ := newCache(t, false, TrustPolicy{})
+		trust, err := cache.GetContentTrust(t.Context(), "", testOwner, testRepo)
+		require.NoError(t, err)
+		assert.Equal(t, ContentTrust{Level: TrustLevelExternal, Safe: true}, trust)
+	})
+
+	t.Run("private repository", func(t *testing.T) {
+		cache, counting := newCache(t, true, TrustPolicy{})
+		trust, err := cache.GetContentTrust(t.Context(), "", testOwner, testRepo)
+		require.NoError(t, err)
+		assert.Equal(t, ContentTrust{Level: TrustLevelExternal, Safe: true}, trust)
+
+		// The repository is cached like for known users
+		_, err = cache.GetContentTrust(t.Context(), "", testOwner, testRepo)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, counting.CallCount())
+	})
+
+	t.Run("private repository filtered by the policy", func(t *testing.T) {
+		cache, _ := newCache(t, true, TrustPolicy{FilterPrivateRepos: true})
+		trust, err := cache.GetContentTrust(t.Context(), "", testOwner, testRepo)
+		require.NoError(t, err)
+		assert.Equal(t, ContentTrust{Level: TrustLevelExternal, Safe: true}, trust)
+	})
+}
@@ -0,0 +1,121 @@
+package lockdown
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// permissionRanks orders the repository permissions of collaborators from least to most privileged.
+var permissionRanks = map[string]int{
+	"READ":     1,
+	"TRIAGE":   2,
+	"WRITE":    3,
+	"MAINTAIN": 4,
+	"ADMIN":    5,
+}
+
+const defaultMinPermission = "WRITE"
+
+// TrustPolicy decides which authors are trusted in lockdown mode. The zero value trusts collaborators with
+// push access and shows all content of private repositories.
+type TrustPolicy struct {
+	// MinPermission is the lowest collaborator permission that is trusted: READ, TRIAGE, WRITE, MAINTAIN or ADMIN.
+	// Defaults to WRITE.
+	MinPermission string `mapstructure:"min_permission" json:"min_permission,omitempty"`
+	// TrustedUsers are logins that are trusted in every repository.
+	TrustedUsers []string `mapstructure:"trusted_users" json:"trusted_users,omitempty"`
+	// TrustedTeams are teams, written as org/team-slug, whose members are trusted in every repository.
+	TrustedTeams []string `mapstructure:"trusted_teams" json:"trusted_teams,omitempty"`
+	// TrustedBots are bot accounts, such as dependabot[bot], that are trusted in every repository. The [bot]
+	// suffix may be left out here, but only logins carrying it are trusted, as a user account may take the
+	// name of a bot without the suffix.
+	TrustedBots []string `mapstructure:"trusted_bots" json:"trusted_bots,omitempty"`
+	// TrustOrgMembers trusts members of the organization that owns the repository.
+	TrustOrgMembers bool `mapstructure:"trust_org_members" json:"trust_org_members,omitempty"`
+	// FilterPrivateRepos applies lockdown filtering to private repositories too. By default, their content is
+	// always shown, as only users with access to the repository can write it.
+	FilterPrivateRepos bool `mapstructure:"filter_private_repos" json:"filter_private_repos,omitempty"`
+}
+
+// Validate reports configuration errors in the policy.
+func (p TrustPolicy) Validate() error {
+	if p.MinPermission != "" {
+		if _, ok := permissionRanks[strings.ToUpper(p.MinPermission)]; !ok {
+			return fmt.Errorf("invalid min_permission %q: must be one of READ, TRIAGE, WRITE, MAINTAIN or ADMIN", p.MinPermission)
+		}
+	}
+	for _, team := range p.TrustedTeams {
+		if _, _, ok := splitTeam(team); !ok {
+			return fmt.Errorf("invalid trusted team %q: must be written as org/team-slug", team)
+		}
+	}
+	return nil
+}
+
+// normalized returns a copy of the policy with defaults applied and lists lowercased and sorted, so that
+// equivalent policies compare equal.
+func (p TrustPolicy) normalized() TrustPolicy {
+	normalizeList := func(values []string, normalize func(string) string) []string {
+		if len(values) == 0 {
+			return nil
+		}
+		out := make([]string, 0, len(values))
+		for _, value := range values {
+			if value = normalize(strings.ToLower(strings.TrimSpace(value))); value != "" {
+				out = append(out, value)
+			}
+		}
+		slices.Sort(out)
+		return slices.Compact(out)
+	}
+	identity := func(value string) string { return value }
+
+	n := p
+	n.MinPermission = strings.ToUpper(p.MinPermission)
+	if n.MinPermission == "" {
+		n.MinPermission = defaultMinPermission
+	}
+	n.TrustedUsers = normalizeList(p.TrustedUsers, identity)
+	n.TrustedTeams = normalizeList(p.TrustedTeams, identity)
+	n.TrustedBots = normalizeList(p.TrustedBots, botLogin)
+	return n
+}
+
+// Key identifies the policy. It is part of the cache key, so that entries computed under a different policy
+// are not reused.
+func (p TrustPolicy) Key() string {
+	data, _ := json.Marshal(p.normalized())
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// allowlisted reports whether the policy trusts login regardless of the repository. The policy must be normalized.
+func (p TrustPolicy) allowlisted(login string) bool {
+	login = strings.ToLower(login)
+	if _, found := slices.BinarySearch(p.TrustedUsers, login); found {
+		return true
+	}
+	_, found := slices.BinarySearch(p.TrustedBots, login)
+	return found
+}
+
+// permissionTrusted reports whether a collaborator permission meets the minimum permission of the policy.
+// The policy must be normalized.
+func (p TrustPolicy) permissionTrusted(permission string) bool {
+	rank, ok := permissionRanks[strings.ToUpper(permission)]
+	return ok && rank >= permissionRanks[p.MinPermission]
+}
+
+// botLogin returns the login of the bot account named name, which carries the [bot] suffix.
+func botLogin(name string) string {
+	return strings.TrimSuffix(name, "[bot]") + "[bot]"
+}
+
+func splitTeam(team string) (org, slug string, ok bool) {
+	org, slug, ok = strings.Cut(strings.TrimSpace(team), "/")
+	return org, slug, ok && org != "" && slug != "" && !strings.Contains(slug, "/")
+}
@@ -0,0 +1,93 @@
+package lockdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      TrustPolicy
+		expectedErr string
+	}{
+		{name: "zero value", policy: TrustPolicy{}},
+		{name: "lowercase permission", policy: TrustPolicy{MinPermission: "triage"}},
+		{name: "team", policy: TrustPolicy{TrustedTeams: []string{"octo-org/reviewers"}}},
+		{name: "unknown permission", policy: TrustPolicy{MinPermission: "PUSH"}, expectedErr: `invalid min_permission "PUSH"`},
+		{name: "team without org", policy: TrustPolicy{TrustedTeams: []string{"reviewers"}}, expectedErr: `invalid trusted team "reviewers"`},
+		{name: "team with nested slug", policy: TrustPolicy{TrustedTeams: []string{"octo-org/a/b"}}, expectedErr: `invalid trusted team "octo-org/a/b"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTrustPolicyKey(t *testing.T) {
+	// Equivalent policies share a key
+	assert.Equal(t, TrustPolicy{}.Key(), TrustPolicy{MinPermission: "write"}.Key())
+	assert.Equal(t,
+		TrustPolicy{TrustedUsers: []string{"Alice", "bob"}}.Key(),
+		TrustPolicy{TrustedUsers: []string{"bob", "alice", "bob"}}.Key(),
+	)
+	assert.Equal(t,
+		TrustPolicy{TrustedBots: []string{"dependabot[bot]"}}.Key(),
+		TrustPolicy{TrustedBots: []string{"dependabot"}}.Key(),
+	)
+
+	// Any change in what is trusted changes the key
+	keys := map[string]bool{}
+	for _, policy := range []TrustPolicy{
+		{},
+		{MinPermission: "READ"},
+		{TrustedUsers: []string{"alice"}},
+		{TrustedTeams: []string{"octo-org/reviewers"}},
+		{TrustedBots: []string{"dependabot"}},
+		{TrustOrgMembers: true},
+		{FilterPrivateRepos: true},
+	} {
+		keys[policy.Key()] = true
+	}
+	assert.Len(t, keys, 7)
+}
+
+func TestTrustPolicyAllowlisted(t *testing.T) {
+	policy := TrustPolicy{
+		TrustedUsers: []string{"Alice"},
+		TrustedBots:  []string{"dependabot[bot]", "renovate"},
+	}.normalized()
+
+	assert.True(t, policy.allowlisted("alice"))
+	assert.True(t, policy.allowlisted("ALICE"))
+	assert.True(t, policy.allowlisted("dependabot[bot]"))
+	assert.True(t, policy.allowlisted("Renovate[bot]"))
+	// User accounts named like a bot are not trusted
+	assert.False(t, policy.allowlisted("dependabot"))
+	assert.False(t, policy.allowlisted("renovate"))
+	assert.False(t, policy.allowlisted("bob"))
+	assert.False(t, policy.allowlisted("alice[bot]x"))
+}
+
+func TestTrustPolicyPermissionTrusted(t *testing.T) {
+	defaults := TrustPolicy{}.normalized()
+	assert.False(t, defaults.permissionTrusted(""))
+	assert.False(t, defaults.permissionTrusted("READ"))
+	assert.False(t, defaults.permissionTrusted("TRIAGE"))
+	assert.True(t, defaults.permissionTrusted("WRITE"))
+	assert.True(t, defaults.permissionTrusted("MAINTAIN"))
+	assert.True(t, defaults.permissionTrusted("ADMIN"))
+
+	admins := TrustPolicy{MinPermission: "admin"}.normalized()
+	assert.False(t, admins.permissionTrusted("MAINTAIN"))
+	assert.True(t, admins.permissionTrusted("ADMIN"))
+}
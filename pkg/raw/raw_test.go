@@ -100,6 +100,51 @@ func TestGetRawContent(t *testing.T) {
 	}
 }
 
+func TestGetRawContentRange(t *testing.T) {
+	base, _ := url.Parse("https://raw.example.com/")
+
+	tests := []struct {
+		name          string
+		offset        int64
+		length        int64
+		expectedRange string
+	}{
+		{
+			name:          "offset and length",
+			offset:        10,
+			length:        5,
+			expectedRange: "bytes=10-14",
+		},
+		{
+			name:          "offset to end of file",
+			offset:        10,
+			expectedRange: "bytes=10-",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockedClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					GetRawReposContentsByOwnerByRepoBySHAByPath,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						require.Equal(t, tc.expectedRange, r.Header.Get("Range"))
+						w.WriteHeader(http.StatusPartialContent)
+					}),
+				),
+			)
+			ghClient := github.NewClient(mockedClient)
+			client := NewClient(ghClient, base)
+			resp, err := client.GetRawContentRange(context.Background(), "octocat", "hello", "README.md", &ContentOpts{SHA: "abc123"}, tc.offset, tc.length)
+			require.NoError(t, err)
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+			require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		})
+	}
+}
+
 func TestUrlFromOpts(t *testing.T) {
 	base, _ := url.Parse("https://raw.example.com/")
 	ghClient := github.NewClient(nil)
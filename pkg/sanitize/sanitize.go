@@ -1,20 +1,90 @@
 package sanitize
 
 import (
+	"net/url"
+	"regexp"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"unicode"
 
 	"github.com/microcosm-cc/bluemonday"
 )
 
-var policy *bluemonday.Policy
-var policyOnce sync.Once
+// policy is the HTML policy of Sanitize, built on first use or by RestrictURLs.
+var policy atomic.Pointer[bluemonday.Policy]
 
 func Sanitize(input string) string {
 	return FilterHTMLTags(FilterCodeFenceMetadata(FilterInvisibleCharacters(input)))
 }
 
+// FilterHiddenContent removes text that a reader of rendered markdown would not see, while leaving the visible
+// text as written: invisible characters, HTML comments, comment-style link reference definitions such as
+// [//]: # (note), and hidden info strings of fenced code blocks. Unlike Sanitize, it does not parse HTML, so
+// plain text such as a < b && c > d is kept.
+func FilterHiddenContent(input string) string {
+	return FilterCodeFenceMetadata(FilterMarkdownComments(FilterInvisibleCharacters(input)))
+}
+
+// markdownCommentPattern matches link reference definitions pointing at #, which are not rendered and are
+// commonly used as markdown comments.
+var markdownCommentPattern = regexp.MustCompile(`^ {0,3}\[[^\]\n]+\]:\s*<?#>?(?:\s.*)?$`)
+
+// FilterMarkdownComments removes HTML comments and comment-style link reference definitions. Fenced code blocks
+// are left alone, as their content is shown as written.
+func FilterMarkdownComments(input string) string {
+	if input == "" {
+		return input
+	}
+
+	lines := strings.Split(input, "\n")
+	out := make([]string, 0, len(lines))
+	insideFence, insideComment := false, false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !insideComment && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")) {
+			insideFence = !insideFence
+			out = append(out, line)
+			continue
+		}
+		if insideFence {
+			out = append(out, line)
+			continue
+		}
+		if markdownCommentPattern.MatchString(line) {
+			continue
+		}
+
+		var kept strings.Builder
+		rest := line
+		for rest != "" {
+			if insideComment {
+				end := strings.Index(rest, "-->")
+				if end == -1 {
+					rest = ""
+					break
+				}
+				rest = rest[end+len("-->"):]
+				insideComment = false
+				continue
+			}
+			start := strings.Index(rest, "<!--")
+			if start == -1 {
+				kept.WriteString(rest)
+				break
+			}
+			kept.WriteString(rest[:start])
+			rest = rest[start+len("<!--"):]
+			insideComment = true
+		}
+		// Lines that only held a comment are dropped rather than left blank
+		if kept.Len() == 0 && trimmed != "" {
+			continue
+		}
+		out = append(out, kept.String())
+	}
+	return strings.Join(out, "\n")
+}
+
 // FilterInvisibleCharacters removes invisible or control characters that should not appear
 // in user-facing titles or bodies. This includes:
 // - Unicode tag characters: U+E0001, U+E0020–U+E007F
@@ -146,31 +216,59 @@ func isSafeCodeFenceToken(token string) bool {
 }
 
 func getPolicy() *bluemonday.Policy {
-	policyOnce.Do(func() {
-		p := bluemonday.StrictPolicy()
-
-		p.AllowElements(
-			"b", "blockquote", "br", "code", "em",
-			"h1", "h2", "h3", "h4", "h5", "h6",
-			"hr", "i", "li", "ol", "p", "pre",
-			"strong", "sub", "sup", "table", "tbody",
-			"td", "th", "thead", "tr", "ul",
-			"a", "img",
-		)
-
-		p.AllowAttrs("href").OnElements("a")
-		p.AllowURLSchemes("http", "https")
-		p.RequireParseableURLs(true)
-		p.RequireNoFollowOnLinks(true)
-		p.RequireNoReferrerOnLinks(true)
-		p.AddTargetBlankToFullyQualifiedLinks(true)
-
-		p.AllowImages()
-		p.AllowAttrs("src", "alt", "title").OnElements("img")
-
-		policy = p
-	})
-	return policy
+	if p := policy.Load(); p != nil {
+		return p
+	}
+	policy.CompareAndSwap(nil, newPolicy(URLPolicy{}))
+	return policy.Load()
+}
+
+// RestrictURLs makes Sanitize remove the href of links and the src of images whose URL p does not allow. It
+// applies to every caller of Sanitize in the process, in addition to URLFilter, which handles markdown.
+func RestrictURLs(p URLPolicy) error {
+	filter, err := NewURLFilter(p)
+	if err != nil {
+		return err
+	}
+	policy.Store(newPolicy(filter.policy))
+	return nil
+}
+
+func newPolicy(urls URLPolicy) *bluemonday.Policy {
+	p := bluemonday.StrictPolicy()
+
+	p.AllowElements(
+		"b", "blockquote", "br", "code", "em",
+		"h1", "h2", "h3", "h4", "h5", "h6",
+		"hr", "i", "li", "ol", "p", "pre",
+		"strong", "sub", "sup", "table", "tbody",
+		"td", "th", "thead", "tr", "ul",
+		"a", "img",
+	)
+
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowURLSchemes("http", "https")
+	p.RequireParseableURLs(true)
+	p.RequireNoFollowOnLinks(true)
+	p.RequireNoReferrerOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+
+	p.AllowImages()
+	p.AllowAttrs("src", "alt", "title").OnElements("img")
+
+	if len(urls.AllowedHosts) > 0 {
+		// AllowImages allows any http and https URL, so the hosts are checked after it
+		allowHost := func(u *url.URL) bool {
+			return hostAllowed(urls.AllowedHosts, u.Hostname())
+		}
+		p.AllowURLSchemeWithCustomPolicy("http", allowHost)
+		p.AllowURLSchemeWithCustomPolicy("https", allowHost)
+		// The policy cannot tell a relative path from a URL such as //host or \\host, which points to another
+		// host, so relative URLs are removed
+		p.AllowRelativeURLs(false)
+	}
+
+	return p
 }
 
 func shouldRemoveRune(r rune) bool {
@@ -0,0 +1,228 @@
+package sanitize
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// URL modes decide what happens to markdown links and images whose URL is not allowed.
+const (
+	// URLModeRemove keeps the text of a link, or the alt text of an image, and drops the URL.
+	URLModeRemove = "remove"
+	// URLModeRewrite replaces a link or image with inert text that shows the defanged URL.
+	URLModeRewrite = "rewrite"
+)
+
+// URLPolicy restricts the URLs of links and images in sanitized content, so that content rendered by a client
+// cannot leak data by loading an image from, or linking to, an arbitrary server.
+type URLPolicy struct {
+	// AllowedHosts are the hosts that links and images may point to, either exact, such as github.com, or with
+	// a wildcard for subdomains, such as *.githubusercontent.com. An empty list allows any host.
+	AllowedHosts []string `mapstructure:"allowed_hosts" json:"allowed_hosts,omitempty"`
+	// Mode is remove (the default) or rewrite, and applies to markdown links and images. Disallowed URLs in
+	// HTML attributes are always removed.
+	Mode string `mapstructure:"mode" json:"mode,omitempty"`
+}
+
+// GitHubHosts are the hosts serving GitHub pages and user content.
+var GitHubHosts = []string{"github.com", "*.github.com", "*.githubusercontent.com", "*.githubassets.com"}
+
+// Validate reports configuration errors in the policy.
+func (p URLPolicy) Validate() error {
+	switch p.Mode {
+	case "", URLModeRemove, URLModeRewrite:
+	default:
+		return fmt.Errorf("invalid URL mode %q: must be remove or rewrite", p.Mode)
+	}
+	for _, host := range p.AllowedHosts {
+		pattern := strings.TrimPrefix(host, "*.")
+		if pattern == "" || strings.ContainsAny(pattern, "*/:@ ") {
+			return fmt.Errorf("invalid allowed host %q: must be a host name, optionally prefixed with *. for subdomains", host)
+		}
+	}
+	return nil
+}
+
+// URLFilter applies a URLPolicy to text.
+type URLFilter struct {
+	policy URLPolicy
+}
+
+// NewURLFilter creates a URLFilter applying p.
+func NewURLFilter(p URLPolicy) (*URLFilter, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	hosts := make([]string, 0, len(p.AllowedHosts))
+	for _, host := range p.AllowedHosts {
+		hosts = append(hosts, strings.ToLower(host))
+	}
+	p.AllowedHosts = slices.Compact(hosts)
+	return &URLFilter{policy: p}, nil
+}
+
+// hostAllowed reports whether host matches one of the allowed host patterns.
+func hostAllowed(allowed []string, host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, pattern := range allowed {
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// urlWhitespace are the characters that browsers drop from anywhere in a URL.
+var urlWhitespace = strings.NewReplacer("\t", "", "\n", "", "\r", "")
+
+// allowed reports whether a link or image may point to rawURL. Relative URLs stay on the site rendering the
+// content and are allowed, as are mailto links. Other schemes, such as data and javascript, are not.
+func (p URLPolicy) allowed(rawURL string) bool {
+	if len(p.AllowedHosts) == 0 {
+		return true
+	}
+	// Browsers read backslashes as slashes, so \\host and /\host point to another host like //host
+	rawURL = strings.ReplaceAll(urlWhitespace.Replace(strings.TrimSpace(rawURL)), "\\", "/")
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "":
+		return u.Host == "" || hostAllowed(p.AllowedHosts, u.Hostname())
+	case "http", "https":
+		return hostAllowed(p.AllowedHosts, u.Hostname())
+	case "mailto":
+		return true
+	default:
+		return false
+	}
+}
+
+const maxInertURLLength = 100
+
+// inertURL defangs a URL, so that it is neither rendered as a link nor fetched, while a reader can still see
+// where it pointed.
+func inertURL(rawURL string) string {
+	s := strings.TrimSpace(rawURL)
+	if len(s) > maxInertURLLength {
+		s = s[:maxInertURLLength] + "..."
+	}
+	if strings.HasPrefix(strings.ToLower(s), "http") {
+		s = "hxxp" + s[len("http"):]
+	}
+	s = strings.Replace(s, ":", "[:]", 1)
+	return strings.ReplaceAll(s, ".", "[.]")
+}
+
+// linkDestination matches the destination of an inline link or image, with an optional title.
+const linkDestination = `\(\s*(<[^<>\n]*>|[^\s()<>]*(?:\([^\s()]*\)[^\s()<>]*)*)(?:\s+(?:"[^"\n]*"|'[^'\n]*'|\([^()\n]*\)))?\s*\)`
+
+var (
+	markdownImagePattern = regexp.MustCompile(`!\[([^\[\]\n]*)\]` + linkDestination)
+	markdownLinkPattern  = regexp.MustCompile(`\[((?:[^\[\]\n]|\[[^\[\]\n]*\])*)\]` + linkDestination)
+	// referenceDefinitionPattern matches link reference definitions, [label]: url "title", which give the URL
+	// of reference-style links and images such as [text][label] and ![alt][label].
+	referenceDefinitionPattern = regexp.MustCompile(`^( {0,3})\[([^\]\n]+)\]:\s*(<[^<>\n]*>|\S+)(.*)$`)
+)
+
+// htmlTagPattern matches an opening HTML tag with attributes.
+var htmlTagPattern = regexp.MustCompile(`<[A-Za-z][A-Za-z0-9-]*\s[^<>]*>`)
+
+// htmlURLAttributePattern matches the HTML attributes that make a client follow or load a URL.
+var htmlURLAttributePattern = regexp.MustCompile(`(?i)\s(?:href|src|srcset|poster|background)\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+)`)
+
+// Filter removes or defangs markdown links and images whose URL is not allowed by the URL policy, including
+// reference-style ones, and removes disallowed URLs from the href, src, srcset, poster and background attributes
+// of HTML tags, which leaves the text of a link and makes an image inert. Fenced code blocks are left alone, as
+// their content is not rendered as links.
+func (f *URLFilter) Filter(input string) string {
+	p := f.policy
+	if input == "" || len(p.AllowedHosts) == 0 {
+		return input
+	}
+
+	lines := strings.Split(input, "\n")
+	insideFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			insideFence = !insideFence
+			continue
+		}
+		if insideFence {
+			continue
+		}
+		lines[i] = p.filterHTMLLine(p.filterMarkdownLine(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p URLPolicy) filterHTMLLine(line string) string {
+	return htmlTagPattern.ReplaceAllStringFunc(line, func(tag string) string {
+		return htmlURLAttributePattern.ReplaceAllStringFunc(tag, func(attribute string) string {
+			value := htmlURLAttributePattern.FindStringSubmatch(attribute)[1]
+			value = strings.Trim(value, `"'`)
+			if strings.HasPrefix(strings.ToLower(strings.TrimSpace(attribute)), "srcset") {
+				// A srcset lists candidate URLs, each followed by an optional size
+				for _, candidate := range strings.Split(value, ",") {
+					if fields := strings.Fields(candidate); len(fields) > 0 && !p.allowed(fields[0]) {
+						return ""
+					}
+				}
+				return attribute
+			}
+			if p.allowed(value) {
+				return attribute
+			}
+			return ""
+		})
+	})
+}
+
+func (p URLPolicy) filterMarkdownLine(line string) string {
+	if match := referenceDefinitionPattern.FindStringSubmatch(line); match != nil {
+		destination := strings.Trim(match[3], "<>")
+		if p.allowed(destination) {
+			return line
+		}
+		if p.Mode == URLModeRewrite {
+			return fmt.Sprintf("%s(blocked link %s: %s)", match[1], match[2], inertURL(destination))
+		}
+		return ""
+	}
+
+	// Images go first, so that an image nested in a link is checked on its own
+	line = markdownImagePattern.ReplaceAllStringFunc(line, func(image string) string {
+		match := markdownImagePattern.FindStringSubmatch(image)
+		alt, destination := match[1], strings.Trim(match[2], "<>")
+		if p.allowed(destination) {
+			return image
+		}
+		if p.Mode == URLModeRewrite {
+			return fmt.Sprintf("(blocked image %q: %s)", alt, inertURL(destination))
+		}
+		return alt
+	})
+
+	return markdownLinkPattern.ReplaceAllStringFunc(line, func(link string) string {
+		match := markdownLinkPattern.FindStringSubmatch(link)
+		text, destination := match[1], strings.Trim(match[2], "<>")
+		if p.allowed(destination) {
+			return link
+		}
+		if p.Mode == URLModeRewrite {
+			return fmt.Sprintf("%s (blocked link: %s)", text, inertURL(destination))
+		}
+		return text
+	})
+}
@@ -0,0 +1,193 @@
+package sanitize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestURLFilter(t *testing.T, p URLPolicy) *URLFilter {
+	t.Helper()
+	f, err := NewURLFilter(p)
+	require.NoError(t, err)
+	return f
+}
+
+func TestURLPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      URLPolicy
+		expectedErr string
+	}{
+		{name: "zero value", policy: URLPolicy{}},
+		{name: "github hosts", policy: URLPolicy{AllowedHosts: GitHubHosts, Mode: URLModeRewrite}},
+		{name: "invalid mode", policy: URLPolicy{Mode: "drop"}, expectedErr: `invalid URL mode "drop"`},
+		{name: "scheme in host", policy: URLPolicy{AllowedHosts: []string{"https://github.com"}}, expectedErr: "invalid allowed host"},
+		{name: "wildcard inside host", policy: URLPolicy{AllowedHosts: []string{"git*.com"}}, expectedErr: "invalid allowed host"},
+		{name: "bare wildcard", policy: URLPolicy{AllowedHosts: []string{"*."}}, expectedErr: "invalid allowed host"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHostAllowed(t *testing.T) {
+	hosts := []string{"github.com", "*.githubusercontent.com"}
+	assert.True(t, hostAllowed(hosts, "github.com"))
+	assert.True(t, hostAllowed(hosts, "GitHub.com."))
+	assert.True(t, hostAllowed(hosts, "raw.githubusercontent.com"))
+	assert.True(t, hostAllowed(hosts, "a.b.githubusercontent.com"))
+	assert.False(t, hostAllowed(hosts, "githubusercontent.com"))
+	assert.False(t, hostAllowed(hosts, "api.github.com"))
+	assert.False(t, hostAllowed(hosts, "github.com.evil.example"))
+	assert.False(t, hostAllowed(hosts, "evilgithub.com"))
+}
+
+func TestURLFilterMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "allowed image and link",
+			input:    "![logo](https://github.com/logo.png) see [docs](https://github.com/org/repo#readme)",
+			expected: "![logo](https://github.com/logo.png) see [docs](https://github.com/org/repo#readme)",
+		},
+		{
+			name:     "relative and mailto links",
+			input:    "[readme](docs/README.md) [top](#top) [mail](mailto:a@example.com)",
+			expected: "[readme](docs/README.md) [top](#top) [mail](mailto:a@example.com)",
+		},
+		{
+			name:     "disallowed image keeps alt text",
+			input:    "Status: ![build](https://evil.example/px.gif?d=secret \"title\") done",
+			expected: "Status: build done",
+		},
+		{
+			name:     "disallowed link keeps text",
+			input:    "Click [here](<https://evil.example/a b>) now",
+			expected: "Click here now",
+		},
+		{
+			name:     "protocol relative and data URLs",
+			input:    "![a](//evil.example/x.png) ![b](data:image/png;base64,AAAA) [c](javascript:alert(1))",
+			expected: "a b c",
+		},
+		{
+			name:     "backslashes and whitespace pointing to another host",
+			input:    "![a](\\\\evil.example/x.png) ![b](/\\evil.example/x.png) [c](<https:\\\\evil.example>) [d](<\t//evil.example>)",
+			expected: "a b c d",
+		},
+		{
+			name:     "disallowed image nested in allowed link",
+			input:    "[![badge](https://evil.example/b.svg)](https://github.com/org/repo)",
+			expected: "[badge](https://github.com/org/repo)",
+		},
+		{
+			name:     "reference definitions",
+			input:    "![alt][img] and [text][ok]\n\n[img]: https://evil.example/x.png \"t\"\n[ok]: https://github.com",
+			expected: "![alt][img] and [text][ok]\n\n\n[ok]: https://github.com",
+		},
+		{
+			name:     "code blocks are left alone",
+			input:    "```md\n![a](https://evil.example/x.png)\n```\n![a](https://evil.example/x.png)",
+			expected: "```md\n![a](https://evil.example/x.png)\n```\na",
+		},
+		{
+			name:     "rewrite image",
+			mode:     URLModeRewrite,
+			input:    "![pixel](https://evil.example/px.gif?d=1)",
+			expected: `(blocked image "pixel": hxxps[:]//evil[.]example/px[.]gif?d=1)`,
+		},
+		{
+			name:     "rewrite link",
+			mode:     URLModeRewrite,
+			input:    "see [this](http://evil.example/a)",
+			expected: "see this (blocked link: hxxp[:]//evil[.]example/a)",
+		},
+		{
+			name:     "rewrite reference definition",
+			mode:     URLModeRewrite,
+			input:    "  [img]: https://evil.example/x.png",
+			expected: "  (blocked link img: hxxps[:]//evil[.]example/x[.]png)",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newTestURLFilter(t, URLPolicy{AllowedHosts: GitHubHosts, Mode: tc.mode})
+			assert.Equal(t, tc.expected, f.Filter(tc.input))
+		})
+	}
+}
+
+func TestURLFilterWithoutAllowedHosts(t *testing.T) {
+	input := `![pixel](https://evil.example/px.gif) <img src="https://evil.example/px.gif">`
+	assert.Equal(t, input, newTestURLFilter(t, URLPolicy{}).Filter(input))
+}
+
+func TestNewURLFilter(t *testing.T) {
+	_, err := NewURLFilter(URLPolicy{Mode: "drop"})
+	require.ErrorContains(t, err, `invalid URL mode "drop"`)
+
+	// Allowed hosts are matched case-insensitively
+	f := newTestURLFilter(t, URLPolicy{AllowedHosts: []string{"GitHub.com"}})
+	assert.Equal(t, "[a](https://github.com/x)", f.Filter("[a](https://github.com/x)"))
+}
+
+func TestURLFilterHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "disallowed link keeps its text",
+			input:    `<a href="https://evil.example/x">evil</a> <a href="https://github.com/x">ok</a>`,
+			expected: `<a>evil</a> <a href="https://github.com/x">ok</a>`,
+		},
+		{
+			name:     "disallowed image becomes inert",
+			input:    `<img alt="p" src='https://evil.example/p.png'> <img src=https://github.com/p.png>`,
+			expected: `<img alt="p"> <img src=https://github.com/p.png>`,
+		},
+		{
+			name:     "srcset with a disallowed candidate",
+			input:    `<img srcset="https://github.com/a.png 1x, https://evil.example/b.png 2x">`,
+			expected: `<img>`,
+		},
+		{
+			name:     "backslashed host",
+			input:    `<a href="\\evil.example/x">evil</a> <a href="/\evil.example/x">evil</a>`,
+			expected: `<a>evil</a> <a>evil</a>`,
+		},
+		{
+			name:     "other attributes and plain text are kept",
+			input:    `a < b && <video poster="https://evil.example/p.png" controls> List<String>`,
+			expected: `a < b && <video controls> List<String>`,
+		},
+		{
+			name:     "code blocks are left alone",
+			input:    "```html\n<img src=\"https://evil.example/p.png\">\n```",
+			expected: "```html\n<img src=\"https://evil.example/p.png\">\n```",
+		},
+	}
+
+	f := newTestURLFilter(t, URLPolicy{AllowedHosts: GitHubHosts})
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, f.Filter(tc.input))
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package scopes inspects the OAuth scopes granted to a GitHub token
+package scopes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	gogithub "github.com/google/go-github/v79/github"
+)
+
+const oauthScopesHeader = "X-OAuth-Scopes"
+
+// impliedScopes lists the scopes that are granted implicitly by a broader scope.
+// See https://docs.github.com/en/apps/oauth-apps/building-oauth-apps/scopes-for-oauth-apps#available-scopes
+var impliedScopes = map[string][]string{
+	"repo":             {"repo:status", "repo_deployment", "public_repo", "repo:invite", "security_events"},
+	"admin:org":        {"write:org", "read:org"},
+	"write:org":        {"read:org"},
+	"admin:public_key": {"write:public_key", "read:public_key"},
+	"write:public_key": {"read:public_key"},
+	"admin:repo_hook":  {"write:repo_hook", "read:repo_hook"},
+	"write:repo_hook":  {"read:repo_hook"},
+	"admin:gpg_key":    {"write:gpg_key", "read:gpg_key"},
+	"write:gpg_key":    {"read:gpg_key"},
+	"user":             {"read:user", "user:email", "user:follow"},
+	"project":          {"read:project"},
+	"write:packages":   {"read:packages"},
+	"admin:enterprise": {"manage_runners:enterprise", "manage_billing:enterprise", "read:enterprise"},
+}
+
+// TokenScopes describes the scopes granted to a token.
+type TokenScopes struct {
+	// Known is false when the token does not report OAuth scopes, which is the case for
+	// fine-grained personal access tokens and GitHub App tokens. Their permissions cannot be listed.
+	Known bool
+	// Granted are the scopes reported for the token, without implied scopes.
+	Granted []string
+	// effective are the granted scopes plus every scope they imply.
+	effective map[string]bool
+}
+
+// NewTokenScopes creates a TokenScopes from a list of granted scopes.
+func NewTokenScopes(granted []string) TokenScopes {
+	ts := TokenScopes{Known: true, effective: make(map[string]bool)}
+	for _, scope := range granted {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		ts.Granted = append(ts.Granted, scope)
+		ts.addEffective(scope)
+	}
+	sort.Strings(ts.Granted)
+	return ts
+}
+
+func (ts TokenScopes) addEffective(scope string) {
+	if ts.effective[scope] {
+		return
+	}
+	ts.effective[scope] = true
+	for _, implied := range impliedScopes[scope] {
+		ts.addEffective(implied)
+	}
+}
+
+// Has reports whether the token has the scope, either directly or implied by a broader scope.
+// It always returns true when the scopes are unknown.
+func (ts TokenScopes) Has(scope string) bool {
+	if !ts.Known {
+		return true
+	}
+	return ts.effective[scope]
+}
+
+// HasAny reports whether the token has at least one of the scopes. An empty list is always satisfied.
+func (ts TokenScopes) HasAny(scopes []string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, scope := range scopes {
+		if ts.Has(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fetch determines the scopes of the token used by the client with a rate limit request,
+// which is cheap and does not count against the rate limit.
+func Fetch(ctx context.Context, client *gogithub.Client) (TokenScopes, error) {
+	_, resp, err := client.RateLimit.Get(ctx)
+	if err != nil {
+		return TokenScopes{}, fmt.Errorf("failed to fetch token scopes: %w", err)
+	}
+	return FromHeader(resp.Header), nil
+}
+
+// FromHeader reads the token scopes from the X-OAuth-Scopes response header.
+func FromHeader(header http.Header) TokenScopes {
+	values, ok := header[http.CanonicalHeaderKey(oauthScopesHeader)]
+	if !ok {
+		return TokenScopes{}
+	}
+	return NewTokenScopes(strings.Split(strings.Join(values, ","), ","))
+}
+
+// TokenKind describes the kind of token based on its prefix.
+// See https://github.blog/engineering/platform-security/behind-githubs-new-authentication-token-formats/
+func TokenKind(token string) string {
+	switch {
+	case strings.HasPrefix(token, "github_pat_"):
+		return "fine-grained personal access token"
+	case strings.HasPrefix(token, "ghp_"):
+		return "personal access token (classic)"
+	case strings.HasPrefix(token, "gho_"):
+		return "OAuth app token"
+	case strings.HasPrefix(token, "ghu_"):
+		return "GitHub App user token"
+	case strings.HasPrefix(token, "ghs_"):
+		return "GitHub App installation token"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,90 @@
+package scopes
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v79/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenScopes_Has(t *testing.T) {
+	ts := NewTokenScopes([]string{"repo", " admin:org", ""})
+
+	assert.Equal(t, []string{"admin:org", "repo"}, ts.Granted)
+	assert.True(t, ts.Has("repo"))
+	assert.True(t, ts.Has("public_repo"), "repo implies public_repo")
+	assert.True(t, ts.Has("security_events"), "repo implies security_events")
+	assert.True(t, ts.Has("read:org"), "admin:org implies read:org transitively")
+	assert.False(t, ts.Has("notifications"))
+	assert.False(t, ts.Has("project"))
+
+	assert.True(t, ts.HasAny(nil))
+	assert.True(t, ts.HasAny([]string{"notifications", "repo"}))
+	assert.False(t, ts.HasAny([]string{"gist", "project"}))
+
+	unknown := TokenScopes{}
+	assert.True(t, unknown.Has("project"), "unknown scopes never report a missing scope")
+}
+
+func TestFromHeader(t *testing.T) {
+	tests := []struct {
+		name            string
+		header          http.Header
+		expectedKnown   bool
+		expectedGranted []string
+	}{
+		{
+			name:            "classic token",
+			header:          http.Header{"X-Oauth-Scopes": []string{"repo, gist, read:org"}},
+			expectedKnown:   true,
+			expectedGranted: []string{"gist", "read:org", "repo"},
+		},
+		{
+			name:          "classic token without scopes",
+			header:        http.Header{"X-Oauth-Scopes": []string{""}},
+			expectedKnown: true,
+		},
+		{
+			name:          "fine-grained token",
+			header:        http.Header{},
+			expectedKnown: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := FromHeader(tc.header)
+			assert.Equal(t, tc.expectedKnown, ts.Known)
+			assert.Equal(t, tc.expectedGranted, ts.Granted)
+		})
+	}
+}
+
+func TestFetch(t *testing.T) {
+	client := github.NewClient(mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetRateLimit,
+			http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("X-OAuth-Scopes", "notifications, project")
+				_, _ = w.Write([]byte(`{"resources":{}}`))
+			}),
+		),
+	))
+
+	ts, err := Fetch(context.Background(), client)
+	require.NoError(t, err)
+	assert.True(t, ts.Known)
+	assert.True(t, ts.Has("read:project"))
+	assert.False(t, ts.Has("repo"))
+}
+
+func TestTokenKind(t *testing.T) {
+	assert.Equal(t, "fine-grained personal access token", TokenKind("github_pat_abc"))
+	assert.Equal(t, "personal access token (classic)", TokenKind("ghp_abc"))
+	assert.Equal(t, "GitHub App installation token", TokenKind("ghs_abc"))
+	assert.Equal(t, "unknown", TokenKind("abc"))
+}
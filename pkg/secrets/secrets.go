@@ -0,0 +1,348 @@
+// Package secrets detects credentials in content that tools are about to publish to GitHub
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Action is what happens to a tool call whose arguments contain secrets.
+type Action string
+
+const (
+	// ActionOff disables scanning.
+	ActionOff Action = "off"
+	// ActionWarn runs the tool and adds a warning naming the secrets to its result.
+	ActionWarn Action = "warn"
+	// ActionBlock refuses to run the tool if a pattern rule matches. Strings that are only reported by the
+	// entropy check are warned about.
+	ActionBlock Action = "block"
+)
+
+// Visibility is how a scanner treats targets whose visibility cannot be looked up.
+type Visibility string
+
+const (
+	// VisibilityPublic treats targets of unknown visibility as public.
+	VisibilityPublic Visibility = "public"
+	// VisibilityPrivate treats targets of unknown visibility as private.
+	VisibilityPrivate Visibility = "private"
+)
+
+const (
+	highEntropyRule = "high-entropy-string"
+
+	defaultEntropyThreshold = 4.2
+	// minEntropyLength is the shortest string checked for entropy. Shorter strings cannot reach the threshold
+	// reliably, and are rarely credentials.
+	minEntropyLength = 32
+)
+
+// Rule is a regular expression matching a kind of secret. If the pattern has a capture group, the first group
+// is the secret, and the rest of the match is context.
+type Rule struct {
+	Name    string `mapstructure:"name" json:"name"`
+	Pattern string `mapstructure:"pattern" json:"pattern"`
+}
+
+// Config configures a Scanner. The zero value warns about tool calls containing secrets matched by the built-in
+// rules.
+type Config struct {
+	// Action is one of off, warn (the default) or block.
+	Action Action `mapstructure:"action" json:"action,omitempty"`
+	// PublicOnly only blocks writes to public repositories and public gists. Secrets written elsewhere are
+	// warned about instead.
+	PublicOnly bool `mapstructure:"public_only" json:"public_only,omitempty"`
+	// UnknownVisibility is public (the default) or private, and decides whether PublicOnly blocks writes to
+	// targets whose visibility cannot be looked up.
+	UnknownVisibility Visibility `mapstructure:"unknown_visibility" json:"unknown_visibility,omitempty"`
+	// DisabledRules names built-in rules that are not run.
+	DisabledRules []string `mapstructure:"disabled_rules" json:"disabled_rules,omitempty"`
+	// Rules are additional rules.
+	Rules []Rule `mapstructure:"rules" json:"rules,omitempty"`
+	// EntropyThreshold is the Shannon entropy, in bits per character, at which a long string of letters and
+	// digits is reported as a possible secret. Defaults to 4.2, which excludes hex strings such as commit SHAs.
+	EntropyThreshold float64 `mapstructure:"entropy_threshold" json:"entropy_threshold,omitempty"`
+	// Allowlist are regular expressions matching values that are never reported, such as example keys.
+	Allowlist []string `mapstructure:"allowlist" json:"allowlist,omitempty"`
+}
+
+// Finding is a secret found in scanned text.
+type Finding struct {
+	Rule string `json:"rule"`
+	// Line is the 1-based line of the text the secret is on.
+	Line int `json:"line"`
+	// Redacted shows the start of the secret and its length, so that it can be located without being repeated.
+	Redacted string `json:"redacted"`
+}
+
+// builtinRules match credentials whose format is documented by their issuer.
+var builtinRules = []Rule{
+	{
+		Name:    "github-token",
+		Pattern: `\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`,
+	},
+	{
+		Name:    "aws-access-key-id",
+		Pattern: `\b((?:AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16})\b`,
+	},
+	{
+		Name:    "aws-secret-access-key",
+		Pattern: `(?i)\baws_?secret_?(?:access_?)?key\b["']?\s*[:=]\s*["']?([A-Za-z0-9/+]{40})\b`,
+	},
+	{
+		Name:    "private-key",
+		Pattern: `(-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----)`,
+	},
+}
+
+var compiledBuiltinRules = func() map[string]*regexp.Regexp {
+	compiled := make(map[string]*regexp.Regexp, len(builtinRules))
+	for _, rule := range builtinRules {
+		compiled[rule.Name] = regexp.MustCompile(rule.Pattern)
+	}
+	return compiled
+}()
+
+// entropyCandidatePattern matches strings that may be random credentials: runs of letters, digits and the
+// punctuation of base64 and URL-safe base64.
+var entropyCandidatePattern = regexp.MustCompile(`[A-Za-z0-9+/_=-]{32,}`)
+
+// checksumPattern matches checksums, which are random but public: go.sum hashes such as h1:…=, subresource
+// integrity hashes of lockfiles such as sha512-…, and digests such as sha256:….
+var checksumPattern = regexp.MustCompile(`(?:\bh1:|\b(?:sha1|sha256|sha384|sha512)[-:])[A-Za-z0-9+/=_-]+`)
+
+// lockfiles are the names of files that record dependency checksums, whose content is not checked for entropy.
+var lockfiles = []string{
+	"go.sum",
+	"go.work.sum",
+	"package-lock.json",
+	"npm-shrinkwrap.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"bun.lock",
+	"Cargo.lock",
+	"Gemfile.lock",
+	"composer.lock",
+	"poetry.lock",
+	"Pipfile.lock",
+	"uv.lock",
+	"pdm.lock",
+	"mix.lock",
+	"pubspec.lock",
+	"Podfile.lock",
+	"Package.resolved",
+	"packages.lock.json",
+	"gradle.lockfile",
+	"flake.lock",
+	".terraform.lock.hcl",
+}
+
+// IsLockfile reports whether the file at p records dependency checksums.
+func IsLockfile(p string) bool {
+	return slices.Contains(lockfiles, path.Base(p))
+}
+
+type compiledRule struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// Scanner finds secrets in text with pattern rules and an entropy check.
+type Scanner struct {
+	rules             []compiledRule
+	entropyThreshold  float64
+	allowlist         []*regexp.Regexp
+	action            Action
+	publicOnly        bool
+	unknownVisibility Visibility
+}
+
+// NewScanner creates a scanner running the built-in rules, except those disabled in cfg, followed by the rules
+// of cfg.
+func NewScanner(cfg Config) (*Scanner, error) {
+	s := &Scanner{
+		action:            cfg.Action,
+		publicOnly:        cfg.PublicOnly,
+		unknownVisibility: cfg.UnknownVisibility,
+		entropyThreshold:  cfg.EntropyThreshold,
+	}
+	switch s.action {
+	case "":
+		s.action = ActionWarn
+	case ActionOff, ActionWarn, ActionBlock:
+	default:
+		return nil, fmt.Errorf("invalid action %q: must be off, warn or block", cfg.Action)
+	}
+	switch s.unknownVisibility {
+	case "":
+		s.unknownVisibility = VisibilityPublic
+	case VisibilityPublic, VisibilityPrivate:
+	default:
+		return nil, fmt.Errorf("invalid unknown visibility %q: must be public or private", cfg.UnknownVisibility)
+	}
+	if s.entropyThreshold < 0 {
+		return nil, fmt.Errorf("invalid entropy threshold %v: must not be negative", cfg.EntropyThreshold)
+	}
+	if s.entropyThreshold == 0 {
+		s.entropyThreshold = defaultEntropyThreshold
+	}
+
+	for _, rule := range builtinRules {
+		if !slices.Contains(cfg.DisabledRules, rule.Name) {
+			s.rules = append(s.rules, compiledRule{name: rule.Name, re: compiledBuiltinRules[rule.Name]})
+		}
+	}
+	for _, rule := range cfg.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule with pattern %q has no name", rule.Pattern)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for rule %s: %w", rule.Name, err)
+		}
+		s.rules = append(s.rules, compiledRule{name: rule.Name, re: re})
+	}
+	if slices.Contains(cfg.DisabledRules, highEntropyRule) {
+		s.entropyThreshold = math.Inf(1)
+	}
+	for _, pattern := range cfg.Allowlist {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist pattern %q: %w", pattern, err)
+		}
+		s.allowlist = append(s.allowlist, re)
+	}
+	return s, nil
+}
+
+// Action returns the action of the scanner.
+func (s *Scanner) Action() Action {
+	return s.action
+}
+
+// PublicOnly reports whether the scanner only blocks writes to public targets.
+func (s *Scanner) PublicOnly() bool {
+	return s.publicOnly
+}
+
+// UnknownVisibility returns how targets whose visibility cannot be looked up are treated.
+func (s *Scanner) UnknownVisibility() Visibility {
+	return s.unknownVisibility
+}
+
+// Blocks reports whether finding blocks a tool call when the action is block. Only pattern rules block, as
+// strings reported by the entropy check alone are often hashes or IDs rather than credentials.
+func (s *Scanner) Blocks(finding Finding) bool {
+	return finding.Rule != highEntropyRule
+}
+
+// Scan returns the secrets found in text, in order of their line. A string matched by a rule is not reported
+// again by the entropy check.
+func (s *Scanner) Scan(text string) []Finding {
+	return s.scan(text, true)
+}
+
+// ScanFile returns the secrets found in the content of the file at path. Lockfiles are full of checksums, so
+// only the pattern rules are run on them.
+func (s *Scanner) ScanFile(path, content string) []Finding {
+	return s.scan(content, !IsLockfile(path))
+}
+
+// ScanPatterns returns the secrets found in text by the pattern rules, without the entropy check.
+func (s *Scanner) ScanPatterns(text string) []Finding {
+	return s.scan(text, false)
+}
+
+func (s *Scanner) scan(text string, entropy bool) []Finding {
+	if s.action == ActionOff || text == "" {
+		return nil
+	}
+
+	var findings []Finding
+	for i, line := range strings.Split(text, "\n") {
+		var matched []string
+		for _, rule := range s.rules {
+			for _, match := range rule.re.FindAllStringSubmatch(line, -1) {
+				secret := match[0]
+				if len(match) > 1 && match[1] != "" {
+					secret = match[1]
+				}
+				matched = append(matched, secret)
+				if s.allowed(secret) {
+					continue
+				}
+				findings = append(findings, Finding{Rule: rule.name, Line: i + 1, Redacted: Redact(secret)})
+			}
+		}
+
+		if !entropy {
+			continue
+		}
+		checksums := checksumPattern.FindAllString(line, -1)
+		for _, candidate := range entropyCandidatePattern.FindAllString(line, -1) {
+			if s.allowed(candidate) || slices.ContainsFunc(matched, func(secret string) bool {
+				return strings.Contains(candidate, secret) || strings.Contains(secret, candidate)
+			}) || slices.ContainsFunc(checksums, func(checksum string) bool {
+				return strings.Contains(checksum, candidate)
+			}) {
+				continue
+			}
+			if looksRandom(candidate) && shannonEntropy(candidate) >= s.entropyThreshold {
+				findings = append(findings, Finding{Rule: highEntropyRule, Line: i + 1, Redacted: Redact(candidate)})
+			}
+		}
+	}
+	return findings
+}
+
+func (s *Scanner) allowed(secret string) bool {
+	return slices.ContainsFunc(s.allowlist, func(re *regexp.Regexp) bool {
+		return re.MatchString(secret)
+	})
+}
+
+// Redact returns the first characters of a secret and its length.
+func Redact(secret string) string {
+	const shown = 4
+	if len(secret) <= shown*2 {
+		return fmt.Sprintf("%d characters", len(secret))
+	}
+	return fmt.Sprintf("%s… (%d characters)", secret[:shown], len(secret))
+}
+
+// looksRandom reports whether s mixes upper and lower case letters and digits, as generated credentials do.
+// Paths, identifiers and words rarely do all three.
+func looksRandom(s string) bool {
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// shannonEntropy returns the entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len(s))
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
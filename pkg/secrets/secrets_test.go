@@ -0,0 +1,165 @@
+package secrets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Credentials are assembled at run time, so that this file does not trip secret scanning itself.
+var (
+	githubToken     = "ghp_" + strings.Repeat("aB3d", 9)
+	fineGrainedPAT  = "github_pat_" + strings.Repeat("11AbC", 16)
+	awsAccessKeyID  = "AKIA" + "IOSFODNN7EXAMPLE"
+	awsSecretKey    = "wJalrXUtnFEMI/K7MDENG/" + "bPxRfiCYEXAMPLEKEY"
+	privateKeyBegin = "-----BEGIN " + "RSA PRIVATE KEY-----"
+	randomString    = "q8ZtV2xKp4LmN7rWc1YbH6sJd9FgA3eU"
+)
+
+func TestNewScanner(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         Config
+		expectedErr string
+	}{
+		{name: "zero value", cfg: Config{}},
+		{name: "invalid action", cfg: Config{Action: "deny"}, expectedErr: `invalid action "deny"`},
+		{name: "invalid unknown visibility", cfg: Config{UnknownVisibility: "secret"}, expectedErr: `invalid unknown visibility "secret"`},
+		{name: "negative entropy threshold", cfg: Config{EntropyThreshold: -1}, expectedErr: "invalid entropy threshold"},
+		{name: "rule without name", cfg: Config{Rules: []Rule{{Pattern: "x"}}}, expectedErr: "has no name"},
+		{name: "invalid rule pattern", cfg: Config{Rules: []Rule{{Name: "bad", Pattern: "("}}}, expectedErr: "invalid pattern for rule bad"},
+		{name: "invalid allowlist pattern", cfg: Config{Allowlist: []string{"("}}, expectedErr: "invalid allowlist pattern"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewScanner(tc.cfg)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, ActionWarn, s.Action())
+			assert.Equal(t, VisibilityPublic, s.UnknownVisibility())
+		})
+	}
+}
+
+func TestScannerScan(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		text     string
+		expected []Finding
+	}{
+		{
+			name: "no secrets",
+			text: "func main() {\n\tfmt.Println(\"hello\")\n}\n// commit 3f786850e387550fdab836ed7e6dc881de23001b",
+		},
+		{
+			name:     "github token",
+			text:     "token: " + githubToken,
+			expected: []Finding{{Rule: "github-token", Line: 1, Redacted: "ghp_… (40 characters)"}},
+		},
+		{
+			name:     "fine-grained personal access token",
+			text:     "export GH_TOKEN=" + fineGrainedPAT,
+			expected: []Finding{{Rule: "github-token", Line: 1, Redacted: "gith… (91 characters)"}},
+		},
+		{
+			name: "aws keys on separate lines",
+			text: "[default]\naws_access_key_id = " + awsAccessKeyID + "\naws_secret_access_key = " + awsSecretKey,
+			expected: []Finding{
+				{Rule: "aws-access-key-id", Line: 2, Redacted: "AKIA… (20 characters)"},
+				{Rule: "aws-secret-access-key", Line: 3, Redacted: "wJal… (40 characters)"},
+			},
+		},
+		{
+			name:     "private key",
+			text:     privateKeyBegin + "\nMIIEow...\n-----END RSA PRIVATE KEY-----",
+			expected: []Finding{{Rule: "private-key", Line: 1, Redacted: "----… (31 characters)"}},
+		},
+		{
+			name:     "high entropy string",
+			text:     "const apiKey = \"" + randomString + "\"",
+			expected: []Finding{{Rule: "high-entropy-string", Line: 1, Redacted: "q8Zt… (32 characters)"}},
+		},
+		{
+			name: "high entropy rule disabled",
+			cfg:  Config{DisabledRules: []string{"high-entropy-string"}},
+			text: randomString,
+		},
+		{
+			name: "built-in rule disabled",
+			cfg:  Config{DisabledRules: []string{"github-token"}},
+			text: githubToken,
+		},
+		{
+			name:     "custom rule with capture group",
+			cfg:      Config{Rules: []Rule{{Name: "slack-webhook", Pattern: `hooks\.slack\.com/services/(T[A-Z0-9]+/B[A-Z0-9]+/[A-Za-z0-9]+)`}}},
+			text:     "url: https://hooks.slack.com/services/T0000/B0000/XXXXXXXX",
+			expected: []Finding{{Rule: "slack-webhook", Line: 1, Redacted: "T000… (20 characters)"}},
+		},
+		{
+			name: "go.sum checksums",
+			text: "github.com/google/go-github/v79 v79.0.0 h1:" + randomString + "0ZL3xGW8Tx4=\n",
+		},
+		{
+			name: "lockfile integrity hashes and digests",
+			text: `"integrity": "sha512-` + randomString + randomString + `=="` + "\nimage: alpine@sha256:" + randomString,
+		},
+		{
+			name: "allowlisted value",
+			cfg:  Config{Allowlist: []string{`EXAMPLE$`}},
+			text: awsAccessKeyID,
+		},
+		{
+			name: "scanning off",
+			cfg:  Config{Action: ActionOff},
+			text: githubToken,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewScanner(tc.cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, s.Scan(tc.text))
+		})
+	}
+}
+
+func TestScannerScanFile(t *testing.T) {
+	s, err := NewScanner(Config{})
+	require.NoError(t, err)
+
+	// Lockfiles are only scanned by the pattern rules
+	assert.Empty(t, s.ScanFile("web/yarn.lock", "resolved "+randomString))
+	assert.Len(t, s.ScanFile("web/yarn.lock", "token "+githubToken), 1)
+	assert.Len(t, s.ScanFile("web/config.js", "resolved "+randomString), 1)
+	assert.Empty(t, s.ScanPatterns(randomString))
+}
+
+func TestScannerBlocks(t *testing.T) {
+	s, err := NewScanner(Config{Action: ActionBlock})
+	require.NoError(t, err)
+
+	findings := s.Scan(githubToken + "\n" + randomString)
+	require.Len(t, findings, 2)
+	assert.True(t, s.Blocks(findings[0]))
+	assert.False(t, s.Blocks(findings[1]))
+}
+
+func TestIsLockfile(t *testing.T) {
+	assert.True(t, IsLockfile("go.sum"))
+	assert.True(t, IsLockfile("web/package-lock.json"))
+	assert.False(t, IsLockfile("go.mod"))
+	assert.False(t, IsLockfile("docs/go.sum.md"))
+}
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, "8 characters", Redact("abcdefgh"))
+	assert.Equal(t, "abcd… (9 characters)", Redact("abcdefghi"))
+}
@@ -1,7 +1,9 @@
 package toolsets
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -29,6 +31,18 @@ func NewToolsetDoesNotExistError(name string) *ToolsetDoesNotExistError {
 	return &ToolsetDoesNotExistError{Name: name}
 }
 
+type ToolDoesNotExistError struct {
+	Name string
+}
+
+func (e *ToolDoesNotExistError) Error() string {
+	return fmt.Sprintf("tool %s does not exist", e.Name)
+}
+
+func NewToolDoesNotExistError(name string) *ToolDoesNotExistError {
+	return &ToolDoesNotExistError{Name: name}
+}
+
 func NewServerTool(tool mcp.Tool, handler server.ToolHandlerFunc) server.ServerTool {
 	return server.ServerTool{Tool: tool, Handler: handler}
 }
@@ -51,25 +65,105 @@ func NewServerPrompt(prompt mcp.Prompt, handler server.PromptHandlerFunc) server
 type Toolset struct {
 	Name        string
 	Description string
-	Enabled     bool
-	readOnly    bool
-	writeTools  []server.ServerTool
-	readTools   []server.ServerTool
+	// Enabled may be set directly while the server is being configured. Once it serves requests, toolsets are
+	// enabled and disabled at runtime, so use IsEnabled and SetEnabled instead.
+	Enabled bool
+	// enabledMu guards Enabled, which dynamic toolset discovery changes while resource reads check it.
+	enabledMu  sync.RWMutex
+	readOnly   bool
+	writeTools []server.ServerTool
+	readTools  []server.ServerTool
 	// resources are not tools, but the community seems to be moving towards namespaces as a broader concept
 	// and in order to have multiple servers running concurrently, we want to avoid overlapping resources too.
-	resourceTemplates []server.ServerResourceTemplate
+	// Like tools, they are classified as read or write so that read-only mode can hide the latter.
+	resourceTemplates      []server.ServerResourceTemplate
+	writeResourceTemplates []server.ServerResourceTemplate
 	// prompts are also not tools but are namespaced similarly
-	prompts []server.ServerPrompt
+	prompts      []server.ServerPrompt
+	writePrompts []server.ServerPrompt
+	// enabledTools are individual tools that are active even when the toolset itself is not enabled
+	enabledTools map[string]bool
+	// disabledTools are individual tools that are never active, even when the toolset is enabled
+	disabledTools map[string]bool
+}
+
+// IsEnabled reports whether the toolset is enabled.
+func (t *Toolset) IsEnabled() bool {
+	t.enabledMu.RLock()
+	defer t.enabledMu.RUnlock()
+	return t.Enabled
+}
+
+// SetEnabled enables or disables the toolset, and reports whether this changed its state.
+func (t *Toolset) SetEnabled(enabled bool) bool {
+	t.enabledMu.Lock()
+	defer t.enabledMu.Unlock()
+	if t.Enabled == enabled {
+		return false
+	}
+	t.Enabled = enabled
+	return true
 }
 
 func (t *Toolset) GetActiveTools() []server.ServerTool {
-	if t.Enabled {
-		if t.readOnly {
-			return t.readTools
+	var active []server.ServerTool
+	for _, tool := range t.GetAvailableTools() {
+		if t.isToolActive(tool.Tool.Name) {
+			active = append(active, tool)
 		}
-		return append(t.readTools, t.writeTools...)
 	}
-	return nil
+	return active
+}
+
+func (t *Toolset) isToolActive(name string) bool {
+	if t.disabledTools[name] {
+		return false
+	}
+	return t.IsEnabled() || t.enabledTools[name]
+}
+
+// MapTools replaces every tool of the toolset, read and write, with the result of fn.
+// It is used to decorate tools, e.g. to adjust descriptions or wrap handlers.
+func (t *Toolset) MapTools(fn func(server.ServerTool) server.ServerTool) {
+	for i, tool := range t.readTools {
+		t.readTools[i] = fn(tool)
+	}
+	for i, tool := range t.writeTools {
+		t.writeTools[i] = fn(tool)
+	}
+}
+
+// HasTool reports whether the toolset contains the named tool, regardless of read-only mode.
+func (t *Toolset) HasTool(name string) bool {
+	for _, tool := range t.readTools {
+		if tool.Tool.Name == name {
+			return true
+		}
+	}
+	for _, tool := range t.writeTools {
+		if tool.Tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// EnableTool activates a single tool without enabling the rest of the toolset.
+func (t *Toolset) EnableTool(name string) {
+	if t.enabledTools == nil {
+		t.enabledTools = make(map[string]bool)
+	}
+	t.enabledTools[name] = true
+	delete(t.disabledTools, name)
+}
+
+// DisableTool deactivates a single tool even if the toolset is enabled.
+func (t *Toolset) DisableTool(name string) {
+	if t.disabledTools == nil {
+		t.disabledTools = make(map[string]bool)
+	}
+	t.disabledTools[name] = true
+	delete(t.enabledTools, name)
 }
 
 func (t *Toolset) GetAvailableTools() []server.ServerTool {
@@ -80,58 +174,104 @@ func (t *Toolset) GetAvailableTools() []server.ServerTool {
 }
 
 func (t *Toolset) RegisterTools(s *server.MCPServer) {
-	if !t.Enabled {
-		return
-	}
-	for _, tool := range t.readTools {
+	for _, tool := range t.GetActiveTools() {
 		s.AddTool(tool.Tool, tool.Handler)
 	}
-	if !t.readOnly {
-		for _, tool := range t.writeTools {
-			s.AddTool(tool.Tool, tool.Handler)
-		}
-	}
 }
 
+// AddResourceTemplates adds resource templates that only read data.
 func (t *Toolset) AddResourceTemplates(templates ...server.ServerResourceTemplate) *Toolset {
 	t.resourceTemplates = append(t.resourceTemplates, templates...)
 	return t
 }
 
+// AddWriteResourceTemplates adds resource templates that modify data, which are hidden in read-only mode.
+func (t *Toolset) AddWriteResourceTemplates(templates ...server.ServerResourceTemplate) *Toolset {
+	t.writeResourceTemplates = append(t.writeResourceTemplates, templates...)
+	return t
+}
+
+// AddPrompts adds prompts that only guide the model towards reading data.
 func (t *Toolset) AddPrompts(prompts ...server.ServerPrompt) *Toolset {
 	t.prompts = append(t.prompts, prompts...)
 	return t
 }
 
+// AddWritePrompts adds prompts that guide the model towards modifying data, which are hidden in read-only mode.
+func (t *Toolset) AddWritePrompts(prompts ...server.ServerPrompt) *Toolset {
+	t.writePrompts = append(t.writePrompts, prompts...)
+	return t
+}
+
 func (t *Toolset) GetActiveResourceTemplates() []server.ServerResourceTemplate {
-	if !t.Enabled {
+	if !t.IsEnabled() {
 		return nil
 	}
-	return t.resourceTemplates
+	return t.GetAvailableResourceTemplates()
 }
 
 func (t *Toolset) GetAvailableResourceTemplates() []server.ServerResourceTemplate {
-	return t.resourceTemplates
+	if t.readOnly {
+		return t.resourceTemplates
+	}
+	return append(append([]server.ServerResourceTemplate{}, t.resourceTemplates...), t.writeResourceTemplates...)
+}
+
+func (t *Toolset) GetActivePrompts() []server.ServerPrompt {
+	if !t.IsEnabled() {
+		return nil
+	}
+	return t.GetAvailablePrompts()
+}
+
+func (t *Toolset) GetAvailablePrompts() []server.ServerPrompt {
+	if t.readOnly {
+		return t.prompts
+	}
+	return append(append([]server.ServerPrompt{}, t.prompts...), t.writePrompts...)
 }
 
 func (t *Toolset) RegisterResourcesTemplates(s *server.MCPServer) {
-	if !t.Enabled {
-		return
+	for _, resource := range t.GetActiveResourceTemplates() {
+		s.AddResourceTemplate(resource.Template, t.guardResourceTemplateHandler(resource.Handler))
 	}
-	for _, resource := range t.resourceTemplates {
-		s.AddResourceTemplate(resource.Template, resource.Handler)
+}
+
+// guardResourceTemplateHandler rejects reads once the toolset has been disabled again.
+// The MCP server offers no way to remove a resource template, so this is how templates are unregistered.
+func (t *Toolset) guardResourceTemplateHandler(handler server.ResourceTemplateHandlerFunc) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		if !t.IsEnabled() {
+			return nil, fmt.Errorf("toolset %s is disabled", t.Name)
+		}
+		return handler(ctx, request)
 	}
 }
 
 func (t *Toolset) RegisterPrompts(s *server.MCPServer) {
-	if !t.Enabled {
-		return
-	}
-	for _, prompt := range t.prompts {
+	for _, prompt := range t.GetActivePrompts() {
 		s.AddPrompt(prompt.Prompt, prompt.Handler)
 	}
 }
 
+// UnregisterTools removes the toolset's tools from the server.
+func (t *Toolset) UnregisterTools(s *server.MCPServer) {
+	names := make([]string, 0, len(t.readTools)+len(t.writeTools))
+	for _, tool := range t.GetAvailableTools() {
+		names = append(names, tool.Tool.Name)
+	}
+	s.DeleteTools(names...)
+}
+
+// UnregisterPrompts removes the toolset's prompts from the server.
+func (t *Toolset) UnregisterPrompts(s *server.MCPServer) {
+	names := make([]string, 0, len(t.prompts)+len(t.writePrompts))
+	for _, prompt := range t.GetAvailablePrompts() {
+		names = append(names, prompt.Prompt.Name)
+	}
+	s.DeletePrompts(names...)
+}
+
 func (t *Toolset) SetReadOnly() {
 	// Set the toolset to read-only
 	t.readOnly = true
@@ -200,7 +340,7 @@ func (tg *ToolsetGroup) IsEnabled(name string) bool {
 	if !exists {
 		return false
 	}
-	return feature.Enabled
+	return feature.IsEnabled()
 }
 
 type EnableToolsetsOptions struct {
@@ -243,11 +383,57 @@ func (tg *ToolsetGroup) EnableToolset(name string) error {
 	if !exists {
 		return NewToolsetDoesNotExistError(name)
 	}
-	toolset.Enabled = true
+	toolset.SetEnabled(true)
 	tg.Toolsets[name] = toolset
 	return nil
 }
 
+// MapTools replaces every tool of every toolset with the result of fn.
+func (tg *ToolsetGroup) MapTools(fn func(server.ServerTool) server.ServerTool) {
+	for _, toolset := range tg.Toolsets {
+		toolset.MapTools(fn)
+	}
+}
+
+// EnableTools activates individual tools by name without enabling the rest of their toolsets.
+func (tg *ToolsetGroup) EnableTools(names []string) error {
+	for _, name := range names {
+		toolsets := tg.findToolsetsForTool(name)
+		if len(toolsets) == 0 {
+			return NewToolDoesNotExistError(name)
+		}
+		for _, toolset := range toolsets {
+			toolset.EnableTool(name)
+		}
+	}
+	return nil
+}
+
+// DisableTools deactivates individual tools by name, even when their toolsets are enabled.
+func (tg *ToolsetGroup) DisableTools(names []string) error {
+	for _, name := range names {
+		toolsets := tg.findToolsetsForTool(name)
+		if len(toolsets) == 0 {
+			return NewToolDoesNotExistError(name)
+		}
+		for _, toolset := range toolsets {
+			toolset.DisableTool(name)
+		}
+	}
+	return nil
+}
+
+// findToolsetsForTool returns every toolset containing the named tool, as some tools are shared between toolsets.
+func (tg *ToolsetGroup) findToolsetsForTool(name string) []*Toolset {
+	var result []*Toolset
+	for _, toolset := range tg.Toolsets {
+		if toolset.HasTool(name) {
+			result = append(result, toolset)
+		}
+	}
+	return result
+}
+
 func (tg *ToolsetGroup) RegisterAll(s *server.MCPServer) {
 	for _, toolset := range tg.Toolsets {
 		toolset.RegisterTools(s)
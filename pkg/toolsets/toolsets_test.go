@@ -1,10 +1,27 @@
 package toolsets
 
 import (
+	"context"
 	"errors"
+	"sync"
 	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
+func testTool(name string, readOnly bool) mcp.Tool {
+	return mcp.NewTool(name, mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: &readOnly}))
+}
+
+func toolNames(tools []server.ServerTool) []string {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		names = append(names, tool.Tool.Name)
+	}
+	return names
+}
+
 func TestNewToolsetGroupIsEmptyWithoutEverythingOn(t *testing.T) {
 	tsg := NewToolsetGroup(false)
 	if len(tsg.Toolsets) != 0 {
@@ -262,3 +279,116 @@ func TestToolsetGroup_GetToolset(t *testing.T) {
 		t.Errorf("expected error to be ToolsetDoesNotExistError, got %v", err)
 	}
 }
+
+func TestToolsetGroup_EnableAndDisableTools(t *testing.T) {
+	tsg := NewToolsetGroup(false)
+	repos := NewToolset("repos", "desc").
+		AddReadTools(NewServerTool(testTool("get_file_contents", true), nil)).
+		AddWriteTools(NewServerTool(testTool("delete_file", false), nil))
+	prs := NewToolset("pull_requests", "desc").
+		AddReadTools(NewServerTool(testTool("pull_request_read", true), nil)).
+		AddWriteTools(NewServerTool(testTool("merge_pull_request", false), nil))
+	tsg.AddToolset(repos)
+	tsg.AddToolset(prs)
+
+	if err := tsg.EnableToolset("pull_requests"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := tsg.EnableTools([]string{"get_file_contents"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := tsg.DisableTools([]string{"merge_pull_request"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got := toolNames(repos.GetActiveTools()); len(got) != 1 || got[0] != "get_file_contents" {
+		t.Errorf("expected only get_file_contents to be active in repos, got %v", got)
+	}
+	if got := toolNames(prs.GetActiveTools()); len(got) != 1 || got[0] != "pull_request_read" {
+		t.Errorf("expected only pull_request_read to be active in pull_requests, got %v", got)
+	}
+	if got := len(repos.GetAvailableTools()); got != 2 {
+		t.Errorf("expected individual tool toggles to leave available tools untouched, got %d", got)
+	}
+
+	err := tsg.EnableTools([]string{"does_not_exist"})
+	var notExist *ToolDoesNotExistError
+	if !errors.As(err, &notExist) {
+		t.Errorf("expected ToolDoesNotExistError, got %v", err)
+	}
+}
+
+func TestToolset_PromptsAndResourcesRespectReadOnly(t *testing.T) {
+	toolset := NewToolset("issues", "desc").
+		AddPrompts(NewServerPrompt(mcp.NewPrompt("SummarizeIssue"), nil)).
+		AddWritePrompts(NewServerPrompt(mcp.NewPrompt("IssueToFixWorkflow"), nil)).
+		AddResourceTemplates(NewServerResourceTemplate(mcp.NewResourceTemplate("repo://{owner}/{repo}/contents{/path*}", "Repository Content"), nil)).
+		AddWriteResourceTemplates(NewServerResourceTemplate(mcp.NewResourceTemplate("draft://{owner}/{repo}", "Draft"), nil))
+
+	if got := len(toolset.GetActivePrompts()); got != 0 {
+		t.Errorf("expected no active prompts while disabled, got %d", got)
+	}
+
+	toolset.Enabled = true
+	if got := len(toolset.GetActivePrompts()); got != 2 {
+		t.Errorf("expected 2 active prompts, got %d", got)
+	}
+	if got := len(toolset.GetActiveResourceTemplates()); got != 2 {
+		t.Errorf("expected 2 active resource templates, got %d", got)
+	}
+
+	toolset.SetReadOnly()
+	prompts := toolset.GetActivePrompts()
+	if len(prompts) != 1 || prompts[0].Prompt.Name != "SummarizeIssue" {
+		t.Errorf("expected only the read prompt in read-only mode, got %v", prompts)
+	}
+	templates := toolset.GetActiveResourceTemplates()
+	if len(templates) != 1 || templates[0].Template.Name != "Repository Content" {
+		t.Errorf("expected only the read resource template in read-only mode, got %v", templates)
+	}
+}
+
+func TestToolset_ResourceTemplateGuard(t *testing.T) {
+	toolset := NewToolset("repos", "desc")
+	toolset.Enabled = true
+	handler := toolset.guardResourceTemplateHandler(func(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{Text: "ok"}}, nil
+	})
+
+	if _, err := handler(context.Background(), mcp.ReadResourceRequest{}); err != nil {
+		t.Fatalf("expected no error while enabled, got %v", err)
+	}
+
+	toolset.SetEnabled(false)
+	if _, err := handler(context.Background(), mcp.ReadResourceRequest{}); err == nil {
+		t.Error("expected an error once the toolset is disabled")
+	}
+}
+
+func TestToolset_SetEnabled(t *testing.T) {
+	toolset := NewToolset("repos", "desc")
+	if !toolset.SetEnabled(true) || !toolset.IsEnabled() {
+		t.Fatal("expected enabling a disabled toolset to change its state")
+	}
+	if toolset.SetEnabled(true) {
+		t.Error("expected enabling an enabled toolset to report no change")
+	}
+
+	// Resource reads check the state while it is changed, which the race detector checks
+	handler := toolset.guardResourceTemplateHandler(func(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, nil
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(enabled bool) {
+			defer wg.Done()
+			toolset.SetEnabled(enabled)
+		}(i%2 == 0)
+		go func() {
+			defer wg.Done()
+			_, _ = handler(context.Background(), mcp.ReadResourceRequest{})
+		}()
+	}
+	wg.Wait()
+}